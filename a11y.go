@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// weatherCodePhrases maps WorldWeatherOnline condition codes to plain
+// descriptions for screen readers, naming the sky where the code is about
+// cloud cover.
+var weatherCodePhrases = map[int]string{
+	113: "clear skies",
+	116: "partly cloudy skies",
+	119: "cloudy skies",
+	122: "overcast skies",
+	143: "mist",
+	176: "patchy rain possible",
+	179: "patchy snow possible",
+	182: "patchy sleet possible",
+	185: "patchy freezing drizzle possible",
+	200: "thundery outbreaks possible",
+	227: "blowing snow",
+	230: "blizzard",
+	248: "fog",
+	260: "freezing fog",
+	263: "patchy light drizzle",
+	266: "light drizzle",
+	281: "freezing drizzle",
+	284: "heavy freezing drizzle",
+	293: "patchy light rain",
+	296: "light rain",
+	299: "moderate rain at times",
+	302: "moderate rain",
+	305: "heavy rain at times",
+	308: "heavy rain",
+	311: "light freezing rain",
+	314: "moderate or heavy freezing rain",
+	317: "light sleet",
+	320: "moderate or heavy sleet",
+	323: "patchy light snow",
+	326: "light snow",
+	329: "patchy moderate snow",
+	332: "moderate snow",
+	335: "patchy heavy snow",
+	338: "heavy snow",
+	350: "ice pellets",
+	353: "light rain showers",
+	356: "moderate or heavy rain showers",
+	359: "torrential rain showers",
+	362: "light sleet showers",
+	365: "moderate or heavy sleet showers",
+	368: "light snow showers",
+	371: "moderate or heavy snow showers",
+	374: "light showers of ice pellets",
+	377: "moderate or heavy showers of ice pellets",
+	386: "patchy light rain with thunder",
+	389: "moderate or heavy rain with thunder",
+	392: "patchy light snow with thunder",
+	395: "moderate or heavy snow with thunder",
+}
+
+// stripEmoji removes emoji and the joiners and selectors that combine
+// them, then tidies the spaces they leave behind.
+func stripEmoji(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.Is(unicode.So, r), unicode.Is(unicode.Sk, r) && r > unicode.MaxLatin1:
+			return -1
+		case r == '\u200d', unicode.Is(unicode.Variation_Selector, r):
+			return -1
+		}
+		return r
+	}, s)
+	return collapseSpaces(s)
+}
+
+// conditionPhrase describes a weather code in words, falling back to
+// wttr.in's description without any emoji for codes not in the table.
+func conditionPhrase(code number, desc string) string {
+	if phrase, ok := weatherCodePhrases[int(code)]; ok {
+		return phrase
+	}
+	return strings.ToLower(stripEmoji(desc))
+}
+
+// windPhrase describes the wind strength in words, roughly following the
+// Beaufort scale, with the direction spelled out.
+func windPhrase(speedKmph float64, dir16 string) string {
+	var strength string
+	switch {
+	case speedKmph < 2:
+		return "calm air"
+	case speedKmph < 20:
+		strength = "light wind"
+	case speedKmph < 39:
+		strength = "moderate wind"
+	case speedKmph < 62:
+		strength = "strong wind"
+	default:
+		strength = "gale-force wind"
+	}
+	if dir, ok := compassWords[dir16]; ok {
+		return strength + " from the " + dir
+	}
+	return strength
+}
+
+// weatherA11y renders the current conditions as one plain sentence with
+// no emoji or symbols and every unit spelled out, for screen readers:
+// "London: Overcast skies, 15 degrees Celsius, light wind from the
+// northwest, humidity 70 percent."
+func weatherA11y(place string, cur CurrentCondition) string {
+	parts := []string{
+		conditionPhrase(cur.WeatherCode, cur.WeatherDesc.String()),
+		spokenDegrees(float64(cur.TempC)),
+	}
+	if math.Round(float64(cur.FeelsLikeC)) != math.Round(float64(cur.TempC)) {
+		parts = append(parts, "feeling like "+spokenDegrees(float64(cur.FeelsLikeC)))
+	}
+	parts = append(parts,
+		windPhrase(float64(cur.WindSpeedKmph), cur.WindDir16Point),
+		fmt.Sprintf("humidity %d percent", int(cur.Humidity)),
+	)
+	if parts[0] == "" {
+		parts = parts[1:]
+	}
+
+	first, size := utf8.DecodeRuneInString(parts[0])
+	parts[0] = string(unicode.ToUpper(first)) + parts[0][size:]
+	sentence := strings.Join(parts, ", ") + "."
+	if place = stripEmoji(place); place != "" {
+		sentence = place + ": " + sentence
+	}
+	return sentence
+}
+
+// GetWeatherA11y returns the current conditions as a plain sentence for
+// screen readers.
+func (c *WeatherClient) GetWeatherA11y(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	cur, err := w.current()
+	if err != nil {
+		return "", err
+	}
+
+	var place string
+	if len(w.NearestArea) > 0 {
+		place = w.NearestArea[0].AreaName.String()
+	}
+	return weatherA11y(place, cur), nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestWeatherA11y(t *testing.T) {
+	cur := CurrentCondition{
+		TempC:          15,
+		FeelsLikeC:     15,
+		Humidity:       70,
+		WeatherCode:    122,
+		WeatherDesc:    text{{Value: "Overcast"}},
+		WindDir16Point: "NW",
+		WindSpeedKmph:  11,
+	}
+
+	got := weatherA11y("London", cur)
+	if want := "London: Overcast skies, 15 degrees Celsius, light wind from the northwest, humidity 70 percent."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	cur.TempC, cur.FeelsLikeC, cur.WindSpeedKmph = -1, -6, 45
+	got = weatherA11y("", cur)
+	if want := "Overcast skies, minus 1 degree Celsius, feeling like minus 6 degrees Celsius, strong wind from the northwest, humidity 70 percent."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWeatherA11yNoEmoji(t *testing.T) {
+	// An unknown code falls back to wttr.in's description, emoji and all.
+	cur := CurrentCondition{
+		TempC:       20,
+		FeelsLikeC:  20,
+		Humidity:    40,
+		WeatherCode: 999,
+		WeatherDesc: text{{Value: "☀️ Sunny 🌤"}},
+	}
+
+	got := weatherA11y("Zürich 🏔", cur)
+	if want := "Zürich: Sunny, 20 degrees Celsius, calm air, humidity 40 percent."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for _, r := range got {
+		if unicode.Is(unicode.So, r) || unicode.Is(unicode.Variation_Selector, r) {
+			t.Errorf("unexpected symbol %q in %q", r, got)
+		}
+	}
+}
+
+func TestWeatherCodePhrasesCoverIcons(t *testing.T) {
+	for code := range weatherCodeIcons {
+		phrase, ok := weatherCodePhrases[code]
+		if !ok {
+			t.Errorf("code %d has an icon but no phrase", code)
+			continue
+		}
+		if stripEmoji(phrase) != phrase || strings.ToLower(phrase) != phrase {
+			t.Errorf("code %d: phrase %q should be plain lowercase text", code, phrase)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// activityRules are the conditions an activity is comfortable in (ideal)
+// and still possible in (limit). Temperatures are the feels-like
+// temperature in °C, wind is km/h and rain is the chance in percent.
+type activityRules struct {
+	minTemp, idealMinTemp, idealMaxTemp, maxTemp float64
+	idealWind, maxWind                           float64
+	idealRain, maxRain                           float64
+}
+
+// activities holds the rule set of each supported activity. Running is
+// fine in the cold but not the heat, cycling is the most wind sensitive and
+// hiking sits in between with a lower tolerance for rain than running.
+var activities = map[string]activityRules{
+	"running": {minTemp: -10, idealMinTemp: 5, idealMaxTemp: 20, maxTemp: 30, idealWind: 30, maxWind: 50, idealRain: 40, maxRain: 80},
+	"cycling": {minTemp: 0, idealMinTemp: 10, idealMaxTemp: 25, maxTemp: 35, idealWind: 15, maxWind: 30, idealRain: 20, maxRain: 50},
+	"hiking":  {minTemp: -5, idealMinTemp: 8, idealMaxTemp: 24, maxTemp: 32, idealWind: 25, maxWind: 45, idealRain: 30, maxRain: 60},
+}
+
+// activityNames returns the supported activities, sorted.
+func activityNames() []string {
+	names := make([]string, 0, len(activities))
+	for name := range activities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Suitability is the verdict on doing an activity today.
+type Suitability struct {
+	Activity     string   `json:"activity"`
+	Verdict      string   `json:"verdict"`
+	Reasons      []string `json:"reasons"`
+	FeelsLikeC   float64  `json:"feels_like_c"`
+	WindKmph     float64  `json:"wind_kmph"`
+	ChanceOfRain int      `json:"chance_of_rain"`
+}
+
+// suitability judges the current conditions and today's rain chance
+// against the activity's rules. Any limit exceeded makes the verdict
+// "poor", anything outside the ideal range "fair", and otherwise it is
+// "good". Rain falling now counts as a certain chance of rain.
+func suitability(w DetailedWeather, activity string) (Suitability, error) {
+	rules, ok := activities[activity]
+	if !ok {
+		return Suitability{}, fmt.Errorf("unknown activity %q", activity)
+	}
+	cur, err := w.current()
+	if err != nil {
+		return Suitability{}, err
+	}
+
+	rain := 0.0
+	if len(w.Weather) > 0 {
+		rain = float64(summarizeDay(w.Weather[0]).ChanceOfRain)
+	}
+	if cur.PrecipMM >= 0.5 {
+		rain = 100
+	}
+
+	s := Suitability{
+		Activity:     activity,
+		FeelsLikeC:   float64(cur.FeelsLikeC),
+		WindKmph:     float64(cur.WindSpeedKmph),
+		ChanceOfRain: int(math.Round(rain)),
+	}
+	rules.judge(&s, float64(cur.PrecipMM), "today")
+	return s, nil
+}
+
+// judge sets the verdict and reasons of s from its conditions and returns
+// how many limits and ideals they miss. precipMM is rain falling now, when
+// the conditions are current ones; when names the day in reasons.
+func (rules activityRules) judge(s *Suitability, precipMM float64, when string) (limits, ideals int) {
+	s.Verdict = "good"
+	s.Reasons = []string{}
+	rain := float64(s.ChanceOfRain)
+
+	note := func(limit bool, reason string) {
+		if limit {
+			limits++
+		} else {
+			ideals++
+		}
+		s.Reasons = append(s.Reasons, reason)
+	}
+
+	switch temp := s.FeelsLikeC; {
+	case temp < rules.minTemp:
+		note(true, fmt.Sprintf("too cold: feels like %g°C", temp))
+	case temp < rules.idealMinTemp:
+		note(false, fmt.Sprintf("cool: feels like %g°C", temp))
+	case temp > rules.maxTemp:
+		note(true, fmt.Sprintf("too hot: feels like %g°C", temp))
+	case temp > rules.idealMaxTemp:
+		note(false, fmt.Sprintf("warm: feels like %g°C", temp))
+	}
+
+	switch wind := s.WindKmph; {
+	case wind > rules.maxWind:
+		note(true, fmt.Sprintf("too windy: %g km/h", wind))
+	case wind > rules.idealWind:
+		note(false, fmt.Sprintf("breezy: %g km/h", wind))
+	}
+
+	switch {
+	case precipMM >= 0.5:
+		note(rain > rules.maxRain, fmt.Sprintf("raining now: %g mm", precipMM))
+	case rain > rules.maxRain:
+		note(true, fmt.Sprintf("rain likely: %d%% chance %s", s.ChanceOfRain, when))
+	case rain > rules.idealRain:
+		note(false, fmt.Sprintf("rain possible: %d%% chance %s", s.ChanceOfRain, when))
+	}
+
+	switch {
+	case limits > 0:
+		s.Verdict = "poor"
+	case ideals > 0:
+		s.Verdict = "fair"
+	default:
+		s.Reasons = append(s.Reasons, fmt.Sprintf("temperature, wind and rain are all comfortable for %s", s.Activity))
+	}
+	return limits, ideals
+}
+
+// GetActivitySuitability judges whether today suits an activity as JSON.
+func (c *WeatherClient) GetActivitySuitability(location, activity string) (string, error) {
+	if _, ok := activities[activity]; !ok {
+		return "", fmt.Errorf("unknown activity %q", activity)
+	}
+
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := suitability(w, activity)
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(result)
+}
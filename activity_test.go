@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuitabilityRunningInColdRain(t *testing.T) {
+	w := DetailedWeather{
+		CurrentCondition: []CurrentCondition{{FeelsLikeC: 1, WindSpeedKmph: 12, PrecipMM: 2.4}},
+		Weather:          []DayForecast{{Hourly: []HourlyWeather{{Time: 1200, ChanceOfRain: 90}}}},
+	}
+
+	s, err := suitability(w, "running")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s.Verdict != "poor" || s.ChanceOfRain != 100 {
+		t.Errorf("expected poor verdict in the rain, got %+v", s)
+	}
+	reasons := strings.Join(s.Reasons, "; ")
+	if !strings.Contains(reasons, "cool") || !strings.Contains(reasons, "raining now") {
+		t.Errorf("expected cold and rain reasons, got %q", reasons)
+	}
+}
+
+func TestSuitabilityCyclingCalmClear(t *testing.T) {
+	w := DetailedWeather{
+		CurrentCondition: []CurrentCondition{{FeelsLikeC: 19, WindSpeedKmph: 6}},
+		Weather:          []DayForecast{{Hourly: []HourlyWeather{{Time: 1200, ChanceOfRain: 0}}}},
+	}
+
+	s, err := suitability(w, "cycling")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Verdict != "good" || len(s.Reasons) != 1 {
+		t.Errorf("expected a good verdict, got %+v", s)
+	}
+}
+
+func TestSuitabilityRulesDiffer(t *testing.T) {
+	// 22 km/h is fine for running but breezy for cycling.
+	w := DetailedWeather{CurrentCondition: []CurrentCondition{{FeelsLikeC: 15, WindSpeedKmph: 22}}}
+
+	run, _ := suitability(w, "running")
+	bike, _ := suitability(w, "cycling")
+	if run.Verdict != "good" || bike.Verdict != "fair" {
+		t.Errorf("expected good running and fair cycling, got %s and %s", run.Verdict, bike.Verdict)
+	}
+}
+
+func TestSuitabilityUnknownActivity(t *testing.T) {
+	if _, err := suitability(DetailedWeather{}, "skydiving"); err == nil {
+		t.Fatal("expected error for unknown activity")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import "fmt"
+
+// Coordinates is a point on the globe in decimal degrees.
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// antipode returns the point on the opposite side of the globe, with the
+// longitude normalized to (-180, 180] and both rounded to 4 decimal places
+// (about 10 m) to drop floating point noise.
+func antipode(p Coordinates) Coordinates {
+	lon := p.Longitude + 180
+	if lon > 180 {
+		lon -= 360
+	}
+	return Coordinates{Latitude: roundTo(-p.Latitude, 4), Longitude: roundTo(lon, 4)}
+}
+
+// query formats the coordinates as a wttr.in location.
+func (p Coordinates) query() string {
+	return fmt.Sprintf("%.4f,%.4f", p.Latitude, p.Longitude)
+}
+
+// AntipodePlace is one end of an antipode report.
+type AntipodePlace struct {
+	Name        string      `json:"name,omitempty"`
+	Coordinates Coordinates `json:"coordinates"`
+	TempC       *float64    `json:"temp_c,omitempty"`
+	Description string      `json:"description,omitempty"`
+}
+
+// AntipodeReport compares the weather at a location and at its antipode.
+type AntipodeReport struct {
+	Origin   AntipodePlace `json:"origin"`
+	Antipode AntipodePlace `json:"antipode"`
+	Note     string        `json:"note,omitempty"`
+}
+
+// antipodePlace describes the weather data of a place. Missing fields are
+// left out, since wttr.in has little to say about the open ocean.
+func antipodePlace(w DetailedWeather, at Coordinates) AntipodePlace {
+	place := AntipodePlace{Coordinates: at}
+	if len(w.NearestArea) > 0 {
+		area := w.NearestArea[0]
+		place.Name = area.AreaName.String()
+		if country := area.Country.String(); country != "" && place.Name != "" {
+			place.Name += ", " + country
+		}
+	}
+	if cur, err := w.current(); err == nil {
+		temp := float64(cur.TempC)
+		place.TempC = &temp
+		place.Description = cur.WeatherDesc.String()
+	}
+	return place
+}
+
+// GetAntipodeWeather resolves the location, then fetches the weather on the
+// opposite side of the globe and returns both as JSON.
+func (c *WeatherClient) GetAntipodeWeather(location string) (string, error) {
+	origin, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	if len(origin.NearestArea) == 0 {
+		return "", fmt.Errorf("could not resolve coordinates for %q", location)
+	}
+	area := origin.NearestArea[0]
+	from := Coordinates{Latitude: float64(area.Latitude), Longitude: float64(area.Longitude)}
+	to := antipode(from)
+
+	report := AntipodeReport{Origin: antipodePlace(origin, from)}
+
+	w, err := c.fetchDetailed(to.query())
+	if err != nil {
+		// wttr.in sometimes fails outright for points far out at sea.
+		report.Antipode = AntipodePlace{Coordinates: to}
+		report.Note = fmt.Sprintf("No weather data for the antipode: %v", err)
+		return marshalResult(report)
+	}
+
+	report.Antipode = antipodePlace(w, to)
+	if report.Antipode.TempC == nil {
+		report.Note = "The antipode is likely open ocean; wttr.in has no observations there."
+	}
+	return marshalResult(report)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAntipode(t *testing.T) {
+	tests := []struct {
+		in, want Coordinates
+	}{
+		{Coordinates{51.517, -0.106}, Coordinates{-51.517, 179.894}},
+		{Coordinates{-33.8688, 151.2093}, Coordinates{33.8688, -28.7907}},
+		{Coordinates{0, 0}, Coordinates{0, 180}},
+		{Coordinates{40, 180}, Coordinates{-40, 0}},
+	}
+
+	for _, tt := range tests {
+		if got := antipode(tt.in); got != tt.want {
+			t.Errorf("antipode(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWeatherClientGetAntipodeWeather(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/Madrid" {
+			w.Write([]byte(`{
+				"current_condition": [{"temp_C": "30", "weatherDesc": [{"value": "Sunny"}]}],
+				"nearest_area": [{"areaName": [{"value": "Madrid"}], "country": [{"value": "Spain"}], "latitude": "40.4", "longitude": "-3.683"}]
+			}`))
+			return
+		}
+		w.Write([]byte(`{
+			"current_condition": [{"temp_C": "12", "weatherDesc": [{"value": "Light rain"}]}],
+			"nearest_area": [{"areaName": [{"value": "Weston"}], "country": [{"value": "New Zealand"}], "latitude": "-40.4", "longitude": "176.317"}]
+		}`))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.GetAntipodeWeather("Madrid")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(paths) != 2 || paths[1] != "/-40.4000,176.3170" {
+		t.Errorf("expected a second fetch at the antipode, got %v", paths)
+	}
+
+	var report AntipodeReport
+	if err := json.Unmarshal([]byte(result), &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if report.Origin.Name != "Madrid, Spain" || report.Antipode.Name != "Weston, New Zealand" {
+		t.Errorf("unexpected places: %+v", report)
+	}
+	if report.Antipode.TempC == nil || *report.Antipode.TempC != 12 || report.Note != "" {
+		t.Errorf("unexpected antipode weather: %+v", report.Antipode)
+	}
+}
+
+func TestWeatherClientGetAntipodeWeatherOcean(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/London" {
+			w.Write([]byte(`{"current_condition": [{"temp_C": "15"}], "nearest_area": [{"latitude": "51.517", "longitude": "-0.106"}]}`))
+			return
+		}
+		w.Write([]byte(`{"current_condition": [], "nearest_area": []}`))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.GetAntipodeWeather("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var report AntipodeReport
+	if err := json.Unmarshal([]byte(result), &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if report.Antipode.TempC != nil || report.Note == "" {
+		t.Errorf("expected an open ocean note, got %+v", report)
+	}
+	if report.Antipode.Coordinates != (Coordinates{-51.517, 179.894}) {
+		t.Errorf("unexpected antipode coordinates: %+v", report.Antipode.Coordinates)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// onThisDayYears is how many prior years get_on_this_day looks back.
+const onThisDayYears = 3
+
+// archiveNotConfigured is the result of get_on_this_day when no archive
+// provider is set up.
+const archiveNotConfigured = "historical data not configured"
+
+// ArchiveProvider looks up past weather at a coordinate.
+type ArchiveProvider interface {
+	// DailyMeanTemp returns the mean temperature in °C on date.
+	DailyMeanTemp(lat, lon float64, date time.Time) (float64, error)
+}
+
+// openMeteoArchive reads daily means from an Open-Meteo compatible archive API.
+type openMeteoArchive struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+func (a *openMeteoArchive) DailyMeanTemp(lat, lon float64, date time.Time) (float64, error) {
+	day := date.Format(j1DateLayout)
+	q := url.Values{}
+	q.Set("latitude", fmt.Sprintf("%.4f", lat))
+	q.Set("longitude", fmt.Sprintf("%.4f", lon))
+	q.Set("start_date", day)
+	q.Set("end_date", day)
+	q.Set("daily", "temperature_2m_mean")
+	q.Set("timezone", "auto")
+
+	resp, err := a.httpClient.Get(a.baseURL + "?" + q.Encode())
+	if err != nil {
+		return 0, fmt.Errorf("fetching archive: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("reading archive response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("archive returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var data struct {
+		Daily struct {
+			Mean []*float64 `json:"temperature_2m_mean"`
+		} `json:"daily"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return 0, fmt.Errorf("parsing archive response: %w", err)
+	}
+	if len(data.Daily.Mean) == 0 || data.Daily.Mean[0] == nil {
+		return 0, fmt.Errorf("archive has no temperature for %s", day)
+	}
+	return *data.Daily.Mean[0], nil
+}
+
+// PastDay is the temperature on the same calendar day of a prior year.
+type PastDay struct {
+	Date        string  `json:"date"`
+	YearsAgo    int     `json:"years_ago"`
+	MeanTempC   float64 `json:"mean_c"`
+	DifferenceC float64 `json:"difference_c"`
+}
+
+// OnThisDay compares today's temperature with the same day in prior years.
+type OnThisDay struct {
+	Date     string    `json:"date"`
+	TempC    float64   `json:"temp_c"`
+	PastDays []PastDay `json:"past_days"`
+	Summary  string    `json:"summary"`
+}
+
+// onThisDay looks up the same calendar day in each of the prior years.
+// Years the archive cannot answer are skipped; it fails only if none can be
+// answered. Difference is positive when it is warmer now than back then.
+func onThisDay(w DetailedWeather, archive ArchiveProvider, years int) (OnThisDay, error) {
+	cur, err := w.current()
+	if err != nil {
+		return OnThisDay{}, err
+	}
+	if len(w.NearestArea) == 0 {
+		return OnThisDay{}, fmt.Errorf("no coordinates in weather data")
+	}
+	area := w.NearestArea[0]
+
+	today, err := localDate(w)
+	if err != nil {
+		return OnThisDay{}, err
+	}
+
+	result := OnThisDay{Date: today.Format(j1DateLayout), TempC: float64(cur.TempC), PastDays: []PastDay{}}
+
+	var lastErr error
+	for y := 1; y <= years; y++ {
+		date := today.AddDate(-y, 0, 0)
+		mean, err := archive.DailyMeanTemp(float64(area.Latitude), float64(area.Longitude), date)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		result.PastDays = append(result.PastDays, PastDay{
+			Date:        date.Format(j1DateLayout),
+			YearsAgo:    y,
+			MeanTempC:   mean,
+			DifferenceC: result.TempC - mean,
+		})
+	}
+	if len(result.PastDays) == 0 {
+		return OnThisDay{}, lastErr
+	}
+
+	return result, nil
+}
+
+// summarizePast describes a past day relative to now.
+func summarizePast(p PastDay) string {
+	ago := "A year ago"
+	if p.YearsAgo > 1 {
+		ago = fmt.Sprintf("%d years ago", p.YearsAgo)
+	}
+
+	switch {
+	case p.DifferenceC > 0:
+		return fmt.Sprintf("%s today it was %g°C, %g°C colder than now.", ago, p.MeanTempC, p.DifferenceC)
+	case p.DifferenceC < 0:
+		return fmt.Sprintf("%s today it was %g°C, %g°C warmer than now.", ago, p.MeanTempC, -p.DifferenceC)
+	default:
+		return fmt.Sprintf("%s today it was %g°C, the same as now.", ago, p.MeanTempC)
+	}
+}
+
+// GetOnThisDay compares today's temperature with the same day in prior years
+// as JSON, or reports that no archive is configured.
+func (c *WeatherClient) GetOnThisDay(location string) (string, error) {
+	if c.archive == nil {
+		return archiveNotConfigured, nil
+	}
+
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := onThisDay(w, c.archive, onThisDayYears)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range result.PastDays {
+		result.PastDays[i].MeanTempC = c.round(result.PastDays[i].MeanTempC)
+		result.PastDays[i].DifferenceC = c.round(result.PastDays[i].DifferenceC)
+	}
+	result.Summary = c.theme.decorate(glyphThermometer, summarizePast(result.PastDays[0]))
+	return marshalResult(result)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockArchive returns the configured mean temperature per date.
+type mockArchive struct {
+	temps    map[string]float64
+	lat, lon float64
+}
+
+func (a *mockArchive) DailyMeanTemp(lat, lon float64, date time.Time) (float64, error) {
+	a.lat, a.lon = lat, lon
+	temp, ok := a.temps[date.Format(j1DateLayout)]
+	if !ok {
+		return 0, errors.New("no data")
+	}
+	return temp, nil
+}
+
+func onThisDayFixture() DetailedWeather {
+	return DetailedWeather{
+		CurrentCondition: []CurrentCondition{{TempC: 18, LocalObsDateTime: "2024-06-12 02:35 PM"}},
+		NearestArea:      []NearestArea{{Latitude: 51.517, Longitude: -0.106}},
+	}
+}
+
+func TestOnThisDay(t *testing.T) {
+	archive := &mockArchive{temps: map[string]float64{
+		"2023-06-12": 15.5,
+		"2022-06-12": 21,
+	}}
+
+	result, err := onThisDay(onThisDayFixture(), archive, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if archive.lat != 51.517 || archive.lon != -0.106 {
+		t.Errorf("expected lookup by coordinates, got %v,%v", archive.lat, archive.lon)
+	}
+	if result.Date != "2024-06-12" || result.TempC != 18 {
+		t.Errorf("unexpected today: %+v", result)
+	}
+	// 2021 is missing from the archive and skipped.
+	if len(result.PastDays) != 2 {
+		t.Fatalf("expected 2 past days, got %+v", result.PastDays)
+	}
+	if p := result.PastDays[0]; p.Date != "2023-06-12" || p.YearsAgo != 1 || p.DifferenceC != 2.5 {
+		t.Errorf("unexpected first past day: %+v", p)
+	}
+	if p := result.PastDays[1]; p.YearsAgo != 2 || p.DifferenceC != -3 {
+		t.Errorf("unexpected second past day: %+v", p)
+	}
+}
+
+func TestOnThisDayNoArchiveData(t *testing.T) {
+	if _, err := onThisDay(onThisDayFixture(), &mockArchive{}, 3); err == nil {
+		t.Fatal("expected error when no year can be answered")
+	}
+}
+
+func TestSummarizePast(t *testing.T) {
+	tests := []struct {
+		past PastDay
+		want string
+	}{
+		{PastDay{YearsAgo: 1, MeanTempC: 15.5, DifferenceC: 2.5}, "A year ago today it was 15.5°C, 2.5°C colder than now."},
+		{PastDay{YearsAgo: 2, MeanTempC: 21, DifferenceC: -3}, "2 years ago today it was 21°C, 3°C warmer than now."},
+		{PastDay{YearsAgo: 1, MeanTempC: 18}, "A year ago today it was 18°C, the same as now."},
+	}
+
+	for _, tt := range tests {
+		if got := summarizePast(tt.past); got != tt.want {
+			t.Errorf("summarizePast(%+v) = %q, want %q", tt.past, got, tt.want)
+		}
+	}
+}
+
+func TestWeatherClientGetOnThisDayNotConfigured(t *testing.T) {
+	client := &WeatherClient{}
+
+	result, err := client.GetOnThisDay("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "historical data not configured" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestOpenMeteoArchive(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("latitude") != "51.5170" || q.Get("start_date") != "2023-06-12" || q.Get("end_date") != "2023-06-12" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"daily": {"time": ["2023-06-12"], "temperature_2m_mean": [15.5]}}`))
+	}))
+	defer srv.Close()
+
+	archive := &openMeteoArchive{httpClient: srv.Client(), baseURL: srv.URL}
+	temp, err := archive.DailyMeanTemp(51.517, -0.106, time.Date(2023, 6, 12, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if temp != 15.5 {
+		t.Errorf("expected 15.5, got %v", temp)
+	}
+}
+
+func TestWeatherClientGetOnThisDay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"current_condition": [{"temp_C": "18", "localObsDateTime": "2024-06-12 02:35 PM"}],
+			"nearest_area": [{"latitude": "51.517", "longitude": "-0.106"}]
+		}`))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+		precision:  1,
+		archive:    &mockArchive{temps: map[string]float64{"2023-06-12": 15.46}},
+	}
+
+	result, err := client.GetOnThisDay("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, `"mean_c":15.5`) || !strings.Contains(result, "A year ago today it was 15.5°C, 2.5°C colder than now.") {
+		t.Errorf("unexpected result: %s", result)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// areaCodes maps US telephone area codes and common city abbreviations to
+// a representative city wttr.in resolves unambiguously. To support another
+// code, add it here; lookups ignore case and surrounding spaces.
+var areaCodes = map[string]string{
+	// Area codes.
+	"202": "Washington DC",
+	"206": "Seattle",
+	"212": "New York",
+	"213": "Los Angeles",
+	"214": "Dallas",
+	"305": "Miami",
+	"312": "Chicago",
+	"404": "Atlanta",
+	"415": "San Francisco",
+	"503": "Portland, Oregon",
+	"512": "Austin",
+	"602": "Phoenix",
+	"617": "Boston",
+	"702": "Las Vegas",
+	"713": "Houston",
+	"720": "Denver",
+
+	// Abbreviations.
+	"ATL":  "Atlanta",
+	"CHI":  "Chicago",
+	"DC":   "Washington DC",
+	"LA":   "Los Angeles",
+	"LV":   "Las Vegas",
+	"NOLA": "New Orleans",
+	"NYC":  "New York",
+	"PHL":  "Philadelphia",
+	"SF":   "San Francisco",
+}
+
+// resolveAreaCode returns the city for an area code or abbreviation.
+func resolveAreaCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return "", invalidArgument("code must not be empty")
+	}
+	city, ok := areaCodes[code]
+	if !ok {
+		return "", invalidArgument("unknown code %q", code)
+	}
+	return city, nil
+}
+
+// GetWeatherByCode resolves an area code or abbreviation to its city and
+// returns the current weather one-liner labeled with the city and code.
+func (c *WeatherClient) GetWeatherByCode(code string) (string, error) {
+	city, err := resolveAreaCode(code)
+	if err != nil {
+		return "", err
+	}
+	line, err := c.GetCurrent(city)
+	if err != nil {
+		return "", err
+	}
+	return labelOneLiner(line, fmt.Sprintf("%s (%s)", city, strings.ToUpper(strings.TrimSpace(code)))), nil
+}
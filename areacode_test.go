@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveAreaCode(t *testing.T) {
+	for code, want := range map[string]string{"212": "New York", " nyc ": "New York", "sf": "San Francisco"} {
+		if city, err := resolveAreaCode(code); err != nil || city != want {
+			t.Errorf("resolveAreaCode(%q) = %q, %v; want %q", code, city, err, want)
+		}
+	}
+}
+
+func TestResolveAreaCodeUnknown(t *testing.T) {
+	for _, code := range []string{"999", "XYZ", ""} {
+		if _, err := resolveAreaCode(code); errorCode(err) != errorCodeInvalidArguments {
+			t.Errorf("%q: expected an invalid argument error, got %v", code, err)
+		}
+	}
+}
+
+func TestWeatherClientGetWeatherByCode(t *testing.T) {
+	var receivedRawURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedRawURL = r.RequestURI
+		w.Write([]byte("New York: ☀️ +20°C"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.GetWeatherByCode("212")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "New York (212): ☀️ +20°C" {
+		t.Errorf("unexpected result: %s", result)
+	}
+	if !strings.HasPrefix(receivedRawURL, "/New%20York?") {
+		t.Errorf("expected the resolved city to be fetched, got %s", receivedRawURL)
+	}
+}
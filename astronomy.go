@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// parseClock parses the 12-hour times wttr.in uses ("06:12 AM") and returns
+// the time of day as a duration since midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("03:04 PM", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("parsing time %q: %w", s, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// localTimeOfDay returns the observation time at the location as a duration since midnight.
+func localTimeOfDay(w DetailedWeather) (time.Duration, error) {
+	cur, err := w.current()
+	if err != nil {
+		return 0, err
+	}
+	t, err := time.Parse(j1DateTimeLayout, cur.LocalObsDateTime)
+	if err != nil {
+		return 0, fmt.Errorf("parsing observation time %q: %w", cur.LocalObsDateTime, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// todayAstronomy returns the sun and moon times of the first forecast day.
+func todayAstronomy(w DetailedWeather) (Astronomy, error) {
+	if len(w.Weather) == 0 || len(w.Weather[0].Astronomy) == 0 {
+		return Astronomy{}, fmt.Errorf("no astronomy data in weather data")
+	}
+	return w.Weather[0].Astronomy[0], nil
+}
+
+// SunSafety is the sun protection advice for the current conditions.
+type SunSafety struct {
+	UVIndex             float64 `json:"uv_index"`
+	CloudCover          float64 `json:"cloud_cover"`
+	EffectiveUV         float64 `json:"effective_uv"`
+	Daylight            bool    `json:"daylight"`
+	Risk                string  `json:"risk"`
+	SafeExposureMinutes *int    `json:"safe_exposure_minutes,omitempty"`
+	Advice              string  `json:"advice"`
+}
+
+// sunSafety turns UV index, cloud cover and daylight into advice.
+//
+// Cloud cover attenuates the UV index by up to half under full overcast. The
+// effective UV is classified with the WHO bands (low < 3, moderate < 6,
+// high < 8, very high < 11, extreme). The safe exposure time is the time for
+// fair (type II) skin to burn, roughly 167 minutes divided by the effective
+// UV. At night there is no risk and no exposure limit.
+func sunSafety(uv, cloudCover float64, daytime bool) SunSafety {
+	s := SunSafety{UVIndex: uv, CloudCover: cloudCover, Daylight: daytime}
+
+	if !daytime {
+		s.Risk = "none"
+		s.Advice = "The sun is down; no sun protection needed."
+		return s
+	}
+
+	s.EffectiveUV = uv * (1 - 0.5*math.Max(0, math.Min(100, cloudCover))/100)
+
+	switch {
+	case s.EffectiveUV < 3:
+		s.Risk = "low"
+		s.Advice = "Low UV. Sunscreen is optional for short periods outside."
+	case s.EffectiveUV < 6:
+		s.Risk = "moderate"
+		s.Advice = "Moderate UV. Wear sunscreen and a hat if outside for long, and seek shade around midday."
+	case s.EffectiveUV < 8:
+		s.Risk = "high"
+		s.Advice = "High UV. Wear sunscreen, a hat and sunglasses, and reduce time in the sun around midday."
+	case s.EffectiveUV < 11:
+		s.Risk = "very high"
+		s.Advice = "Very high UV. Apply SPF 30+ sunscreen and avoid the midday sun."
+	default:
+		s.Risk = "extreme"
+		s.Advice = "Extreme UV. Avoid being outside around midday; full protection is essential."
+	}
+
+	if s.EffectiveUV >= 1 {
+		minutes := int(math.Round(167 / s.EffectiveUV))
+		s.SafeExposureMinutes = &minutes
+	}
+
+	return s
+}
+
+// isDaylight reports whether the observation time falls between today's
+// sunrise and sunset. Without usable times it assumes daylight while the UV
+// index is above zero.
+func isDaylight(w DetailedWeather) bool {
+	now, err := localTimeOfDay(w)
+	if err == nil {
+		if astro, err := todayAstronomy(w); err == nil {
+			sunrise, errRise := parseClock(astro.Sunrise)
+			sunset, errSet := parseClock(astro.Sunset)
+			if errRise == nil && errSet == nil {
+				return now >= sunrise && now < sunset
+			}
+		}
+	}
+
+	cur, err := w.current()
+	return err == nil && cur.UVIndex > 0
+}
+
+// GetSunSafety returns sun protection advice for the current conditions as JSON.
+func (c *WeatherClient) GetSunSafety(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	cur, err := w.current()
+	if err != nil {
+		return "", err
+	}
+
+	advice := sunSafety(float64(cur.UVIndex), float64(cur.CloudCover), isDaylight(w))
+	advice.EffectiveUV = c.round(advice.EffectiveUV)
+	if advice.Daylight {
+		advice.Advice = c.theme.decorate(glyphSun, advice.Advice)
+	} else {
+		advice.Advice = c.theme.decorate(glyphMoon, advice.Advice)
+	}
+	return marshalResult(advice)
+}
+
+// Daylight describes the sun times and day length at a location.
+type Daylight struct {
+	Sunrise       timeValue `json:"sunrise"`
+	Sunset        timeValue `json:"sunset"`
+	DayLength     string    `json:"day_length"`
+	Trend         string    `json:"trend,omitempty"`
+	ChangeMinutes *int      `json:"change_minutes,omitempty"`
+}
+
+// formatClock formats a duration since midnight as 24-hour HH:MM.
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// dayLength returns the sunrise, sunset and their difference for a forecast
+// day. A sunset before the sunrise is taken to be after midnight.
+func dayLength(d DayForecast) (sunrise, sunset, length time.Duration, err error) {
+	if len(d.Astronomy) == 0 {
+		return 0, 0, 0, fmt.Errorf("no astronomy data for %s", d.Date)
+	}
+	astro := d.Astronomy[0]
+
+	sunrise, err = parseClock(astro.Sunrise)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("no usable sunrise on %s (polar day or night?): %w", d.Date, err)
+	}
+	sunset, err = parseClock(astro.Sunset)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("no usable sunset on %s (polar day or night?): %w", d.Date, err)
+	}
+	length = sunset - sunrise
+	if length < 0 {
+		// At high latitudes the sun can set after midnight.
+		length += 24 * time.Hour
+	}
+	return sunrise, sunset, length, nil
+}
+
+// daylight computes today's day length and, when tomorrow is in the
+// forecast, whether the days are lengthening or shortening.
+func daylight(w DetailedWeather) (Daylight, error) {
+	if len(w.Weather) == 0 {
+		return Daylight{}, fmt.Errorf("no forecast days in weather data")
+	}
+
+	sunrise, sunset, length, err := dayLength(w.Weather[0])
+	if err != nil {
+		return Daylight{}, err
+	}
+
+	result := Daylight{
+		Sunrise:   timeValue(formatClock(sunrise)),
+		Sunset:    timeValue(formatClock(sunset)),
+		DayLength: formatClock(length),
+	}
+
+	if len(w.Weather) > 1 {
+		if _, _, next, err := dayLength(w.Weather[1]); err == nil {
+			change := int((next - length).Minutes())
+			result.ChangeMinutes = &change
+			switch {
+			case change > 0:
+				result.Trend = "lengthening"
+			case change < 0:
+				result.Trend = "shortening"
+			default:
+				result.Trend = "steady"
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// GetDaylight returns today's sunrise, sunset and day length as JSON.
+func (c *WeatherClient) GetDaylight(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := daylight(w)
+	if err != nil {
+		return "", err
+	}
+	if c.convertsTimes() {
+		if result.Sunrise, err = c.zoneClock(w, w.Weather[0].Date, result.Sunrise); err != nil {
+			return "", err
+		}
+		if result.Sunset, err = c.zoneClock(w, w.Weather[0].Date, result.Sunset); err != nil {
+			return "", err
+		}
+	}
+	return marshalResult(result)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"06:12 AM", 6*time.Hour + 12*time.Minute},
+		{"12:00 AM", 0},
+		{"12:30 PM", 12*time.Hour + 30*time.Minute},
+		{"09:18 PM", 21*time.Hour + 18*time.Minute},
+	}
+
+	for _, tt := range tests {
+		got, err := parseClock(tt.in)
+		if err != nil {
+			t.Errorf("parseClock(%q): unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseClock(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := parseClock("No moonrise"); err == nil {
+		t.Error("expected error for non-time value")
+	}
+}
+
+func TestSunSafetyHighUVClear(t *testing.T) {
+	s := sunSafety(9, 0, true)
+
+	if s.Risk != "very high" {
+		t.Errorf("expected very high risk, got %s", s.Risk)
+	}
+	if s.EffectiveUV != 9 {
+		t.Errorf("expected clear sky to keep UV 9, got %v", s.EffectiveUV)
+	}
+	if s.SafeExposureMinutes == nil || *s.SafeExposureMinutes != 19 {
+		t.Errorf("expected 19 safe minutes, got %v", s.SafeExposureMinutes)
+	}
+	if !strings.Contains(s.Advice, "sunscreen") {
+		t.Errorf("expected sunscreen advice, got %q", s.Advice)
+	}
+}
+
+func TestSunSafetyHighUVOvercast(t *testing.T) {
+	s := sunSafety(9, 100, true)
+
+	if s.EffectiveUV != 4.5 {
+		t.Errorf("expected overcast to halve UV to 4.5, got %v", s.EffectiveUV)
+	}
+	if s.Risk != "moderate" {
+		t.Errorf("expected moderate risk, got %s", s.Risk)
+	}
+	if s.SafeExposureMinutes == nil || *s.SafeExposureMinutes != 37 {
+		t.Errorf("expected 37 safe minutes, got %v", s.SafeExposureMinutes)
+	}
+}
+
+func TestSunSafetyNight(t *testing.T) {
+	s := sunSafety(9, 0, false)
+
+	if s.Risk != "none" {
+		t.Errorf("expected no risk at night, got %s", s.Risk)
+	}
+	if s.SafeExposureMinutes != nil {
+		t.Errorf("expected no exposure limit at night, got %d", *s.SafeExposureMinutes)
+	}
+}
+
+func TestIsDaylight(t *testing.T) {
+	w := DetailedWeather{
+		CurrentCondition: []CurrentCondition{{LocalObsDateTime: "2024-06-12 02:35 PM"}},
+		Weather: []DayForecast{{
+			Astronomy: []Astronomy{{Sunrise: "04:43 AM", Sunset: "09:18 PM"}},
+		}},
+	}
+	if !isDaylight(w) {
+		t.Error("expected daylight in the afternoon")
+	}
+
+	w.CurrentCondition[0].LocalObsDateTime = "2024-06-12 11:05 PM"
+	if isDaylight(w) {
+		t.Error("expected night after sunset")
+	}
+}
+
+func TestWeatherClientGetSunSafety(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"current_condition": [{"uvIndex": "7", "cloudcover": "25", "localObsDateTime": "2024-06-12 01:00 PM"}],
+			"weather": [{"astronomy": [{"sunrise": "05:00 AM", "sunset": "09:00 PM"}]}]
+		}`))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+		precision:  1,
+	}
+
+	result, err := client.GetSunSafety("Madrid")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{`"effective_uv":6.1`, `"risk":"high"`, `"daylight":true`} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %s in result: %s", want, result)
+		}
+	}
+}
+
+func daylightFixture(days ...[2]string) DetailedWeather {
+	var w DetailedWeather
+	for _, d := range days {
+		w.Weather = append(w.Weather, DayForecast{Astronomy: []Astronomy{{Sunrise: d[0], Sunset: d[1]}}})
+	}
+	return w
+}
+
+func TestDaylightSummer(t *testing.T) {
+	w := daylightFixture([2]string{"06:12 AM", "08:45 PM"}, [2]string{"06:11 AM", "08:46 PM"})
+
+	d, err := daylight(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Sunrise != "06:12" || d.Sunset != "20:45" || d.DayLength != "14:33" {
+		t.Errorf("unexpected daylight: %+v", d)
+	}
+	if d.Trend != "lengthening" || d.ChangeMinutes == nil || *d.ChangeMinutes != 2 {
+		t.Errorf("expected lengthening by 2 minutes, got %s %v", d.Trend, d.ChangeMinutes)
+	}
+}
+
+func TestDaylightNearEquinox(t *testing.T) {
+	// Around the September equinox days shrink by a few minutes each day.
+	w := daylightFixture([2]string{"06:58 AM", "07:07 PM"}, [2]string{"07:00 AM", "07:05 PM"})
+
+	d, err := daylight(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.DayLength != "12:09" {
+		t.Errorf("expected day length 12:09, got %s", d.DayLength)
+	}
+	if d.Trend != "shortening" || *d.ChangeMinutes != -4 {
+		t.Errorf("expected shortening by 4 minutes, got %s %d", d.Trend, *d.ChangeMinutes)
+	}
+}
+
+func TestDaylightSunsetAfterMidnight(t *testing.T) {
+	// Near the Arctic Circle in summer the sun sets after midnight.
+	d, err := daylight(daylightFixture([2]string{"02:10 AM", "12:40 AM"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Sunset != "00:40" || d.DayLength != "22:30" {
+		t.Errorf("expected a 22:30 day ending at 00:40, got %+v", d)
+	}
+}
+
+func TestDaylightSingleDay(t *testing.T) {
+	d, err := daylight(daylightFixture([2]string{"07:00 AM", "07:00 PM"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Trend != "" || d.ChangeMinutes != nil {
+		t.Errorf("expected no trend without tomorrow, got %+v", d)
+	}
+}
+
+func TestDaylightPolarNight(t *testing.T) {
+	if _, err := daylight(daylightFixture([2]string{"No sunrise", "No sunset"})); err == nil {
+		t.Fatal("expected error without a sunrise")
+	}
+}
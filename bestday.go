@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Day scores start at 100 and lose limitPenalty for every activity limit
+// the day's conditions exceed and idealPenalty for every ideal they miss.
+const (
+	limitPenalty = 40
+	idealPenalty = 15
+)
+
+// DayScore is the verdict on doing an activity on one forecast day.
+type DayScore struct {
+	Date  string `json:"date"`
+	Day   string `json:"day"`
+	Score int    `json:"score"`
+	Suitability
+}
+
+// BestDay is the forecast day that suits an activity best, and the scores
+// of all days it was chosen from.
+type BestDay struct {
+	Activity string     `json:"activity"`
+	Best     DayScore   `json:"best"`
+	Days     []DayScore `json:"days"`
+}
+
+// dayScore judges a forecast day by its noon feels-like temperature, its
+// strongest daytime wind and its chance of rain.
+func dayScore(d DayForecast, activity string, rules activityRules) DayScore {
+	summary := summarizeDay(d)
+	score := DayScore{Date: d.Date, Day: summary.Day}
+	score.Activity = activity
+	score.ChanceOfRain = summary.ChanceOfRain
+	if noon, ok := noonSlot(d); ok {
+		score.FeelsLikeC = float64(noon.FeelsLikeC)
+	}
+	for _, h := range d.Hourly {
+		if h.Time >= 900 && h.Time <= 1800 && float64(h.WindSpeedKmph) > score.WindKmph {
+			score.WindKmph = float64(h.WindSpeedKmph)
+		}
+	}
+
+	when := "that day"
+	if date, err := time.Parse(j1DateLayout, d.Date); err == nil {
+		when = "on " + date.Weekday().String()
+	}
+	limits, ideals := rules.judge(&score.Suitability, 0, when)
+	score.Score = max(100-limits*limitPenalty-ideals*idealPenalty, 0)
+	return score
+}
+
+// bestDay scores every forecast day for the activity. On ties the earliest
+// day wins.
+func bestDay(w DetailedWeather, activity string) (BestDay, error) {
+	rules, ok := activities[activity]
+	if !ok {
+		return BestDay{}, fmt.Errorf("unknown activity %q", activity)
+	}
+	if len(w.Weather) == 0 {
+		return BestDay{}, fmt.Errorf("no forecast in weather data")
+	}
+
+	result := BestDay{Activity: activity}
+	for _, d := range w.Weather {
+		score := dayScore(d, activity, rules)
+		if len(result.Days) == 0 || score.Score > result.Best.Score {
+			result.Best = score
+		}
+		result.Days = append(result.Days, score)
+	}
+	return result, nil
+}
+
+// GetBestDay returns the forecast day best suited to an activity, with the
+// scores of every day, as JSON.
+func (c *WeatherClient) GetBestDay(location, activity string) (string, error) {
+	if _, ok := activities[activity]; !ok {
+		return "", fmt.Errorf("unknown activity %q", activity)
+	}
+
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := bestDay(w, activity)
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(result)
+}
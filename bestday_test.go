@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// bestDayFixture is a rainy, windy Monday, a clear and calm Tuesday and a
+// Wednesday just like it.
+func bestDayFixture() DetailedWeather {
+	day := func(date string, feelsLike, wind, rain float64) DayForecast {
+		d := DayForecast{Date: date}
+		for _, t := range []number{600, 900, 1200, 1500, 1800} {
+			d.Hourly = append(d.Hourly, HourlyWeather{Time: t, FeelsLikeC: number(feelsLike), WindSpeedKmph: number(wind), ChanceOfRain: number(rain)})
+		}
+		return d
+	}
+	return DetailedWeather{Weather: []DayForecast{
+		day("2024-06-10", 14, 40, 90),
+		day("2024-06-11", 16, 10, 5),
+		day("2024-06-12", 16, 10, 5),
+	}}
+}
+
+func TestBestDay(t *testing.T) {
+	result, err := bestDay(bestDayFixture(), "cycling")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Days) != 3 {
+		t.Fatalf("expected 3 scored days, got %+v", result.Days)
+	}
+	// The tie with Wednesday goes to the earlier day.
+	if result.Best.Date != "2024-06-11" || result.Best.Score != 100 || result.Best.Verdict != "good" {
+		t.Errorf("expected the calm Tuesday to win, got %+v", result.Best)
+	}
+
+	rainy := result.Days[0]
+	if rainy.Verdict != "poor" || rainy.Score != 100-2*limitPenalty {
+		t.Errorf("unexpected rainy day: %+v", rainy)
+	}
+	if reasons := strings.Join(rainy.Reasons, "; "); reasons != "too windy: 40 km/h; rain likely: 90% chance on Monday" {
+		t.Errorf("unexpected reasons: %s", reasons)
+	}
+}
+
+func TestBestDayUnknownActivity(t *testing.T) {
+	if _, err := bestDay(bestDayFixture(), "kayaking"); err == nil {
+		t.Error("expected an error for an unknown activity")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rainOutlook phrases a day's chance of rain.
+func rainOutlook(chance int) string {
+	if chance == 0 {
+		return "no rain expected"
+	}
+	return fmt.Sprintf("a %d%% chance of rain", chance)
+}
+
+// briefing renders the current conditions, today's range and rain outlook
+// and tomorrow's preview as a paragraph.
+func briefing(w DetailedWeather) (string, error) {
+	cur, err := w.current()
+	if err != nil {
+		return "", err
+	}
+	if len(w.Weather) == 0 {
+		return "", fmt.Errorf("no forecast in weather data")
+	}
+
+	var sentences []string
+
+	now := fmt.Sprintf("it is %g°C", float64(cur.TempC))
+	if cur.FeelsLikeC != cur.TempC {
+		now += fmt.Sprintf(" (feels like %g°C)", float64(cur.FeelsLikeC))
+	}
+	if desc := cur.WeatherDesc.String(); desc != "" {
+		now += " and " + strings.ToLower(desc)
+	}
+	if len(w.NearestArea) > 0 && w.NearestArea[0].AreaName.String() != "" {
+		now = "In " + w.NearestArea[0].AreaName.String() + " " + now
+	} else {
+		now = "Right now " + now
+	}
+	sentences = append(sentences, now+".")
+
+	today := summarizeDay(w.Weather[0])
+	sentences = append(sentences, fmt.Sprintf("Today ranges from %g°C to %g°C with %s.", today.MinTempC, today.MaxTempC, rainOutlook(today.ChanceOfRain)))
+
+	if len(w.Weather) > 1 {
+		tomorrow := summarizeDay(w.Weather[1])
+		preview := fmt.Sprintf("Tomorrow: %g-%g°C", tomorrow.MinTempC, tomorrow.MaxTempC)
+		if tomorrow.Description != "" {
+			preview += ", " + strings.ToLower(tomorrow.Description)
+		}
+		sentences = append(sentences, preview+", "+rainOutlook(tomorrow.ChanceOfRain)+".")
+	}
+
+	return strings.Join(sentences, " "), nil
+}
+
+// GetBriefing returns a one-paragraph briefing from a single j1 fetch.
+func (c *WeatherClient) GetBriefing(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	return briefing(w)
+}
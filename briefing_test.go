@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestBriefing(t *testing.T) {
+	w := DetailedWeather{
+		CurrentCondition: []CurrentCondition{{TempC: 20, FeelsLikeC: 19, WeatherDesc: text{{Value: "Partly cloudy"}}}},
+		NearestArea:      []NearestArea{{AreaName: text{{Value: "London"}}}},
+		Weather: []DayForecast{
+			{Date: "2024-06-12", MinTempC: 12, MaxTempC: 22, Hourly: []HourlyWeather{{Time: 1200, ChanceOfRain: 40, WeatherDesc: text{{Value: "Partly cloudy"}}}}},
+			{Date: "2024-06-13", MinTempC: 13, MaxTempC: 21, Hourly: []HourlyWeather{{Time: 1200, ChanceOfRain: 80, WeatherDesc: text{{Value: "Light rain"}}}}},
+		},
+	}
+
+	got, err := briefing(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "In London it is 20°C (feels like 19°C) and partly cloudy. " +
+		"Today ranges from 12°C to 22°C with a 40% chance of rain. " +
+		"Tomorrow: 13-21°C, light rain, a 80% chance of rain."
+	if got != want {
+		t.Errorf("unexpected briefing:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestBriefingTodayOnly(t *testing.T) {
+	w := DetailedWeather{
+		CurrentCondition: []CurrentCondition{{TempC: -2, FeelsLikeC: -2}},
+		Weather:          []DayForecast{{Date: "2024-01-15", MinTempC: -5, MaxTempC: 1}},
+	}
+
+	got, err := briefing(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Right now it is -2°C. Today ranges from -5°C to 1°C with no rain expected."; got != want {
+		t.Errorf("unexpected briefing:\n got %q\nwant %q", got, want)
+	}
+}
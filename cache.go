@@ -0,0 +1,135 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// cacheJitter spreads entry lifetimes by up to ±10% of the TTL, so entries
+// cached together don't all expire together and hit wttr.in at once.
+const cacheJitter = 0.1
+
+// historyWindow is how long replaced responses are kept for comparing
+// readings over time.
+const historyWindow = time.Hour
+
+// sweepInterval is how often set sweeps the whole cache, so responses for
+// URLs that are never requested again don't pile up.
+const sweepInterval = time.Minute
+
+// responseCache keeps successful upstream responses by URL for a TTL.
+type responseCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	// past holds the responses entries replaced within historyWindow,
+	// oldest first.
+	past      map[string][]cacheEntry
+	lastSweep time.Time
+}
+
+type cacheEntry struct {
+	body      string
+	fetchedAt time.Time
+	expires   time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, now: time.Now, entries: map[string]cacheEntry{}, past: map[string][]cacheEntry{}}
+}
+
+// get returns the cached body for rawURL and when it was fetched.
+func (c *responseCache) get(rawURL string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[rawURL]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if !c.now().Before(entry.expires) {
+		c.past[rawURL] = append(c.past[rawURL], entry)
+		delete(c.entries, rawURL)
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// set caches body for a jittered TTL.
+func (c *responseCache) set(rawURL, body string) {
+	now := c.now()
+	jitter := time.Duration((rand.Float64()*2 - 1) * cacheJitter * float64(c.ttl))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if old, ok := c.entries[rawURL]; ok {
+		c.past[rawURL] = append(c.past[rawURL], old)
+	}
+	c.entries[rawURL] = cacheEntry{body: body, fetchedAt: now, expires: now.Add(c.ttl + jitter)}
+	c.prune(rawURL, now)
+	if now.Sub(c.lastSweep) >= sweepInterval {
+		c.sweep(now)
+	}
+}
+
+// sweep retires every expired entry to past and prunes past for every URL,
+// dropping what has aged out of historyWindow. c.mu must be held.
+func (c *responseCache) sweep(now time.Time) {
+	for rawURL, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			c.past[rawURL] = append(c.past[rawURL], entry)
+			delete(c.entries, rawURL)
+		}
+	}
+	for rawURL := range c.past {
+		c.prune(rawURL, now)
+	}
+	c.lastSweep = now
+}
+
+// stale returns the latest response for rawURL within historyWindow,
+// fresh or not, for when wttr.in cannot be asked.
+func (c *responseCache) stale(rawURL string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[rawURL]; ok {
+		return entry, true
+	}
+	c.prune(rawURL, c.now())
+	if past := c.past[rawURL]; len(past) > 0 {
+		return past[len(past)-1], true
+	}
+	return cacheEntry{}, false
+}
+
+// history returns the responses for rawURL fetched within historyWindow,
+// oldest first, including the current one even if it has expired.
+func (c *responseCache) history(rawURL string) []cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prune(rawURL, c.now())
+	entries := append([]cacheEntry(nil), c.past[rawURL]...)
+	if entry, ok := c.entries[rawURL]; ok {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// prune drops past responses older than historyWindow. c.mu must be held.
+func (c *responseCache) prune(rawURL string, now time.Time) {
+	past := c.past[rawURL]
+	i := 0
+	for i < len(past) && now.Sub(past[i].fetchedAt) > historyWindow {
+		i++
+	}
+	if i == len(past) {
+		delete(c.past, rawURL)
+		return
+	}
+	c.past[rawURL] = past[i:]
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseCacheJitter(t *testing.T) {
+	c := newResponseCache(10 * time.Minute)
+	now := time.Date(2024, 6, 12, 14, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.set("a", "body a")
+	c.set("b", "body b")
+
+	a, b := c.entries["a"].expires.Sub(now), c.entries["b"].expires.Sub(now)
+	for _, ttl := range []time.Duration{a, b} {
+		if ttl < 9*time.Minute || ttl > 11*time.Minute {
+			t.Errorf("TTL %v outside the ±10%% jitter window", ttl)
+		}
+	}
+	if a == b {
+		t.Errorf("expected jittered TTLs to differ, both %v", a)
+	}
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	c := newResponseCache(time.Minute)
+	now := time.Date(2024, 6, 12, 14, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.set("a", "body")
+	if entry, ok := c.get("a"); !ok || entry.body != "body" {
+		t.Fatalf("expected a fresh entry, got %+v", entry)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.get("a"); ok {
+		t.Error("expected the entry to have expired")
+	}
+}
+
+func TestWeatherClientCache(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("London: ☀️ +20°C"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+		cache:      newResponseCache(time.Minute),
+	}
+
+	for i := 0; i < 3; i++ {
+		if result, err := client.GetCurrent("London"); err != nil || result != "London: ☀️ +20°C" {
+			t.Fatalf("unexpected result %q (%v)", result, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 upstream request, got %d", requests)
+	}
+
+	trace := &FetchTrace{}
+	client.WithTrace(trace).GetCurrent("London")
+	if len(trace.Fetches()) != 1 {
+		t.Errorf("expected cached fetch to be traced, got %v", trace.Fetches())
+	}
+}
+
+// TestResponseCacheConcurrent hammers a cached client from many goroutines,
+// as fetchAll and get_weather_overview do. Run with -race to check the
+// cache and fetch trace locking.
+func TestResponseCacheConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("London: ☀️ +20°C"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL, cache: newResponseCache(time.Minute)}
+	trace := &FetchTrace{}
+	traced := client.WithTrace(trace)
+
+	locations := make([]string, 200)
+	for i := range locations {
+		locations[i] = fmt.Sprintf("City%d", i%10)
+	}
+	results := fetchAll(locations, func(location string) (string, error) {
+		return traced.GetCurrent(location)
+	})
+
+	for _, r := range results {
+		if r.Err != nil || r.Text != "London: ☀️ +20°C" {
+			t.Fatalf("%s: unexpected result %q (%v)", r.Location, r.Text, r.Err)
+		}
+	}
+	if n := len(trace.Fetches()); n != len(locations) {
+		t.Errorf("expected %d recorded fetches, got %d", len(locations), n)
+	}
+	if n := len(client.cache.entries); n != 10 {
+		t.Errorf("expected 10 cached locations, got %d", n)
+	}
+}
+
+func TestResponseCacheHistory(t *testing.T) {
+	c := newResponseCache(10 * time.Minute)
+	now := time.Date(2024, 6, 12, 14, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	for _, body := range []string{"a", "b", "c", "d"} {
+		c.set("u", body)
+		now = now.Add(25 * time.Minute)
+		c.get("u")
+	}
+
+	// "a" and "b" were fetched 100 and 75 minutes ago, beyond the hour kept.
+	var bodies []string
+	for _, entry := range c.history("u") {
+		bodies = append(bodies, entry.body)
+	}
+	if got := strings.Join(bodies, ","); got != "c,d" {
+		t.Errorf("unexpected history: %s", got)
+	}
+}
+
+func TestResponseCacheSweep(t *testing.T) {
+	c := newResponseCache(10 * time.Minute)
+	now := time.Date(2024, 6, 12, 14, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.set("once", "a")
+	c.set("replaced", "b")
+	c.set("replaced", "c")
+
+	// Neither URL is asked for again; the next set sweeps them out.
+	now = now.Add(2 * time.Hour)
+	c.set("other", "d")
+
+	if _, ok := c.entries["once"]; ok || len(c.entries) != 1 {
+		t.Errorf("expected only the new entry to be cached, got %v", c.entries)
+	}
+	if len(c.past) != 0 {
+		t.Errorf("expected the history beyond the hour to be dropped, got %v", c.past)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// cardWeather is the part of the j1 payload a weather card shows. Its
+// numbers are optional so fields missing upstream are left off the card
+// rather than shown as zero.
+type cardWeather struct {
+	CurrentCondition []struct {
+		TempC          optionalNumber `json:"temp_C"`
+		FeelsLikeC     optionalNumber `json:"FeelsLikeC"`
+		Humidity       optionalNumber `json:"humidity"`
+		WindSpeedKmph  optionalNumber `json:"windspeedKmph"`
+		WindDir16Point string         `json:"winddir16Point"`
+	} `json:"current_condition"`
+	NearestArea []NearestArea `json:"nearest_area"`
+}
+
+// weatherCard renders up to three lines: the place, the temperature and
+// feels-like, and the wind and humidity. Lines and parts whose fields are
+// missing are omitted. The card always has glyphs: emoji unless the theme
+// is ascii.
+func weatherCard(body string, theme Theme) (string, error) {
+	var w cardWeather
+	if err := json.Unmarshal([]byte(body), &w); err != nil {
+		return "", fmt.Errorf("parsing weather data: %w", err)
+	}
+	if len(w.CurrentCondition) == 0 {
+		return "", fmt.Errorf("no current conditions in weather data")
+	}
+	cur := w.CurrentCondition[0]
+	if theme != themeASCII {
+		theme = themeEmoji
+	}
+
+	var lines []string
+	if len(w.NearestArea) > 0 {
+		if area := w.NearestArea[0].AreaName.String(); area != "" {
+			lines = append(lines, theme.decorate(glyphPin, area))
+		}
+	}
+
+	var temps []string
+	if cur.TempC.Valid {
+		temps = append(temps, fmt.Sprintf("%g°C", cur.TempC.Value))
+	}
+	if cur.FeelsLikeC.Valid {
+		temps = append(temps, fmt.Sprintf("feels %g°C", cur.FeelsLikeC.Value))
+	}
+	if len(temps) > 0 {
+		lines = append(lines, theme.decorate(glyphThermometer, strings.Join(temps, " ")))
+	}
+
+	var air []string
+	if cur.WindSpeedKmph.Valid {
+		wind := fmt.Sprintf("%g km/h", cur.WindSpeedKmph.Value)
+		if cur.WindDir16Point != "" {
+			wind = cur.WindDir16Point + " " + wind
+		}
+		air = append(air, theme.decorate(glyphWind, wind))
+	}
+	if cur.Humidity.Valid {
+		air = append(air, theme.glyph(glyphDroplet)+fmt.Sprintf("%g%%", cur.Humidity.Value))
+	}
+	if len(air) > 0 {
+		lines = append(lines, strings.Join(air, " "))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// GetWeatherCard returns a compact text card of the current conditions.
+func (c *WeatherClient) GetWeatherCard(location string) (string, error) {
+	body, err := c.GetDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	return weatherCard(body, c.theme)
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestWeatherCard(t *testing.T) {
+	body := `{
+		"current_condition": [{"temp_C": "20", "FeelsLikeC": "19", "humidity": "45", "windspeedKmph": "5", "winddir16Point": "NW"}],
+		"nearest_area": [{"areaName": [{"value": "London"}]}]
+	}`
+
+	got, err := weatherCard(body, themeNone)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "📍 London\n🌡️ 20°C feels 19°C\n💨 NW 5 km/h 💧45%"
+	if got != want {
+		t.Errorf("unexpected card:\n got %q\nwant %q", got, want)
+	}
+
+	got, _ = weatherCard(body, themeASCII)
+	if want := "[at] London\n[temp] 20°C feels 19°C\n[wind] NW 5 km/h [humidity]45%"; got != want {
+		t.Errorf("unexpected ascii card:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestWeatherCardMissingFields(t *testing.T) {
+	body := `{"current_condition": [{"temp_C": "-3", "FeelsLikeC": "", "humidity": "80"}], "nearest_area": []}`
+
+	got, err := weatherCard(body, themeEmoji)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "🌡️ -3°C\n💧80%"; got != want {
+		t.Errorf("unexpected card:\n got %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Comfort thresholds. A humidex of 30 is where Environment Canada starts
+// to call conditions uncomfortable; humidity is blamed when it adds at
+// least humidDeltaC to the temperature.
+const (
+	hotHumidexC   = 30
+	humidDeltaC   = 5
+	chillyC       = 10
+	windyKmph     = 30
+	windChillMaxC = 10
+	windChillMinV = 4.8
+)
+
+// Comfort is a one-word verdict on how the current conditions feel.
+type Comfort struct {
+	Level       string  `json:"level"`
+	Explanation string  `json:"explanation"`
+	TempC       float64 `json:"temp_c"`
+	Humidity    float64 `json:"humidity"`
+	WindKmph    float64 `json:"wind_kmph"`
+	HumidexC    float64 `json:"humidex_c"`
+	WindChillC  float64 `json:"wind_chill_c"`
+}
+
+// humidex is the Canadian humidity index for a temperature in °C and a
+// relative humidity in percent, with the vapour pressure from the Magnus
+// formula.
+func humidex(tempC, humidity float64) float64 {
+	vapour := 6.112 * math.Pow(10, 7.5*tempC/(237.7+tempC)) * humidity / 100
+	return tempC + 0.5555*(vapour-10)
+}
+
+// windChill is the North American wind chill index. Outside the range it
+// is defined for, cold enough and windy enough, it is the temperature.
+func windChill(tempC, windKmph float64) float64 {
+	if tempC > windChillMaxC || windKmph <= windChillMinV {
+		return tempC
+	}
+	v := math.Pow(windKmph, 0.16)
+	return 13.12 + 0.6215*tempC - 11.37*v + 0.3965*tempC*v
+}
+
+// classifyComfort sorts conditions into one of five levels, checked in
+// order:
+//
+//   - hot or humid: the humidex is hotHumidexC or more; humid when
+//     humidity adds humidDeltaC or more to it, hot otherwise
+//   - chilly: the wind chill is below chillyC
+//   - windy: the wind is windyKmph or more
+//   - comfortable: none of the above
+//
+// The level is chosen from the unrounded humidex and wind chill, so it does
+// not depend on the configured precision; only the reported values are rounded.
+func (c *WeatherClient) classifyComfort(tempC, humidity, windKmph float64) Comfort {
+	hx := humidex(tempC, humidity)
+	wc := windChill(tempC, windKmph)
+	comfort := Comfort{
+		TempC:      tempC,
+		Humidity:   humidity,
+		WindKmph:   windKmph,
+		HumidexC:   c.round(hx),
+		WindChillC: c.round(wc),
+	}
+
+	switch {
+	case hx >= hotHumidexC && hx-tempC >= humidDeltaC:
+		comfort.Level = "humid"
+		comfort.Explanation = fmt.Sprintf("%g%% humidity makes %g°C feel like %g°C", humidity, tempC, comfort.HumidexC)
+	case hx >= hotHumidexC:
+		comfort.Level = "hot"
+		comfort.Explanation = fmt.Sprintf("%g°C is hot even in dry air", tempC)
+	case wc < chillyC:
+		comfort.Level = "chilly"
+		if wc < tempC {
+			comfort.Explanation = fmt.Sprintf("a %g km/h wind makes %g°C feel like %g°C", windKmph, tempC, comfort.WindChillC)
+		} else {
+			comfort.Explanation = fmt.Sprintf("%g°C is cool enough for a jacket", tempC)
+		}
+	case windKmph >= windyKmph:
+		comfort.Level = "windy"
+		comfort.Explanation = fmt.Sprintf("%g°C is mild but a %g km/h wind is blowing", tempC, windKmph)
+	default:
+		comfort.Level = "comfortable"
+		comfort.Explanation = fmt.Sprintf("%g°C with %g%% humidity and a %g km/h wind is pleasant", tempC, humidity, windKmph)
+	}
+	return comfort
+}
+
+// GetComfort classifies how comfortable the current conditions are and
+// returns the verdict with its explanation as JSON.
+func (c *WeatherClient) GetComfort(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	cur, err := w.current()
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(c.classifyComfort(float64(cur.TempC), float64(cur.Humidity), float64(cur.WindSpeedKmph)))
+}
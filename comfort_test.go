@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestClassifyComfort(t *testing.T) {
+	client := &WeatherClient{precision: 1}
+	tests := []struct {
+		name                     string
+		temp, humidity, windKmph float64
+		want                     string
+	}{
+		{"humid heat", 31, 75, 8, "humid"},
+		{"dry heat", 36, 15, 10, "hot"},
+		{"cold and windy", 4, 60, 35, "chilly"},
+		{"mild gale", 18, 55, 45, "windy"},
+		{"pleasant", 21, 50, 10, "comfortable"},
+	}
+	for _, tt := range tests {
+		c := client.classifyComfort(tt.temp, tt.humidity, tt.windKmph)
+		if c.Level != tt.want {
+			t.Errorf("%s: got %s (%+v), want %s", tt.name, c.Level, c, tt.want)
+		}
+		if c.Explanation == "" {
+			t.Errorf("%s: expected an explanation", tt.name)
+		}
+	}
+}
+
+func TestClassifyComfortIndices(t *testing.T) {
+	client := &WeatherClient{precision: 1}
+	humid := client.classifyComfort(31, 75, 8)
+	if humid.HumidexC < 43 || humid.HumidexC > 45 {
+		t.Errorf("expected a humidex around 44 for 31°C at 75%%, got %g", humid.HumidexC)
+	}
+
+	cold := client.classifyComfort(4, 60, 35)
+	if cold.WindChillC > -1 || cold.WindChillC < -2 {
+		t.Errorf("expected a wind chill around -1.7°C for 4°C at 35 km/h, got %g", cold.WindChillC)
+	}
+	if want := "a 35 km/h wind makes 4°C feel like -1.7°C"; cold.Explanation != want {
+		t.Errorf("unexpected explanation: %q", cold.Explanation)
+	}
+}
+
+func TestClassifyComfortPrecision(t *testing.T) {
+	cold := (&WeatherClient{precision: 0}).classifyComfort(4, 60, 35)
+	if cold.WindChillC != -2 {
+		t.Errorf("expected the wind chill rounded to -2°C, got %g", cold.WindChillC)
+	}
+	if want := "a 35 km/h wind makes 4°C feel like -2°C"; cold.Explanation != want {
+		t.Errorf("unexpected explanation: %q", cold.Explanation)
+	}
+}
+
+func TestClassifyComfortIgnoresPrecision(t *testing.T) {
+	// 25°C at 59% humidity has a humidex of about 29.8, which rounds to
+	// the 30°C threshold at precision 0 but must still count as comfortable.
+	for _, precision := range []int{0, 1} {
+		comfort := (&WeatherClient{precision: precision}).classifyComfort(25, 59, 5)
+		if comfort.Level != "comfortable" {
+			t.Errorf("precision %d: expected comfortable, got %s (humidex %g)", precision, comfort.Level, comfort.HumidexC)
+		}
+	}
+}
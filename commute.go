@@ -0,0 +1,94 @@
+package main
+
+import "fmt"
+
+const (
+	defaultMorningHour = 8
+	defaultEveningHour = 18
+)
+
+// CommuteSlot is the forecast for one leg of a commute.
+type CommuteSlot struct {
+	RequestedHour int      `json:"requested_hour"`
+	Time          string   `json:"time"`
+	TempC         float64  `json:"temp_c"`
+	FeelsLikeC    float64  `json:"feels_like_c"`
+	TempF         *float64 `json:"temp_f,omitempty"`
+	FeelsLikeF    *float64 `json:"feels_like_f,omitempty"`
+	Description   string   `json:"description"`
+	ChanceOfRain  int      `json:"chance_of_rain"`
+	WindKmph      float64  `json:"wind_kmph"`
+	WindDir       string   `json:"wind_dir"`
+}
+
+// CommuteReport is today's forecast for the morning and evening commute.
+type CommuteReport struct {
+	Date    string      `json:"date"`
+	Morning CommuteSlot `json:"morning"`
+	Evening CommuteSlot `json:"evening"`
+}
+
+// snapToSlot returns the time of the 3-hourly j1 slot nearest to hour,
+// from 0 to 2100. Hours past 22:30 snap back to the last slot of the day.
+func snapToSlot(hour int) int {
+	slot := (hour + 1) / 3 * 3
+	if slot > 21 {
+		slot = 21
+	}
+	return slot * 100
+}
+
+// commuteSlot picks the slot nearest to hour from the day's forecast.
+func (c *WeatherClient) commuteSlot(d DayForecast, hour int) (CommuteSlot, error) {
+	want := snapToSlot(hour)
+	for _, h := range d.Hourly {
+		if int(h.Time) == want {
+			return CommuteSlot{
+				RequestedHour: hour,
+				Time:          fmt.Sprintf("%02d:00", want/100),
+				TempC:         float64(h.TempC),
+				FeelsLikeC:    float64(h.FeelsLikeC),
+				TempF:         c.fahrenheit(h.TempF),
+				FeelsLikeF:    c.fahrenheit(h.FeelsLikeF),
+				Description:   h.WeatherDesc.String(),
+				ChanceOfRain:  int(h.ChanceOfRain),
+				WindKmph:      float64(h.WindSpeedKmph),
+				WindDir:       h.WindDir16Point,
+			}, nil
+		}
+	}
+	return CommuteSlot{}, fmt.Errorf("no %02d:00 slot in the forecast for %s", want/100, d.Date)
+}
+
+// commute builds today's commute report.
+func (c *WeatherClient) commute(w DetailedWeather, morningHour, eveningHour int) (CommuteReport, error) {
+	if len(w.Weather) == 0 {
+		return CommuteReport{}, fmt.Errorf("no forecast days in weather data")
+	}
+	today := w.Weather[0]
+
+	morning, err := c.commuteSlot(today, morningHour)
+	if err != nil {
+		return CommuteReport{}, err
+	}
+	evening, err := c.commuteSlot(today, eveningHour)
+	if err != nil {
+		return CommuteReport{}, err
+	}
+	return CommuteReport{Date: today.Date, Morning: morning, Evening: evening}, nil
+}
+
+// GetCommute returns today's forecast at the morning and evening commute
+// hours, snapped to the 3-hourly slots, as JSON.
+func (c *WeatherClient) GetCommute(location string, morningHour, eveningHour int) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	report, err := c.commute(w, morningHour, eveningHour)
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(report)
+}
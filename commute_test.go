@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestSnapToSlot(t *testing.T) {
+	tests := map[int]int{
+		0:  0,
+		1:  0,
+		2:  300,
+		7:  600,
+		8:  900,
+		9:  900,
+		13: 1200,
+		17: 1800,
+		18: 1800,
+		22: 2100,
+		23: 2100,
+	}
+
+	for hour, want := range tests {
+		if got := snapToSlot(hour); got != want {
+			t.Errorf("snapToSlot(%d) = %d, want %d", hour, got, want)
+		}
+	}
+}
+
+func TestCommute(t *testing.T) {
+	report, err := new(WeatherClient).commute(segmentsFixture(), 8, 18)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Date != "2024-06-12" {
+		t.Errorf("unexpected date: %s", report.Date)
+	}
+	if m := report.Morning; m.RequestedHour != 8 || m.Time != "09:00" || m.TempC != 13 || m.ChanceOfRain != 30 {
+		t.Errorf("unexpected morning slot: %+v", m)
+	}
+	if e := report.Evening; e.Time != "18:00" || e.Description != "Overcast" {
+		t.Errorf("unexpected evening slot: %+v", e)
+	}
+}
+
+func TestCommuteMissingSlot(t *testing.T) {
+	// The fixture only has 09:00 and 12:00 slots.
+	if _, err := new(WeatherClient).commute(forecastFixture("2024-06-12 02:35 PM", 1), 8, 18); err == nil {
+		t.Fatal("expected error for a missing evening slot")
+	}
+}
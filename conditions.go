@@ -0,0 +1,78 @@
+package main
+
+// Condition icons, as wttr.in draws them in its one-liners.
+const (
+	iconUnknown      = "✨"
+	iconSunny        = "☀️"
+	iconPartlyCloudy = "⛅️"
+	iconCloudy       = "☁️"
+	iconFog          = "🌫"
+	iconLightRain    = "🌦"
+	iconHeavyRain    = "🌧"
+	iconSleet        = "🌧"
+	iconLightSnow    = "🌨"
+	iconHeavySnow    = "❄️"
+	iconThunderRain  = "🌩"
+	iconThunder      = "⛈"
+)
+
+// weatherCodeIcons maps WorldWeatherOnline condition codes, the j1
+// weatherCode field, to icons, following wttr.in's own table.
+var weatherCodeIcons = map[int]string{
+	113: iconSunny,
+	116: iconPartlyCloudy,
+	119: iconCloudy,
+	122: iconCloudy,
+	143: iconFog,
+	176: iconLightRain,
+	179: iconSleet,
+	182: iconSleet,
+	185: iconSleet,
+	200: iconThunder,
+	227: iconLightSnow,
+	230: iconHeavySnow,
+	248: iconFog,
+	260: iconFog,
+	263: iconLightRain,
+	266: iconLightRain,
+	281: iconSleet,
+	284: iconSleet,
+	293: iconLightRain,
+	296: iconLightRain,
+	299: iconHeavyRain,
+	302: iconHeavyRain,
+	305: iconHeavyRain,
+	308: iconHeavyRain,
+	311: iconSleet,
+	314: iconSleet,
+	317: iconSleet,
+	320: iconLightSnow,
+	323: iconLightSnow,
+	326: iconLightSnow,
+	329: iconHeavySnow,
+	332: iconHeavySnow,
+	335: iconHeavySnow,
+	338: iconHeavySnow,
+	350: iconSleet,
+	353: iconLightRain,
+	356: iconHeavyRain,
+	359: iconHeavyRain,
+	362: iconSleet,
+	365: iconSleet,
+	368: iconLightSnow,
+	371: iconHeavySnow,
+	374: iconSleet,
+	377: iconSleet,
+	386: iconThunder,
+	389: iconThunderRain,
+	392: iconThunder,
+	395: iconHeavySnow,
+}
+
+// conditionIcon returns the icon for a weather code, or iconUnknown.
+func conditionIcon(code number) string {
+	if icon, ok := weatherCodeIcons[int(code)]; ok {
+		return icon
+	}
+	return iconUnknown
+}
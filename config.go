@@ -0,0 +1,392 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultPrecision = 1
+
+// maxForecastDays is the longest forecast wttr.in gives, and the number of
+// days get_forecast covers by default.
+const maxForecastDays = 3
+
+// defaultMaxMessageBytes bounds a single JSON-RPC message read from stdin.
+const defaultMaxMessageBytes = 1 << 20
+
+// Config holds the server settings read from the environment at startup.
+type Config struct {
+	// Precision is the number of decimal places computed values are rounded to.
+	Precision int
+
+	// WarmupLocation, when set, is fetched in the background after initialize
+	// to prime DNS, TLS and connection reuse before the first tool call.
+	WarmupLocation string
+
+	// ResourceThreshold is the result size in bytes from which large results
+	// are returned as embedded resources instead of text. Zero disables it.
+	ResourceThreshold int
+
+	// CacheTTL is how long upstream responses are reused, varied by ±10% per
+	// entry. Zero disables caching.
+	CacheTTL time.Duration
+
+	// FetchTimeout bounds a whole upstream request, DialTimeout connecting
+	// to wttr.in and HeaderTimeout waiting for its response headers once
+	// the request is sent. Zero means the default.
+	FetchTimeout  time.Duration
+	DialTimeout   time.Duration
+	HeaderTimeout time.Duration
+
+	// MaxMessageBytes is the largest JSON-RPC message read from stdin.
+	// Zero means defaultMaxMessageBytes.
+	MaxMessageBytes int64
+
+	// RequestLogPath, when set, is a JSONL file every request is logged to.
+	// It is rotated once it reaches RequestLogMaxBytes.
+	RequestLogPath     string
+	RequestLogMaxBytes int64
+
+	// ArchiveURL, when set, is an Open-Meteo compatible archive endpoint used
+	// by get_on_this_day to look up past temperatures.
+	ArchiveURL string
+
+	// Theme selects the glyphs in prose output. The zero value means none.
+	Theme Theme
+
+	// ForecastFormat is the get_forecast rendering used when a call doesn't
+	// ask for one. The zero value means ascii.
+	ForecastFormat string
+
+	// ForecastDays is the number of days get_forecast covers when a call
+	// doesn't pass days, 0-3. Nil means 3.
+	ForecastDays *int
+
+	// LocalRender builds the get_current_weather one-liner and the ascii
+	// get_forecast from the j1 data instead of requesting wttr.in's own
+	// renderings, so with caching one j1 request serves both.
+	LocalRender bool
+
+	// AutoLangs lists the languages text forecasts may be given in when
+	// detected from the country a location resolves to. Empty disables
+	// detection, and forecasts are in Russian.
+	AutoLangs []string
+
+	// ErrorStyle selects how failed tool calls are reported: "content" for
+	// isError results, "rpc" for JSON-RPC errors. The zero value means
+	// content.
+	ErrorStyle string
+
+	// Debug exposes the debugging tools, such as get_debug_headers.
+	Debug bool
+
+	// SchemaVersion adds a schema_version field to JSON object results.
+	SchemaVersion bool
+
+	// SchemaRef adds a $schema identifier naming the tool and result
+	// version to JSON object results.
+	SchemaRef bool
+
+	// StrictSchema validates tool arguments against the advertised input
+	// schemas before dispatching, rejecting for example numeric strings.
+	StrictSchema bool
+
+	// Footer, when set, is an attribution or disclaimer added to every
+	// successful tool result.
+	Footer string
+
+	// MaintenancePhrases replaces the notices recognized as wttr.in being
+	// overloaded or under maintenance. Nil means the built-in list.
+	MaintenancePhrases []string
+
+	// EchoMeta lists the request _meta fields tools/call copies into the
+	// result's _meta, so clients can correlate asynchronous calls. Nil
+	// means correlation_id.
+	EchoMeta []string
+
+	// ToolDescriptions overrides the built-in description of the tools it names.
+	ToolDescriptions map[string]string
+
+	// Profiles maps profile names ("work", "parents") to locations.
+	Profiles map[string]string
+}
+
+func loadConfig() (Config, error) {
+	cfg := Config{
+		Precision:          defaultPrecision,
+		WarmupLocation:     os.Getenv("WTTR_WARMUP_LOCATION"),
+		RequestLogPath:     os.Getenv("WTTR_REQUEST_LOG"),
+		ArchiveURL:         os.Getenv("WTTR_ARCHIVE_URL"),
+		RequestLogMaxBytes: defaultRequestLogMaxBytes,
+	}
+
+	if v := os.Getenv("WTTR_PRECISION"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 0 || p > 6 {
+			return cfg, fmt.Errorf("WTTR_PRECISION must be an integer between 0 and 6, got %q", v)
+		}
+		cfg.Precision = p
+	}
+
+	if v := os.Getenv("WTTR_CACHE_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl < 0 {
+			return cfg, fmt.Errorf("WTTR_CACHE_TTL must be a non-negative duration such as 10m, got %q", v)
+		}
+		cfg.CacheTTL = ttl
+	}
+
+	for name, timeout := range map[string]*time.Duration{
+		"WTTR_FETCH_TIMEOUT":  &cfg.FetchTimeout,
+		"WTTR_DIAL_TIMEOUT":   &cfg.DialTimeout,
+		"WTTR_HEADER_TIMEOUT": &cfg.HeaderTimeout,
+	} {
+		if v := os.Getenv(name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return cfg, fmt.Errorf("%s must be a positive duration such as 10s, got %q", name, v)
+			}
+			*timeout = d
+		}
+	}
+
+	if v := os.Getenv("WTTR_RESOURCE_THRESHOLD"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("WTTR_RESOURCE_THRESHOLD must be a non-negative number of bytes, got %q", v)
+		}
+		cfg.ResourceThreshold = n
+	}
+
+	switch v := Theme(os.Getenv("WTTR_THEME")); v {
+	case "", themeNone:
+	case themeEmoji, themeASCII:
+		cfg.Theme = v
+	default:
+		return cfg, fmt.Errorf("WTTR_THEME must be one of emoji, ascii or none, got %q", v)
+	}
+
+	switch v := os.Getenv("WTTR_FORECAST_FORMAT"); v {
+	case "":
+	case forecastFormatASCII, forecastFormatJSON, forecastFormatMarkdown:
+		cfg.ForecastFormat = v
+	default:
+		return cfg, fmt.Errorf("WTTR_FORECAST_FORMAT must be one of ascii, json or markdown, got %q", v)
+	}
+
+	if v := os.Getenv("WTTR_DEFAULT_FORECAST_DAYS"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("WTTR_DEFAULT_FORECAST_DAYS must be an integer, got %q", v)
+		}
+		days = min(max(days, 0), maxForecastDays)
+		cfg.ForecastDays = &days
+	}
+
+	switch v := os.Getenv("WTTR_ERROR_STYLE"); v {
+	case "", errorStyleContent:
+	case errorStyleRPC:
+		cfg.ErrorStyle = v
+	default:
+		return cfg, fmt.Errorf("WTTR_ERROR_STYLE must be rpc or content, got %q", v)
+	}
+
+	if v := os.Getenv("WTTR_AUTO_LANGS"); v != "" {
+		for _, lang := range strings.Split(v, ",") {
+			if lang = strings.TrimSpace(lang); lang != "" {
+				cfg.AutoLangs = append(cfg.AutoLangs, lang)
+			}
+		}
+	}
+
+	if v := os.Getenv("WTTR_ECHO_META"); v != "" {
+		cfg.EchoMeta = []string{}
+		for _, key := range strings.Split(v, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				cfg.EchoMeta = append(cfg.EchoMeta, key)
+			}
+		}
+	}
+
+	if v := os.Getenv("WTTR_DEBUG"); v != "" {
+		debug, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("WTTR_DEBUG must be a boolean, got %q", v)
+		}
+		cfg.Debug = debug
+	}
+
+	if v := os.Getenv("WTTR_SCHEMA_VERSION"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("WTTR_SCHEMA_VERSION must be a boolean, got %q", v)
+		}
+		cfg.SchemaVersion = enabled
+	}
+
+	if v := os.Getenv("WTTR_SCHEMA_REF"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("WTTR_SCHEMA_REF must be a boolean, got %q", v)
+		}
+		cfg.SchemaRef = enabled
+	}
+
+	if v := os.Getenv("WTTR_LOCAL_RENDER"); v != "" {
+		local, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("WTTR_LOCAL_RENDER must be a boolean, got %q", v)
+		}
+		cfg.LocalRender = local
+	}
+
+	if v := os.Getenv("WTTR_STRICT_SCHEMA"); v != "" {
+		strict, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("WTTR_STRICT_SCHEMA must be a boolean, got %q", v)
+		}
+		cfg.StrictSchema = strict
+	}
+
+	if v := os.Getenv("WTTR_MAX_MESSAGE_BYTES"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return cfg, fmt.Errorf("WTTR_MAX_MESSAGE_BYTES must be a positive number of bytes, got %q", v)
+		}
+		cfg.MaxMessageBytes = n
+	}
+
+	if v := os.Getenv("WTTR_REQUEST_LOG_MAX_BYTES"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return cfg, fmt.Errorf("WTTR_REQUEST_LOG_MAX_BYTES must be a positive number of bytes, got %q", v)
+		}
+		cfg.RequestLogMaxBytes = n
+	}
+
+	if v := os.Getenv("WTTR_MAINTENANCE_PHRASES"); v != "" {
+		if err := json.Unmarshal([]byte(v), &cfg.MaintenancePhrases); err != nil {
+			return cfg, fmt.Errorf("WTTR_MAINTENANCE_PHRASES must be a JSON array of strings: %w", err)
+		}
+	}
+
+	footer, err := loadFooter()
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Footer = footer
+
+	profiles, err := loadProfiles()
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Profiles = profiles
+
+	descriptions, err := loadToolDescriptions()
+	if err != nil {
+		return cfg, err
+	}
+	cfg.ToolDescriptions = descriptions
+
+	return cfg, nil
+}
+
+// loadFooter reads the result footer from WTTR_FOOTER, or from the file
+// named by WTTR_FOOTER_FILE when WTTR_FOOTER is not set.
+func loadFooter() (string, error) {
+	if v := os.Getenv("WTTR_FOOTER"); v != "" {
+		return strings.TrimSpace(v), nil
+	}
+	path := os.Getenv("WTTR_FOOTER_FILE")
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading WTTR_FOOTER_FILE: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// loadProfiles reads the profile map from the JSON file named by
+// WTTR_PROFILES_FILE and the JSON object in WTTR_PROFILES, which takes
+// precedence for profiles defined in both.
+func loadProfiles() (map[string]string, error) {
+	profiles, err := loadStringMap("WTTR_PROFILES", "profile names to locations")
+	if err != nil {
+		return nil, err
+	}
+
+	for name, location := range profiles {
+		if name == "" || location == "" {
+			return nil, fmt.Errorf("profile %q: names and locations must not be empty", name)
+		}
+	}
+
+	return profiles, nil
+}
+
+// loadToolDescriptions reads the tool description overrides from
+// WTTR_TOOL_DESCRIPTIONS_FILE and WTTR_TOOL_DESCRIPTIONS, like loadProfiles.
+func loadToolDescriptions() (map[string]string, error) {
+	descriptions, err := loadStringMap("WTTR_TOOL_DESCRIPTIONS", "tool names to descriptions")
+	if err != nil {
+		return nil, err
+	}
+
+	for name, description := range descriptions {
+		if description == "" {
+			return nil, fmt.Errorf("tool description %q must not be empty", name)
+		}
+	}
+
+	return descriptions, nil
+}
+
+// loadStringMap merges the JSON object in the file named by <env>_FILE with
+// the JSON object in env itself, which wins for keys defined in both.
+func loadStringMap(env, shape string) (map[string]string, error) {
+	values := map[string]string{}
+
+	if path := os.Getenv(env + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s_FILE: %w", env, err)
+		}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("parsing %s_FILE: %w", env, err)
+		}
+	}
+
+	if v := os.Getenv(env); v != "" {
+		var fromEnv map[string]string
+		if err := json.Unmarshal([]byte(v), &fromEnv); err != nil {
+			return nil, fmt.Errorf("%s must be a JSON object of %s: %w", env, shape, err)
+		}
+		for key, value := range fromEnv {
+			values[key] = value
+		}
+	}
+
+	return values, nil
+}
+
+// maxMessageBytes is the largest JSON-RPC message serve reads.
+func (cfg Config) maxMessageBytes() int64 {
+	if cfg.MaxMessageBytes > 0 {
+		return cfg.MaxMessageBytes
+	}
+	return defaultMaxMessageBytes
+}
+
+// forecastDays is the number of days get_forecast covers by default.
+func (cfg Config) forecastDays() int {
+	if cfg.ForecastDays == nil {
+		return maxForecastDays
+	}
+	return *cfg.ForecastDays
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigDefaults(t *testing.T) {
+	t.Setenv("WTTR_PRECISION", "")
+	t.Setenv("WTTR_WARMUP_LOCATION", "")
+	t.Setenv("WTTR_RESOURCE_THRESHOLD", "")
+	t.Setenv("WTTR_PROFILES", "")
+	t.Setenv("WTTR_PROFILES_FILE", "")
+	t.Setenv("WTTR_REQUEST_LOG", "")
+	t.Setenv("WTTR_REQUEST_LOG_MAX_BYTES", "")
+	t.Setenv("WTTR_ARCHIVE_URL", "")
+	t.Setenv("WTTR_THEME", "")
+	t.Setenv("WTTR_DEBUG", "")
+	t.Setenv("WTTR_AUTO_LANGS", "")
+	t.Setenv("WTTR_FOOTER", "")
+	t.Setenv("WTTR_ERROR_STYLE", "")
+	t.Setenv("WTTR_MAINTENANCE_PHRASES", "")
+	t.Setenv("WTTR_FORECAST_FORMAT", "")
+	t.Setenv("WTTR_FOOTER_FILE", "")
+	t.Setenv("WTTR_STRICT_SCHEMA", "")
+	t.Setenv("WTTR_CACHE_TTL", "")
+	t.Setenv("WTTR_FETCH_TIMEOUT", "")
+	t.Setenv("WTTR_DIAL_TIMEOUT", "")
+	t.Setenv("WTTR_HEADER_TIMEOUT", "")
+	t.Setenv("WTTR_SCHEMA_VERSION", "")
+	t.Setenv("WTTR_SCHEMA_REF", "")
+	t.Setenv("WTTR_TOOL_DESCRIPTIONS", "")
+	t.Setenv("WTTR_TOOL_DESCRIPTIONS_FILE", "")
+	t.Setenv("WTTR_DEFAULT_FORECAST_DAYS", "")
+	t.Setenv("WTTR_ECHO_META", "")
+	t.Setenv("WTTR_LOCAL_RENDER", "")
+	t.Setenv("WTTR_MAX_MESSAGE_BYTES", "")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Precision != 1 {
+		t.Errorf("expected default precision 1, got %d", cfg.Precision)
+	}
+	if cfg.WarmupLocation != "" {
+		t.Errorf("expected warm-up disabled by default, got %q", cfg.WarmupLocation)
+	}
+	if cfg.ResourceThreshold != 0 {
+		t.Errorf("expected resource results disabled by default, got %d", cfg.ResourceThreshold)
+	}
+	if cfg.RequestLogPath != "" || cfg.ArchiveURL != "" {
+		t.Errorf("expected request log and archive disabled by default, got %+v", cfg)
+	}
+	if cfg.maxMessageBytes() != defaultMaxMessageBytes {
+		t.Errorf("expected the default message limit, got %d", cfg.maxMessageBytes())
+	}
+	if cfg.CacheTTL != 0 {
+		t.Errorf("expected caching disabled by default, got %v", cfg.CacheTTL)
+	}
+	if cfg.Debug || cfg.StrictSchema || cfg.SchemaVersion || cfg.SchemaRef {
+		t.Error("expected debug tools, strict schema, schema version and schema ref disabled by default")
+	}
+	if cfg.LocalRender {
+		t.Error("expected wttr.in's own renderings by default")
+	}
+	if cfg.ForecastFormat != "" {
+		t.Errorf("expected the ascii forecast by default, got %q", cfg.ForecastFormat)
+	}
+	if cfg.MaintenancePhrases != nil {
+		t.Errorf("expected the built-in maintenance phrases by default, got %v", cfg.MaintenancePhrases)
+	}
+	if cfg.ErrorStyle != "" {
+		t.Errorf("expected isError results by default, got %q", cfg.ErrorStyle)
+	}
+	if cfg.Footer != "" {
+		t.Errorf("expected no footer by default, got %q", cfg.Footer)
+	}
+	if len(cfg.AutoLangs) != 0 {
+		t.Errorf("expected language detection disabled by default, got %v", cfg.AutoLangs)
+	}
+	if cfg.EchoMeta != nil {
+		t.Errorf("expected the default echoed _meta fields, got %v", cfg.EchoMeta)
+	}
+	if cfg.Theme != "" {
+		t.Errorf("expected no theme by default, got %q", cfg.Theme)
+	}
+	if len(cfg.Profiles) != 0 {
+		t.Errorf("expected no profiles by default, got %v", cfg.Profiles)
+	}
+	if len(cfg.ToolDescriptions) != 0 {
+		t.Errorf("expected no description overrides by default, got %v", cfg.ToolDescriptions)
+	}
+}
+
+func TestLoadConfigPrecision(t *testing.T) {
+	t.Setenv("WTTR_PRECISION", "0")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Precision != 0 {
+		t.Errorf("expected precision 0, got %d", cfg.Precision)
+	}
+}
+
+func TestLoadConfigInvalidPrecision(t *testing.T) {
+	for _, v := range []string{"abc", "-1", "7"} {
+		t.Setenv("WTTR_PRECISION", v)
+		if _, err := loadConfig(); err == nil {
+			t.Errorf("WTTR_PRECISION=%s: expected error", v)
+		}
+	}
+}
+
+func TestLoadConfigWarmupLocation(t *testing.T) {
+	t.Setenv("WTTR_WARMUP_LOCATION", "Berlin")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.WarmupLocation != "Berlin" {
+		t.Errorf("expected warm-up location Berlin, got %q", cfg.WarmupLocation)
+	}
+}
+
+func TestLoadConfigResourceThreshold(t *testing.T) {
+	t.Setenv("WTTR_RESOURCE_THRESHOLD", "4096")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ResourceThreshold != 4096 {
+		t.Errorf("expected threshold 4096, got %d", cfg.ResourceThreshold)
+	}
+
+	t.Setenv("WTTR_RESOURCE_THRESHOLD", "big")
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected error for non-numeric threshold")
+	}
+}
+
+func TestLoadConfigProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, []byte(`{"work": "Berlin", "home": "Madrid"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WTTR_PROFILES_FILE", path)
+	t.Setenv("WTTR_PROFILES", `{"home": "Lisbon", "parents": "Lyon"}`)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{"work": "Berlin", "home": "Lisbon", "parents": "Lyon"}
+	if len(cfg.Profiles) != len(want) {
+		t.Fatalf("expected %v, got %v", want, cfg.Profiles)
+	}
+	for name, location := range want {
+		if cfg.Profiles[name] != location {
+			t.Errorf("profile %s: expected %s, got %s", name, location, cfg.Profiles[name])
+		}
+	}
+}
+
+func TestLoadConfigInvalidProfiles(t *testing.T) {
+	for _, v := range []string{`work=Berlin`, `{"work": ""}`, `{"": "Berlin"}`} {
+		t.Setenv("WTTR_PROFILES", v)
+		if _, err := loadConfig(); err == nil {
+			t.Errorf("WTTR_PROFILES=%s: expected error", v)
+		}
+	}
+}
+
+func TestLoadConfigToolDescriptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "descriptions.json")
+	if err := os.WriteFile(path, []byte(`{"get_forecast": "From file", "get_current_weather": "From file"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WTTR_TOOL_DESCRIPTIONS_FILE", path)
+	t.Setenv("WTTR_TOOL_DESCRIPTIONS", `{"get_current_weather": "Use for quick checks"}`)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ToolDescriptions["get_forecast"] != "From file" || cfg.ToolDescriptions["get_current_weather"] != "Use for quick checks" {
+		t.Errorf("unexpected descriptions: %v", cfg.ToolDescriptions)
+	}
+
+	t.Setenv("WTTR_TOOL_DESCRIPTIONS", `{"get_forecast": ""}`)
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected error for empty description")
+	}
+}
+
+func TestLoadConfigRequestLog(t *testing.T) {
+	t.Setenv("WTTR_REQUEST_LOG", "/var/log/wttr.jsonl")
+	t.Setenv("WTTR_REQUEST_LOG_MAX_BYTES", "")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RequestLogPath != "/var/log/wttr.jsonl" {
+		t.Errorf("unexpected path: %q", cfg.RequestLogPath)
+	}
+	if cfg.RequestLogMaxBytes != 10<<20 {
+		t.Errorf("expected default max size 10 MiB, got %d", cfg.RequestLogMaxBytes)
+	}
+
+	t.Setenv("WTTR_REQUEST_LOG_MAX_BYTES", "1024")
+	if cfg, _ := loadConfig(); cfg.RequestLogMaxBytes != 1024 {
+		t.Errorf("expected max size 1024, got %d", cfg.RequestLogMaxBytes)
+	}
+
+	t.Setenv("WTTR_REQUEST_LOG_MAX_BYTES", "0")
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected error for zero max size")
+	}
+}
+
+func TestLoadConfigMaxMessageBytes(t *testing.T) {
+	t.Setenv("WTTR_MAX_MESSAGE_BYTES", "4096")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.maxMessageBytes() != 4096 {
+		t.Errorf("expected 4096, got %d", cfg.maxMessageBytes())
+	}
+
+	for _, v := range []string{"0", "-1", "1MB"} {
+		t.Setenv("WTTR_MAX_MESSAGE_BYTES", v)
+		if _, err := loadConfig(); err == nil {
+			t.Errorf("WTTR_MAX_MESSAGE_BYTES=%s: expected error", v)
+		}
+	}
+}
+
+func TestLoadConfigCacheTTL(t *testing.T) {
+	t.Setenv("WTTR_CACHE_TTL", "10m")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.CacheTTL != 10*time.Minute {
+		t.Errorf("expected 10m, got %v", cfg.CacheTTL)
+	}
+
+	for _, v := range []string{"600", "-1m"} {
+		t.Setenv("WTTR_CACHE_TTL", v)
+		if _, err := loadConfig(); err == nil {
+			t.Errorf("WTTR_CACHE_TTL=%s: expected error", v)
+		}
+	}
+}
+
+func TestLoadConfigTimeouts(t *testing.T) {
+	t.Setenv("WTTR_FETCH_TIMEOUT", "45s")
+	t.Setenv("WTTR_DIAL_TIMEOUT", "3s")
+	t.Setenv("WTTR_HEADER_TIMEOUT", "15s")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.FetchTimeout != 45*time.Second || cfg.DialTimeout != 3*time.Second || cfg.HeaderTimeout != 15*time.Second {
+		t.Errorf("unexpected timeouts: %v, %v, %v", cfg.FetchTimeout, cfg.DialTimeout, cfg.HeaderTimeout)
+	}
+
+	for _, v := range []string{"10", "0s", "-1s"} {
+		t.Setenv("WTTR_DIAL_TIMEOUT", v)
+		if _, err := loadConfig(); err == nil {
+			t.Errorf("WTTR_DIAL_TIMEOUT=%s: expected error", v)
+		}
+	}
+}
+
+func TestLoadConfigTheme(t *testing.T) {
+	t.Setenv("WTTR_THEME", "ascii")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Theme != themeASCII {
+		t.Errorf("expected ascii theme, got %q", cfg.Theme)
+	}
+
+	t.Setenv("WTTR_THEME", "fancy")
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected error for unknown theme")
+	}
+}
+
+func TestLoadConfigSchemaRef(t *testing.T) {
+	t.Setenv("WTTR_SCHEMA_REF", "true")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.SchemaRef {
+		t.Error("expected the $schema reference to be enabled")
+	}
+
+	t.Setenv("WTTR_SCHEMA_REF", "sometimes")
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected an error for a non-boolean value")
+	}
+}
+
+func TestLoadConfigLocalRender(t *testing.T) {
+	t.Setenv("WTTR_LOCAL_RENDER", "1")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.LocalRender {
+		t.Error("expected local rendering to be enabled")
+	}
+
+	t.Setenv("WTTR_LOCAL_RENDER", "yes")
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected an error for a non-boolean value")
+	}
+}
+
+func TestLoadConfigAutoLangs(t *testing.T) {
+	t.Setenv("WTTR_AUTO_LANGS", "fr, ja,,de")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(cfg.AutoLangs, ",") != "fr,ja,de" {
+		t.Errorf("unexpected languages: %v", cfg.AutoLangs)
+	}
+}
+
+func TestLoadConfigEchoMeta(t *testing.T) {
+	t.Setenv("WTTR_ECHO_META", "requestId, traceparent,")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(cfg.EchoMeta, ",") != "requestId,traceparent" {
+		t.Errorf("unexpected fields: %v", cfg.EchoMeta)
+	}
+}
+
+func TestLoadConfigFooter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "footer.txt")
+	if err := os.WriteFile(path, []byte("Data from wttr.in; not for safety-critical use\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WTTR_FOOTER", "")
+	t.Setenv("WTTR_FOOTER_FILE", path)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Footer != "Data from wttr.in; not for safety-critical use" {
+		t.Errorf("unexpected footer from file: %q", cfg.Footer)
+	}
+
+	t.Setenv("WTTR_FOOTER", "Powered by wttr.in")
+	cfg, err = loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Footer != "Powered by wttr.in" {
+		t.Errorf("expected WTTR_FOOTER to win, got %q", cfg.Footer)
+	}
+}
+
+func TestLoadConfigForecastDays(t *testing.T) {
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ForecastDays != nil || cfg.forecastDays() != 3 {
+		t.Errorf("expected 3 days by default, got %d", cfg.forecastDays())
+	}
+
+	for v, want := range map[string]int{"1": 1, "0": 0, "7": 3, "-2": 0} {
+		t.Setenv("WTTR_DEFAULT_FORECAST_DAYS", v)
+		cfg, err := loadConfig()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", v, err)
+		}
+		if cfg.forecastDays() != want {
+			t.Errorf("%s: expected %d days, got %d", v, want, cfg.forecastDays())
+		}
+	}
+
+	t.Setenv("WTTR_DEFAULT_FORECAST_DAYS", "two")
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected error for a non-integer day count")
+	}
+}
+
+func TestLoadConfigForecastFormat(t *testing.T) {
+	t.Setenv("WTTR_FORECAST_FORMAT", "markdown")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ForecastFormat != "markdown" {
+		t.Errorf("expected markdown, got %q", cfg.ForecastFormat)
+	}
+
+	t.Setenv("WTTR_FORECAST_FORMAT", "csv")
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestLoadConfigMaintenancePhrases(t *testing.T) {
+	t.Setenv("WTTR_MAINTENANCE_PHRASES", `["back soon", "over capacity"]`)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(cfg.MaintenancePhrases, "|") != "back soon|over capacity" {
+		t.Errorf("unexpected phrases: %v", cfg.MaintenancePhrases)
+	}
+
+	t.Setenv("WTTR_MAINTENANCE_PHRASES", "back soon")
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected error for a non-JSON list")
+	}
+}
+
+func TestLoadConfigErrorStyle(t *testing.T) {
+	t.Setenv("WTTR_ERROR_STYLE", "rpc")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ErrorStyle != "rpc" {
+		t.Errorf("expected rpc, got %q", cfg.ErrorStyle)
+	}
+
+	t.Setenv("WTTR_ERROR_STYLE", "exceptions")
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected error for unknown style")
+	}
+}
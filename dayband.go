@@ -0,0 +1,56 @@
+package main
+
+// largeSwingC is the spread between the day's lowest and highest hourly
+// temperature from which a single outfit won't do.
+const largeSwingC = 12
+
+// DayBand is the range of temperatures over today.
+type DayBand struct {
+	Date     string   `json:"date"`
+	MinTempC float64  `json:"min_temp_c"`
+	MaxTempC float64  `json:"max_temp_c"`
+	MinTempF *float64 `json:"min_temp_f,omitempty"`
+	MaxTempF *float64 `json:"max_temp_f,omitempty"`
+	SpreadC  float64  `json:"spread_c"`
+	Note     string   `json:"note,omitempty"`
+	// Hourly is today's parsed hourly slots, when raw hourly data was
+	// requested.
+	Hourly []HourlyWeather `json:"hourly,omitempty"`
+}
+
+// dayBand derives today's temperature band from the hourly forecast.
+func (c *WeatherClient) dayBand(w DetailedWeather) (DayBand, error) {
+	extremes, err := c.tempExtremes(w, 0)
+	if err != nil {
+		return DayBand{}, err
+	}
+
+	band := DayBand{
+		Date:     extremes.Date,
+		MinTempC: extremes.Coldest.TempC,
+		MaxTempC: extremes.Warmest.TempC,
+		MinTempF: extremes.Coldest.TempF,
+		MaxTempF: extremes.Warmest.TempF,
+		SpreadC:  extremes.Warmest.TempC - extremes.Coldest.TempC,
+	}
+	if band.SpreadC > largeSwingC {
+		band.Note = "large swing: dress in layers"
+	}
+	band.Hourly = c.rawHourly(w.Weather[0])
+	return band, nil
+}
+
+// GetDayBand returns today's minimum and maximum temperature and their
+// spread as JSON.
+func (c *WeatherClient) GetDayBand(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	band, err := c.dayBand(w)
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(band)
+}
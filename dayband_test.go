@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func bandFixture(temps ...int) DetailedWeather {
+	hourly := make([]HourlyWeather, len(temps))
+	for i, temp := range temps {
+		hourly[i] = HourlyWeather{Time: number(i * 300), TempC: number(temp)}
+	}
+	return DetailedWeather{Weather: []DayForecast{{Date: "2024-06-12", Hourly: hourly}}}
+}
+
+func TestDayBandDesert(t *testing.T) {
+	band, err := new(WeatherClient).dayBand(bandFixture(14, 11, 16, 28, 37, 39, 30, 21))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if band.MinTempC != 11 || band.MaxTempC != 39 || band.SpreadC != 28 {
+		t.Errorf("unexpected band: %+v", band)
+	}
+	if band.Note == "" {
+		t.Error("expected a large swing note")
+	}
+}
+
+func TestDayBandMaritime(t *testing.T) {
+	band, err := new(WeatherClient).dayBand(bandFixture(12, 11, 12, 14, 15, 16, 14, 13))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if band.MinTempC != 11 || band.MaxTempC != 16 || band.SpreadC != 5 {
+		t.Errorf("unexpected band: %+v", band)
+	}
+	if band.Note != "" {
+		t.Errorf("expected no note for a stable day, got %q", band.Note)
+	}
+}
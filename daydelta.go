@@ -0,0 +1,47 @@
+package main
+
+// DayDelta compares two forecast days: the temperature differences are
+// To minus From, so positive means warmer.
+type DayDelta struct {
+	From            DaySummary `json:"from"`
+	To              DaySummary `json:"to"`
+	MaxTempDeltaC   float64    `json:"max_temp_delta_c"`
+	MinTempDeltaC   float64    `json:"min_temp_delta_c"`
+	ConditionChange string     `json:"condition_change,omitempty"`
+}
+
+// dayDelta compares the forecast days at offsets a and b.
+func (c *WeatherClient) dayDelta(w DetailedWeather, a, b int) (DayDelta, error) {
+	for _, offset := range []int{a, b} {
+		if offset < 0 || offset >= len(w.Weather) {
+			return DayDelta{}, invalidArgument("day %d is beyond the %d-day forecast", offset, len(w.Weather))
+		}
+	}
+
+	from, to := summarizeDay(w.Weather[a]), summarizeDay(w.Weather[b])
+	delta := DayDelta{
+		From:          from,
+		To:            to,
+		MaxTempDeltaC: c.round(to.MaxTempC - from.MaxTempC),
+		MinTempDeltaC: c.round(to.MinTempC - from.MinTempC),
+	}
+	if from.Description != to.Description {
+		delta.ConditionChange = from.Description + " → " + to.Description
+	}
+	return delta, nil
+}
+
+// GetDayDelta returns how the forecast day at dayB differs from the one at
+// dayA as JSON.
+func (c *WeatherClient) GetDayDelta(location string, dayA, dayB int) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	delta, err := c.dayDelta(w, dayA, dayB)
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(delta)
+}
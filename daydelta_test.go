@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func deltaFixture() DetailedWeather {
+	day := func(date string, min, max number, desc string) DayForecast {
+		return DayForecast{Date: date, MinTempC: min, MaxTempC: max, Hourly: []HourlyWeather{
+			{Time: 1200, WeatherDesc: text{{Value: desc}}},
+		}}
+	}
+	return DetailedWeather{Weather: []DayForecast{
+		day("2024-06-11", 12, 19, "Light rain"),
+		day("2024-06-12", 14, 24, "Sunny"),
+		day("2024-06-13", 13, 22, "Sunny"),
+	}}
+}
+
+func TestDayDelta(t *testing.T) {
+	c := &WeatherClient{precision: 1}
+
+	delta, err := c.dayDelta(deltaFixture(), 0, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if delta.MaxTempDeltaC != 5 || delta.MinTempDeltaC != 2 {
+		t.Errorf("unexpected deltas: %+v", delta)
+	}
+	if delta.ConditionChange != "Light rain → Sunny" {
+		t.Errorf("unexpected condition change: %q", delta.ConditionChange)
+	}
+
+	delta, err = c.dayDelta(deltaFixture(), 1, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if delta.MaxTempDeltaC != -2 || delta.ConditionChange != "" {
+		t.Errorf("unexpected delta: %+v", delta)
+	}
+}
+
+func TestDayDeltaOutOfWindow(t *testing.T) {
+	c := &WeatherClient{precision: 1}
+	if _, err := c.dayDelta(deltaFixture(), 0, 3); err == nil {
+		t.Fatal("expected error for a day beyond the forecast")
+	}
+}
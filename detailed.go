@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DetailedWeather is the subset of the wttr.in j1 payload used by the parsed tools.
+type DetailedWeather struct {
+	CurrentCondition []CurrentCondition `json:"current_condition"`
+	NearestArea      []NearestArea      `json:"nearest_area"`
+	Weather          []DayForecast      `json:"weather"`
+}
+
+// CurrentCondition is a single observation from the j1 current_condition list.
+type CurrentCondition struct {
+	TempC            number `json:"temp_C"`
+	TempF            number `json:"temp_F"`
+	FeelsLikeC       number `json:"FeelsLikeC"`
+	FeelsLikeF       number `json:"FeelsLikeF"`
+	Humidity         number `json:"humidity"`
+	CloudCover       number `json:"cloudcover"`
+	PrecipMM         number `json:"precipMM"`
+	Pressure         number `json:"pressure"`
+	UVIndex          number `json:"uvIndex"`
+	Visibility       number `json:"visibility"`
+	WeatherCode      number `json:"weatherCode"`
+	WeatherDesc      text   `json:"weatherDesc"`
+	WindDir16Point   string `json:"winddir16Point"`
+	WindDirDegree    number `json:"winddirDegree"`
+	WindSpeedKmph    number `json:"windspeedKmph"`
+	LocalObsDateTime string `json:"localObsDateTime"`
+	ObservationTime  string `json:"observation_time"`
+}
+
+// NearestArea describes the place wttr.in resolved the query to.
+type NearestArea struct {
+	AreaName  text   `json:"areaName"`
+	Country   text   `json:"country"`
+	Region    text   `json:"region"`
+	Latitude  number `json:"latitude"`
+	Longitude number `json:"longitude"`
+}
+
+// DayForecast is one entry of the j1 weather list.
+type DayForecast struct {
+	Date      string          `json:"date"`
+	MaxTempC  number          `json:"maxtempC"`
+	MinTempC  number          `json:"mintempC"`
+	AvgTempC  number          `json:"avgtempC"`
+	UVIndex   number          `json:"uvIndex"`
+	Astronomy []Astronomy     `json:"astronomy"`
+	Hourly    []HourlyWeather `json:"hourly"`
+}
+
+// Astronomy holds the sun and moon times for a forecast day.
+type Astronomy struct {
+	Sunrise          string `json:"sunrise"`
+	Sunset           string `json:"sunset"`
+	Moonrise         string `json:"moonrise"`
+	Moonset          string `json:"moonset"`
+	MoonPhase        string `json:"moon_phase"`
+	MoonIllumination number `json:"moon_illumination"`
+}
+
+// HourlyWeather is one 3-hourly slot of a forecast day.
+type HourlyWeather struct {
+	Time           number         `json:"time"`
+	TempC          number         `json:"tempC"`
+	TempF          number         `json:"tempF"`
+	FeelsLikeC     number         `json:"FeelsLikeC"`
+	FeelsLikeF     number         `json:"FeelsLikeF"`
+	Humidity       number         `json:"humidity"`
+	CloudCover     number         `json:"cloudcover"`
+	ChanceOfRain   number         `json:"chanceofrain"`
+	ChanceOfSnow   number         `json:"chanceofsnow"`
+	PrecipMM       number         `json:"precipMM"`
+	Pressure       number         `json:"pressure"`
+	UVIndex        number         `json:"uvIndex"`
+	Visibility     number         `json:"visibility"`
+	WeatherCode    number         `json:"weatherCode"`
+	WeatherDesc    text           `json:"weatherDesc"`
+	WindDir16Point string         `json:"winddir16Point"`
+	WindDirDegree  number         `json:"winddirDegree"`
+	WindSpeedKmph  number         `json:"windspeedKmph"`
+	WindGustKmph   optionalNumber `json:"WindGustKmph"`
+}
+
+// number decodes the numeric strings used throughout the j1 payload ("25", "0.3").
+type number float64
+
+func (n *number) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*n = 0
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("parsing number %s: %w", data, err)
+	}
+	*n = number(f)
+	return nil
+}
+
+// optionalNumber is a number that may be missing or empty, which j1 does
+// for some fields at some locations.
+type optionalNumber struct {
+	Value float64
+	Valid bool
+}
+
+func (n *optionalNumber) UnmarshalJSON(data []byte) error {
+	var v number
+	if err := v.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	s := strings.Trim(string(data), `"`)
+	*n = optionalNumber{Value: float64(v), Valid: s != "" && s != "null"}
+	return nil
+}
+
+// MarshalJSON writes a missing number as null, for results that pass
+// parsed slots through.
+func (n optionalNumber) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Value)
+}
+
+// text decodes the [{"value": "..."}] lists j1 uses for descriptive fields.
+type text []struct {
+	Value string `json:"value"`
+}
+
+func (t text) String() string {
+	if len(t) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(t[0].Value)
+}
+
+func parseDetailed(body string) (DetailedWeather, error) {
+	var w DetailedWeather
+	if err := json.Unmarshal([]byte(body), &w); err != nil {
+		return w, fmt.Errorf("parsing weather data: %w", err)
+	}
+	return w, nil
+}
+
+// current returns the first current condition entry.
+func (w DetailedWeather) current() (CurrentCondition, error) {
+	if len(w.CurrentCondition) == 0 {
+		return CurrentCondition{}, fmt.Errorf("no current conditions in weather data")
+	}
+	return w.CurrentCondition[0], nil
+}
+
+// CurrentProjection is a subset of the j1 current conditions.
+type CurrentProjection struct {
+	Current       map[string]json.RawMessage `json:"current"`
+	UnknownFields []string                   `json:"unknown_fields,omitempty"`
+}
+
+// projectCurrent keeps only the named fields of the first j1 current
+// condition, with their upstream names and values. Names it doesn't have
+// are reported as unknown.
+func projectCurrent(body string, fields []string) (CurrentProjection, error) {
+	var raw struct {
+		CurrentCondition []map[string]json.RawMessage `json:"current_condition"`
+	}
+	if err := json.Unmarshal([]byte(body), &raw); err != nil {
+		return CurrentProjection{}, fmt.Errorf("parsing weather data: %w", err)
+	}
+	if len(raw.CurrentCondition) == 0 {
+		return CurrentProjection{}, fmt.Errorf("no current conditions in weather data")
+	}
+
+	current := raw.CurrentCondition[0]
+	projection := CurrentProjection{Current: map[string]json.RawMessage{}}
+	for _, field := range fields {
+		if value, ok := current[field]; ok {
+			projection.Current[field] = value
+		} else {
+			projection.UnknownFields = append(projection.UnknownFields, field)
+		}
+	}
+	return projection, nil
+}
+
+// fetchDetailed fetches the j1 payload for a location and decodes it.
+func (c *WeatherClient) fetchDetailed(location string) (DetailedWeather, error) {
+	body, err := c.GetDetailed(location)
+	if err != nil {
+		return DetailedWeather{}, err
+	}
+	return parseDetailed(body)
+}
+
+// roundTo rounds v to the given number of decimal places.
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
+
+// round rounds a computed value to the configured precision, so every tool
+// reports fractions the same way.
+func (c *WeatherClient) round(v float64) float64 {
+	return roundTo(v, c.precision)
+}
+
+func marshalResult(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encoding result: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import "testing"
+
+const sampleJ1 = `{
+	"current_condition": [{
+		"temp_C": "25", "temp_F": "77", "FeelsLikeC": "26", "humidity": "44",
+		"precipMM": "0.0", "visibility": "10", "windspeedKmph": "11", "winddir16Point": "NW",
+		"weatherCode": "113", "weatherDesc": [{"value": "Sunny "}],
+		"localObsDateTime": "2024-06-12 02:35 PM", "observation_time": "12:35 PM"
+	}],
+	"nearest_area": [{
+		"areaName": [{"value": "London"}], "country": [{"value": "United Kingdom"}],
+		"latitude": "51.517", "longitude": "-0.106"
+	}],
+	"weather": [{
+		"date": "2024-06-12", "maxtempC": "27", "mintempC": "15",
+		"astronomy": [{"sunrise": "04:43 AM", "sunset": "09:18 PM"}],
+		"hourly": [{"time": "0", "tempC": "16", "chanceofrain": "0"}, {"time": "300", "tempC": "15", "chanceofrain": "10"}]
+	}]
+}`
+
+func TestParseDetailed(t *testing.T) {
+	w, err := parseDetailed(sampleJ1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cur, err := w.current()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cur.TempC != 25 || cur.WindSpeedKmph != 11 {
+		t.Errorf("unexpected current condition: %+v", cur)
+	}
+	if cur.WeatherDesc.String() != "Sunny" {
+		t.Errorf("expected trimmed description Sunny, got %q", cur.WeatherDesc.String())
+	}
+	if w.NearestArea[0].AreaName.String() != "London" || w.NearestArea[0].Longitude != -0.106 {
+		t.Errorf("unexpected nearest area: %+v", w.NearestArea[0])
+	}
+	if len(w.Weather) != 1 || len(w.Weather[0].Hourly) != 2 || w.Weather[0].Hourly[1].Time != 300 {
+		t.Errorf("unexpected forecast: %+v", w.Weather)
+	}
+}
+
+func TestParseDetailedEmptyNumbers(t *testing.T) {
+	w, err := parseDetailed(`{"current_condition": [{"temp_C": "", "uvIndex": "3"}]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.CurrentCondition[0].TempC != 0 || w.CurrentCondition[0].UVIndex != 3 {
+		t.Errorf("unexpected values: %+v", w.CurrentCondition[0])
+	}
+}
+
+func TestParseDetailedInvalidNumber(t *testing.T) {
+	if _, err := parseDetailed(`{"current_condition": [{"temp_C": "warm"}]}`); err == nil {
+		t.Fatal("expected error for non-numeric temperature")
+	}
+}
+
+func TestDetailedWeatherNoCurrent(t *testing.T) {
+	if _, err := (DetailedWeather{}).current(); err == nil {
+		t.Fatal("expected error when current conditions are missing")
+	}
+}
+
+func TestRoundTo(t *testing.T) {
+	tests := []struct {
+		value  float64
+		places int
+		want   float64
+	}{
+		{12.34, 0, 12},
+		{12.5, 0, 13},
+		{-3.6, 0, -4},
+		{12.34, 1, 12.3},
+		{12.35, 1, 12.4},
+		{-0.04, 1, -0},
+		{7, 1, 7},
+	}
+
+	for _, tt := range tests {
+		if got := roundTo(tt.value, tt.places); got != tt.want {
+			t.Errorf("roundTo(%v, %d) = %v, want %v", tt.value, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestWeatherClientRoundUsesPrecision(t *testing.T) {
+	client := NewWeatherClient(Config{Precision: 0})
+	if got := client.round(18.6); got != 19 {
+		t.Errorf("expected 19 at precision 0, got %v", got)
+	}
+
+	client = NewWeatherClient(Config{Precision: 1})
+	if got := client.round(18.66); got != 18.7 {
+		t.Errorf("expected 18.7 at precision 1, got %v", got)
+	}
+}
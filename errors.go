@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Error codes carried in the _meta.error_code of isError results, so
+// clients can react to a failure without parsing its message.
+const (
+	errorCodeInvalidArguments    = "invalid_arguments"
+	errorCodeUnknownLocation     = "unknown_location"
+	errorCodeRateLimited         = "rate_limited"
+	errorCodeUpstreamUnavailable = "upstream_unavailable"
+	errorCodeUpstreamError       = "upstream_error"
+	errorCodeInternal            = "internal_error"
+)
+
+// Error styles for failed tool calls.
+const (
+	errorStyleContent = "content"
+	errorStyleRPC     = "rpc"
+)
+
+// errToolFailed is the JSON-RPC error code of failed tool calls in the rpc
+// error style, from the range reserved for implementation-defined errors.
+const errToolFailed = -32000
+
+// errEmptyResponse is returned when wttr.in answers with an empty body,
+// which it does when overloaded.
+var errEmptyResponse = errors.New("upstream returned empty response")
+
+// errUnavailable is returned when wttr.in answers with a maintenance or
+// overload notice instead of weather.
+var errUnavailable = errors.New("wttr.in is temporarily unavailable, try again later")
+
+// defaultMaintenancePhrases are notices wttr.in serves with a 200 status
+// when it is overloaded or under maintenance.
+var defaultMaintenancePhrases = []string{
+	"Sorry, we are running out of queries to the weather service",
+	"Sorry, we processed more than",
+	"service is temporarily unavailable",
+	"down for maintenance",
+}
+
+// isMaintenanceNotice reports whether body contains one of phrases,
+// ignoring case.
+func isMaintenanceNotice(body string, phrases []string) bool {
+	body = strings.ToLower(body)
+	for _, phrase := range phrases {
+		if phrase != "" && strings.Contains(body, strings.ToLower(phrase)) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusError is a non-200 response from wttr.in, with its headers and
+// the Retry-After delay if it sent one.
+type statusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+	Header     http.Header
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("wttr.in returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// cooldownError is returned instead of requesting wttr.in while it is
+// rate limiting and no cached response is at hand.
+type cooldownError struct {
+	Until time.Time
+}
+
+func (e *cooldownError) Error() string {
+	return fmt.Sprintf("wttr.in is rate limiting requests; try again after %s", e.Until.UTC().Format("15:04:05 UTC"))
+}
+
+// argumentError is a tool argument the client rejects only once it is
+// used, such as an unknown field name.
+type argumentError struct {
+	msg string
+}
+
+func (e *argumentError) Error() string {
+	return e.msg
+}
+
+func invalidArgument(format string, args ...interface{}) error {
+	return &argumentError{msg: fmt.Sprintf(format, args...)}
+}
+
+// errorCode categorizes the error behind an isError result.
+func errorCode(err error) string {
+	var argErr *argumentError
+	if errors.As(err, &argErr) {
+		return errorCodeInvalidArguments
+	}
+
+	var status *statusError
+	if errors.As(err, &status) {
+		switch {
+		case status.StatusCode == http.StatusNotFound || strings.Contains(status.Body, "Unknown location"):
+			return errorCodeUnknownLocation
+		case status.StatusCode == http.StatusTooManyRequests:
+			return errorCodeRateLimited
+		case status.StatusCode >= 500:
+			return errorCodeUpstreamUnavailable
+		default:
+			return errorCodeUpstreamError
+		}
+	}
+
+	var cooldown *cooldownError
+	if errors.As(err, &cooldown) {
+		return errorCodeRateLimited
+	}
+
+	var netErr net.Error
+	if errors.Is(err, errEmptyResponse) || errors.Is(err, errUnavailable) || errors.As(err, &netErr) {
+		return errorCodeUpstreamUnavailable
+	}
+
+	return errorCodeInternal
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{checkCoordinates("100,0"), "invalid_arguments"},
+		{fmt.Errorf("wrapped: %w", invalidArgument("day 3 is beyond the 3-day forecast")), "invalid_arguments"},
+		{&statusError{StatusCode: 404, Body: "Unknown location; please try ~Atlantis"}, "unknown_location"},
+		{&statusError{StatusCode: 200, Body: "Unknown location"}, "unknown_location"},
+		{&statusError{StatusCode: 429, Body: "Too many queries"}, "rate_limited"},
+		{&statusError{StatusCode: 503, Body: "Service Unavailable"}, "upstream_unavailable"},
+		{&statusError{StatusCode: 403, Body: "Forbidden"}, "upstream_error"},
+		{errEmptyResponse, "upstream_unavailable"},
+		{errUnavailable, "upstream_unavailable"},
+		{fmt.Errorf("parsing weather data: unexpected EOF"), "internal_error"},
+	}
+
+	for _, tt := range tests {
+		if got := errorCode(tt.err); got != tt.want {
+			t.Errorf("%v: expected %s, got %s", tt.err, tt.want, got)
+		}
+	}
+}
+
+func TestErrorCodeNetworkFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+	srv.Close()
+
+	_, err := client.GetCurrent("London")
+	if got := errorCode(err); got != "upstream_unavailable" {
+		t.Errorf("%v: expected upstream_unavailable, got %s", err, got)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxEvents caps the events of one call, each of which may cost an
+// upstream request.
+const maxEvents = 20
+
+// eventLayouts are the accepted event datetimes, local time at the event's
+// location.
+var eventLayouts = []string{"2006-01-02T15:04", "2006-01-02 15:04"}
+
+// Event is a calendar entry to forecast the weather for.
+type Event struct {
+	Title    string `json:"title"`
+	Location string `json:"location"`
+	Datetime string `json:"datetime"`
+}
+
+// parseEventTime parses an event datetime.
+func parseEventTime(datetime string) (time.Time, error) {
+	for _, layout := range eventLayouts {
+		if t, err := time.Parse(layout, datetime); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, invalidArgument("datetime %q must be formatted as YYYY-MM-DDTHH:MM", datetime)
+}
+
+// checkEvents validates the events of a call before anything is fetched.
+func checkEvents(events []Event) error {
+	if len(events) == 0 {
+		return invalidArgument("at least one event is required")
+	}
+	if len(events) > maxEvents {
+		return invalidArgument("%d events given, the limit is %d", len(events), maxEvents)
+	}
+	for i, e := range events {
+		if e.Location == "" {
+			return invalidArgument("event %d has no location", i)
+		}
+		if err := checkCoordinates(e.Location); err != nil {
+			return err
+		}
+		if _, err := parseEventTime(e.Datetime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EventWeather is the forecast for an event, from the 3-hourly slot
+// nearest to its start. Note explains a missing forecast.
+type EventWeather struct {
+	Title        string   `json:"title,omitempty"`
+	Location     string   `json:"location"`
+	Datetime     string   `json:"datetime"`
+	Slot         string   `json:"slot,omitempty"`
+	TempC        *float64 `json:"temp_c,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	ChanceOfRain *int     `json:"chance_of_rain,omitempty"`
+	Note         string   `json:"note,omitempty"`
+}
+
+// eventWeather finds the forecast for an event at a time in the weather
+// data of its location.
+func eventWeather(e Event, at time.Time, w DetailedWeather) EventWeather {
+	result := EventWeather{Title: e.Title, Location: e.Location, Datetime: e.Datetime}
+	date := at.Format(j1DateLayout)
+	want := snapToSlot(at.Hour())
+
+	for _, d := range w.Weather {
+		if d.Date != date {
+			continue
+		}
+		for _, h := range d.Hourly {
+			if int(h.Time) != want {
+				continue
+			}
+			temp, chance := float64(h.TempC), int(h.ChanceOfRain)
+			result.Slot = fmt.Sprintf("%s %02d:00", date, want/100)
+			result.TempC = &temp
+			result.Description = h.WeatherDesc.String()
+			result.ChanceOfRain = &chance
+			return result
+		}
+		result.Note = fmt.Sprintf("no %02d:00 slot in the forecast", want/100)
+		return result
+	}
+
+	if len(w.Weather) > 0 && date < w.Weather[0].Date {
+		result.Note = "in the past"
+	} else {
+		result.Note = "beyond forecast"
+	}
+	return result
+}
+
+// GetEventsWeather returns the forecast for each event as a JSON array, in
+// the order given. Each location is fetched once, concurrently.
+func (c *WeatherClient) GetEventsWeather(events []Event) (string, error) {
+	if err := checkEvents(events); err != nil {
+		return "", err
+	}
+
+	var locations []string
+	seen := map[string]bool{}
+	for _, e := range events {
+		if !seen[e.Location] {
+			seen[e.Location] = true
+			locations = append(locations, e.Location)
+		}
+	}
+	fetched := map[string]locationResult{}
+	for _, r := range fetchAll(locations, c.GetDetailed) {
+		fetched[r.Location] = r
+	}
+
+	results := make([]EventWeather, len(events))
+	for i, e := range events {
+		at, _ := parseEventTime(e.Datetime)
+		r := fetched[e.Location]
+		if r.Err != nil {
+			results[i] = EventWeather{Title: e.Title, Location: e.Location, Datetime: e.Datetime, Note: r.Err.Error()}
+			continue
+		}
+		w, err := parseDetailed(r.Text)
+		if err != nil {
+			return "", err
+		}
+		results[i] = eventWeather(e, at, w)
+	}
+	return marshalResult(results)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWeatherClientGetEventsWeather(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"weather": [
+			{"date": "2024-06-12", "hourly": [
+				{"time": "1200", "tempC": "21", "chanceofrain": "10", "weatherDesc": [{"value": "Sunny"}]},
+				{"time": "1500", "tempC": "23", "chanceofrain": "40", "weatherDesc": [{"value": "Patchy rain nearby"}]}
+			]},
+			{"date": "2024-06-13", "hourly": [{"time": "1500", "tempC": "18", "weatherDesc": [{"value": "Overcast"}]}]}
+		]}`))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.GetEventsWeather([]Event{
+		{Title: "Picnic", Location: "London", Datetime: "2024-06-12T14:30"},
+		{Title: "Conference", Location: "London", Datetime: "2024-06-20T09:00"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected the shared location to be fetched once, got %d requests", requests)
+	}
+
+	var events []EventWeather
+	if err := json.Unmarshal([]byte(result), &events); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+
+	picnic := events[0]
+	if picnic.Slot != "2024-06-12 15:00" || picnic.TempC == nil || *picnic.TempC != 23 || picnic.Description != "Patchy rain nearby" || picnic.Note != "" {
+		t.Errorf("unexpected in-window event: %+v", picnic)
+	}
+	if conference := events[1]; conference.Note != "beyond forecast" || conference.TempC != nil {
+		t.Errorf("unexpected out-of-window event: %+v", conference)
+	}
+}
+
+func TestCheckEvents(t *testing.T) {
+	for _, events := range [][]Event{
+		nil,
+		{{Title: "No place", Datetime: "2024-06-12T14:30"}},
+		{{Location: "London", Datetime: "tomorrow"}},
+		make([]Event, maxEvents+1),
+	} {
+		if err := checkEvents(events); errorCode(err) != errorCodeInvalidArguments {
+			t.Errorf("%+v: expected an invalid argument error, got %v", events, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import "fmt"
+
+// TempAt is a temperature at a time of day.
+type TempAt struct {
+	Time  timeValue `json:"time"`
+	TempC float64   `json:"temp_c"`
+	TempF *float64  `json:"temp_f,omitempty"`
+}
+
+// TempExtremes is the coldest and warmest slot of a forecast day.
+type TempExtremes struct {
+	Date    string `json:"date"`
+	Day     string `json:"day"`
+	Coldest TempAt `json:"coldest"`
+	Warmest TempAt `json:"warmest"`
+}
+
+// tempExtremes finds the coldest and warmest hourly slots of the forecast
+// day at offset. On ties the earliest slot wins.
+func (c *WeatherClient) tempExtremes(w DetailedWeather, offset int) (TempExtremes, error) {
+	if offset < 0 || offset >= len(w.Weather) {
+		return TempExtremes{}, invalidArgument("day %d is beyond the %d-day forecast", offset, len(w.Weather))
+	}
+	d := w.Weather[offset]
+	if len(d.Hourly) == 0 {
+		return TempExtremes{}, fmt.Errorf("no hourly forecast for %s", d.Date)
+	}
+
+	coldest, warmest := d.Hourly[0], d.Hourly[0]
+	for _, h := range d.Hourly[1:] {
+		if h.TempC < coldest.TempC {
+			coldest = h
+		}
+		if h.TempC > warmest.TempC {
+			warmest = h
+		}
+	}
+
+	at := func(h HourlyWeather) TempAt {
+		return TempAt{Time: slotClock(h.Time), TempC: float64(h.TempC), TempF: c.fahrenheit(h.TempF)}
+	}
+	return TempExtremes{Date: d.Date, Day: summarizeDay(d).Day, Coldest: at(coldest), Warmest: at(warmest)}, nil
+}
+
+// GetTempExtremes returns the coldest and warmest time of the day at
+// dayOffset (0 is today) as JSON.
+func (c *WeatherClient) GetTempExtremes(location string, dayOffset int) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := c.tempExtremes(w, dayOffset)
+	if err != nil {
+		return "", err
+	}
+	if c.convertsTimes() {
+		if result.Coldest.Time, err = c.zoneClock(w, result.Date, result.Coldest.Time); err != nil {
+			return "", err
+		}
+		if result.Warmest.Time, err = c.zoneClock(w, result.Date, result.Warmest.Time); err != nil {
+			return "", err
+		}
+	}
+	return marshalResult(result)
+}
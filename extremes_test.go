@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestTempExtremes(t *testing.T) {
+	// segmentsFixture warms steadily; give it a clear trough and peak.
+	w := segmentsFixture()
+	hourly := w.Weather[0].Hourly
+	hourly[1].TempC = 4  // 03:00
+	hourly[5].TempC = 26 // 15:00
+
+	result, err := new(WeatherClient).tempExtremes(w, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Coldest != (TempAt{Time: "03:00", TempC: 4}) {
+		t.Errorf("unexpected coldest: %+v", result.Coldest)
+	}
+	if result.Warmest != (TempAt{Time: "15:00", TempC: 26}) {
+		t.Errorf("unexpected warmest: %+v", result.Warmest)
+	}
+}
+
+func TestTempExtremesTies(t *testing.T) {
+	w := DetailedWeather{Weather: []DayForecast{{Date: "2024-06-12", Hourly: []HourlyWeather{
+		{Time: 0, TempC: 10},
+		{Time: 300, TempC: 8},
+		{Time: 600, TempC: 8},
+		{Time: 1200, TempC: 20},
+		{Time: 1500, TempC: 20},
+	}}}}
+
+	result, err := new(WeatherClient).tempExtremes(w, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Coldest.Time != "03:00" || result.Warmest.Time != "12:00" {
+		t.Errorf("expected the earliest slots on ties, got %+v", result)
+	}
+}
+
+func TestTempExtremesBeyondForecast(t *testing.T) {
+	if _, err := new(WeatherClient).tempExtremes(segmentsFixture(), 1); err == nil {
+		t.Fatal("expected error beyond the forecast")
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkAirportCode validates an IATA airport code: three letters, in any
+// case. wttr.in looks such locations up as airports itself.
+func checkAirportCode(code string) error {
+	if len(code) != 3 {
+		return invalidArgument("airport code %q must be three letters", code)
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return invalidArgument("airport code %q must be three letters", code)
+		}
+	}
+	return nil
+}
+
+// GetFlightWeather returns the current weather one-liners at both ends of
+// a route, fetched concurrently and labeled with the airport codes.
+func (c *WeatherClient) GetFlightWeather(origin, dest string) (string, error) {
+	for _, code := range []string{origin, dest} {
+		if err := checkAirportCode(code); err != nil {
+			return "", err
+		}
+	}
+
+	// Airports are queried in lowercase, as wttr.in documents them
+	// (wttr.in/muc), and labeled in the usual uppercase.
+	origin, dest = strings.ToUpper(origin), strings.ToUpper(dest)
+	results := fetchAll([]string{strings.ToLower(origin), strings.ToLower(dest)}, func(location string) (string, error) {
+		return c.GetCurrent(location)
+	})
+	labels := []string{origin + " (origin)", dest + " (destination)"}
+
+	lines := make([]string, len(results))
+	for i, r := range results {
+		if r.Err != nil {
+			return "", fmt.Errorf("%s: %w", labels[i], r.Err)
+		}
+		lines[i] = labelOneLiner(r.Text, labels[i])
+	}
+	return strings.Join(lines, "\n"), nil
+}
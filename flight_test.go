@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckAirportCode(t *testing.T) {
+	for _, code := range []string{"MUC", "jfk", "Lhr"} {
+		if err := checkAirportCode(code); err != nil {
+			t.Errorf("%s: unexpected error: %v", code, err)
+		}
+	}
+	for _, code := range []string{"", "MU", "MUNC", "M1C", "MÜC"} {
+		if err := checkAirportCode(code); errorCode(err) != errorCodeInvalidArguments {
+			t.Errorf("%q: expected an invalid argument error, got %v", code, err)
+		}
+	}
+}
+
+func TestWeatherClientGetFlightWeather(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/muc":
+			w.Write([]byte("muc: ☀️ +20°C"))
+		case "/jfk":
+			w.Write([]byte("jfk: 🌧 +15°C"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.GetFlightWeather("MUC", "jfk")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "MUC (origin): ☀️ +20°C\nJFK (destination): 🌧 +15°C"; result != want {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+
+	if _, err := client.GetFlightWeather("MUC", "J2K"); errorCode(err) != errorCodeInvalidArguments {
+		t.Errorf("expected an invalid argument error for the destination, got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	j1DateLayout     = "2006-01-02"
+	j1DateTimeLayout = "2006-01-02 03:04 PM"
+)
+
+// DaySummary is the condensed view of a forecast day used by the parsed tools.
+type DaySummary struct {
+	Date         string  `json:"date"`
+	Day          string  `json:"day"`
+	MinTempC     float64 `json:"min_c"`
+	MaxTempC     float64 `json:"max_c"`
+	Description  string  `json:"description"`
+	ChanceOfRain int     `json:"chance_of_rain"`
+	// Hourly is the day's parsed hourly slots, when raw hourly data was
+	// requested.
+	Hourly []HourlyWeather `json:"hourly,omitempty"`
+}
+
+// summarizeDay condenses a forecast day. The description is taken from the
+// slot closest to noon and the chance of rain is the highest of the day.
+func summarizeDay(d DayForecast) DaySummary {
+	summary := DaySummary{
+		Date:     d.Date,
+		MinTempC: float64(d.MinTempC),
+		MaxTempC: float64(d.MaxTempC),
+	}
+	if date, err := time.Parse(j1DateLayout, d.Date); err == nil {
+		summary.Day = date.Weekday().String()
+	}
+
+	for _, h := range d.Hourly {
+		if chance := int(h.ChanceOfRain); chance > summary.ChanceOfRain {
+			summary.ChanceOfRain = chance
+		}
+	}
+	if noon, ok := noonSlot(d); ok {
+		summary.Description = noon.WeatherDesc.String()
+	}
+
+	return summary
+}
+
+// noonSlot returns the hourly slot of a forecast day closest to noon, which
+// stands for the day's conditions.
+func noonSlot(d DayForecast) (HourlyWeather, bool) {
+	noon := -1
+	for i, h := range d.Hourly {
+		if noon < 0 || math.Abs(float64(h.Time)-1200) < math.Abs(float64(d.Hourly[noon].Time)-1200) {
+			noon = i
+		}
+	}
+	if noon < 0 {
+		return HourlyWeather{}, false
+	}
+	return d.Hourly[noon], true
+}
+
+// localDate returns the current date at the location, taken from the
+// observation time and falling back to the first forecast day.
+func localDate(w DetailedWeather) (time.Time, error) {
+	if cur, err := w.current(); err == nil && cur.LocalObsDateTime != "" {
+		if t, err := time.Parse(j1DateTimeLayout, cur.LocalObsDateTime); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	if len(w.Weather) > 0 {
+		if t, err := time.Parse(j1DateLayout, w.Weather[0].Date); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("weather data has no local date")
+}
+
+// forecastDay returns the forecast entry for date, if it is within the forecast window.
+func forecastDay(w DetailedWeather, date time.Time) (DayForecast, bool) {
+	want := date.Format(j1DateLayout)
+	for _, d := range w.Weather {
+		if d.Date == want {
+			return d, true
+		}
+	}
+	return DayForecast{}, false
+}
+
+// WeekendReport lists the forecast for the upcoming Saturday and Sunday.
+type WeekendReport struct {
+	Today string       `json:"today"`
+	Days  []DaySummary `json:"days"`
+	Note  string       `json:"note,omitempty"`
+}
+
+// weekendForecast picks the upcoming weekend out of the forecast. On a
+// Saturday the weekend is today and tomorrow; on a Sunday it is only today.
+func weekendForecast(w DetailedWeather) (WeekendReport, error) {
+	today, err := localDate(w)
+	if err != nil {
+		return WeekendReport{}, err
+	}
+
+	report := WeekendReport{Today: today.Format(j1DateLayout), Days: []DaySummary{}}
+
+	var weekend []time.Time
+	switch today.Weekday() {
+	case time.Saturday:
+		weekend = []time.Time{today, today.AddDate(0, 0, 1)}
+	case time.Sunday:
+		weekend = []time.Time{today}
+	default:
+		saturday := today.AddDate(0, 0, int(time.Saturday-today.Weekday()))
+		weekend = []time.Time{saturday, saturday.AddDate(0, 0, 1)}
+	}
+
+	var missing []string
+	for _, date := range weekend {
+		d, ok := forecastDay(w, date)
+		if !ok {
+			missing = append(missing, fmt.Sprintf("%s (%s)", date.Weekday(), date.Format(j1DateLayout)))
+			continue
+		}
+		report.Days = append(report.Days, summarizeDay(d))
+	}
+
+	if len(missing) > 0 {
+		verb := "is"
+		if len(missing) > 1 {
+			verb = "are"
+		}
+		report.Note = fmt.Sprintf("%s %s beyond the %d-day forecast horizon", strings.Join(missing, " and "), verb, len(w.Weather))
+	}
+
+	return report, nil
+}
+
+// GetWeekend returns the forecast for the upcoming weekend as JSON.
+func (c *WeatherClient) GetWeekend(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	report, err := weekendForecast(w)
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(report)
+}
+
+// GetTomorrow returns the summary of tomorrow's forecast as JSON.
+func (c *WeatherClient) GetTomorrow(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	if len(w.Weather) < 2 {
+		return "", fmt.Errorf("no forecast for tomorrow in weather data")
+	}
+	summary := summarizeDay(w.Weather[1])
+	summary.Hourly = c.rawHourly(w.Weather[1])
+	return marshalResult(summary)
+}
+
+// Forecast renderings get_forecast can return. ASCII is wttr.in's own
+// text forecast; the others are built from the j1 data.
+const (
+	forecastFormatASCII    = "ascii"
+	forecastFormatJSON     = "json"
+	forecastFormatMarkdown = "markdown"
+)
+
+var forecastFormats = []string{forecastFormatASCII, forecastFormatJSON, forecastFormatMarkdown}
+
+// ForecastSummary is the parsed forecast for a number of days.
+type ForecastSummary struct {
+	Days []DaySummary `json:"days"`
+}
+
+// summarizeForecast condenses the first days of the forecast. Zero days
+// is today only, as in wttr.in's today-only view.
+func summarizeForecast(w DetailedWeather, days int) ForecastSummary {
+	days = max(days, 1)
+	summary := ForecastSummary{Days: []DaySummary{}}
+	for _, d := range w.Weather[:min(days, len(w.Weather))] {
+		summary.Days = append(summary.Days, summarizeDay(d))
+	}
+	return summary
+}
+
+// markdown renders the forecast as a Markdown table.
+func (f ForecastSummary) markdown() string {
+	var b strings.Builder
+	b.WriteString("| Date | Day | Min °C | Max °C | Conditions | Chance of rain |\n")
+	b.WriteString("|------|-----|--------|--------|------------|----------------|\n")
+	for _, d := range f.Days {
+		fmt.Fprintf(&b, "| %s | %s | %g | %g | %s | %d%% |\n", d.Date, d.Day, d.MinTempC, d.MaxTempC, d.Description, d.ChanceOfRain)
+	}
+	return b.String()
+}
+
+// GetForecastSummary returns the forecast for the given number of days as
+// JSON or as a Markdown table.
+func (c *WeatherClient) GetForecastSummary(location string, days int, format string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	summary := summarizeForecast(w, days)
+	for i := range summary.Days {
+		summary.Days[i].Hourly = c.rawHourly(w.Weather[i])
+	}
+	switch format {
+	case forecastFormatJSON:
+		return marshalResult(summary)
+	case forecastFormatMarkdown:
+		return summary.markdown(), nil
+	default:
+		return "", fmt.Errorf("unsupported forecast format %q", format)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// forecastFixture builds a j1 payload observed at obs with forecast days
+// starting at the observation date.
+func forecastFixture(obs string, days int) DetailedWeather {
+	start, _ := time.Parse(j1DateTimeLayout, obs)
+	w := DetailedWeather{CurrentCondition: []CurrentCondition{{LocalObsDateTime: obs}}}
+	for i := 0; i < days; i++ {
+		w.Weather = append(w.Weather, DayForecast{
+			Date:     start.AddDate(0, 0, i).Format(j1DateLayout),
+			MinTempC: number(10 + i),
+			MaxTempC: number(20 + i),
+			Hourly: []HourlyWeather{
+				{Time: 900, ChanceOfRain: number(10 * i), WeatherDesc: text{{Value: "Cloudy"}}},
+				{Time: 1200, ChanceOfRain: 5, WeatherDesc: text{{Value: "Sunny "}}},
+			},
+		})
+	}
+	return w
+}
+
+func TestSummarizeDay(t *testing.T) {
+	w := forecastFixture("2024-06-12 02:35 PM", 3)
+
+	summary := summarizeDay(w.Weather[2])
+	if summary.Date != "2024-06-14" || summary.Day != "Friday" {
+		t.Errorf("unexpected date: %+v", summary)
+	}
+	if summary.MinTempC != 12 || summary.MaxTempC != 22 {
+		t.Errorf("unexpected temperatures: %+v", summary)
+	}
+	if summary.Description != "Sunny" {
+		t.Errorf("expected noon description Sunny, got %q", summary.Description)
+	}
+	if summary.ChanceOfRain != 20 {
+		t.Errorf("expected highest chance of rain 20, got %d", summary.ChanceOfRain)
+	}
+}
+
+func TestLocalDateFallsBackToForecast(t *testing.T) {
+	w := DetailedWeather{Weather: []DayForecast{{Date: "2024-06-15"}}}
+
+	date, err := localDate(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if date.Format(j1DateLayout) != "2024-06-15" {
+		t.Errorf("unexpected date: %v", date)
+	}
+
+	if _, err := localDate(DetailedWeather{}); err == nil {
+		t.Error("expected error without any date")
+	}
+}
+
+func TestWeekendForecastMidWeek(t *testing.T) {
+	// Wednesday: the forecast ends on Friday.
+	w := forecastFixture("2024-06-12 02:35 PM", 3)
+
+	report, err := weekendForecast(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Today != "2024-06-12" {
+		t.Errorf("expected today 2024-06-12, got %s", report.Today)
+	}
+	if len(report.Days) != 0 {
+		t.Errorf("expected no weekend days in the forecast, got %+v", report.Days)
+	}
+	if !strings.Contains(report.Note, "Saturday (2024-06-15) and Sunday (2024-06-16) are beyond") {
+		t.Errorf("unexpected note: %q", report.Note)
+	}
+}
+
+func TestWeekendForecastFriday(t *testing.T) {
+	w := forecastFixture("2024-06-14 08:00 AM", 3)
+
+	report, err := weekendForecast(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Days) != 2 {
+		t.Fatalf("expected 2 weekend days, got %+v", report.Days)
+	}
+	if report.Days[0].Day != "Saturday" || report.Days[0].Date != "2024-06-15" {
+		t.Errorf("unexpected first day: %+v", report.Days[0])
+	}
+	if report.Days[1].Day != "Sunday" || report.Days[1].Date != "2024-06-16" {
+		t.Errorf("unexpected second day: %+v", report.Days[1])
+	}
+	if report.Note != "" {
+		t.Errorf("expected no note, got %q", report.Note)
+	}
+}
+
+func TestWeekendForecastThursday(t *testing.T) {
+	w := forecastFixture("2024-06-13 08:00 AM", 3)
+
+	report, err := weekendForecast(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Days) != 1 || report.Days[0].Day != "Saturday" {
+		t.Errorf("expected only Saturday, got %+v", report.Days)
+	}
+	if !strings.HasPrefix(report.Note, "Sunday (2024-06-16) is beyond the 3-day") {
+		t.Errorf("unexpected note: %q", report.Note)
+	}
+}
+
+func TestWeekendForecastSunday(t *testing.T) {
+	w := forecastFixture("2024-06-16 08:00 AM", 3)
+
+	report, err := weekendForecast(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Days) != 1 || report.Days[0].Date != "2024-06-16" {
+		t.Errorf("expected only today, got %+v", report.Days)
+	}
+}
+
+func TestSummarizeForecastMarkdown(t *testing.T) {
+	w := DetailedWeather{Weather: []DayForecast{
+		{Date: "2024-06-12", MinTempC: 11, MaxTempC: 19, Hourly: []HourlyWeather{{Time: 1200, ChanceOfRain: 40, WeatherDesc: text{{Value: "Light rain"}}}}},
+		{Date: "2024-06-13", MinTempC: 12, MaxTempC: 22, Hourly: []HourlyWeather{{Time: 1200, WeatherDesc: text{{Value: "Sunny"}}}}},
+	}}
+
+	if days := summarizeForecast(w, 0).Days; len(days) != 1 {
+		t.Errorf("expected today only for 0 days, got %d days", len(days))
+	}
+
+	got := summarizeForecast(w, 3).markdown()
+	want := "| Date | Day | Min °C | Max °C | Conditions | Chance of rain |\n" +
+		"|------|-----|--------|--------|------------|----------------|\n" +
+		"| 2024-06-12 | Wednesday | 11 | 19 | Light rain | 40% |\n" +
+		"| 2024-06-13 | Thursday | 12 | 22 | Sunny | 0% |\n"
+	if got != want {
+		t.Errorf("unexpected table:\n%s", got)
+	}
+}
+
+func TestWeatherClientGetTomorrow(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"weather": [
+			{"date": "2024-06-12", "mintempC": "11", "maxtempC": "19", "hourly": [{"time": "1200", "chanceofrain": "10", "weatherDesc": [{"value": "Sunny"}]}]},
+			{"date": "2024-06-13", "mintempC": "9", "maxtempC": "15", "hourly": [{"time": "1200", "chanceofrain": "80", "weatherDesc": [{"value": "Moderate rain"}]}]},
+			{"date": "2024-06-14", "mintempC": "12", "maxtempC": "21", "hourly": [{"time": "1200", "chanceofrain": "0", "weatherDesc": [{"value": "Clear"}]}]}
+		]}`))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.GetTomorrow("Glasgow")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"date":"2024-06-13","day":"Thursday","min_c":9,"max_c":15,"description":"Moderate rain","chance_of_rain":80}`
+	if result != want {
+		t.Errorf("unexpected result: %s", result)
+	}
+
+	result, err = client.WithRawHourly().GetTomorrow("Glasgow")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var raw struct {
+		Hourly []map[string]interface{} `json:"hourly"`
+	}
+	if err := json.Unmarshal([]byte(result), &raw); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(raw.Hourly) != 1 || raw.Hourly[0]["time"] != 1200.0 || raw.Hourly[0]["chanceofrain"] != 80.0 {
+		t.Errorf("expected tomorrow's hourly slot, got %s", result)
+	}
+}
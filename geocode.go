@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// GeocodeResult is the place wttr.in resolves a location to.
+type GeocodeResult struct {
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+	Area       string  `json:"area"`
+	Region     string  `json:"region,omitempty"`
+	Country    string  `json:"country"`
+	Confidence string  `json:"confidence,omitempty"`
+	// DistanceKm is how far the resolved area lies from a "lat,lon" query.
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+}
+
+// geocode extracts the resolved place from the j1 nearest_area.
+func geocode(w DetailedWeather) (GeocodeResult, error) {
+	if len(w.NearestArea) == 0 {
+		return GeocodeResult{}, fmt.Errorf("no nearest_area in weather data; the location could not be resolved")
+	}
+	area := w.NearestArea[0]
+	return GeocodeResult{
+		Lat:     float64(area.Latitude),
+		Lon:     float64(area.Longitude),
+		Area:    area.AreaName.String(),
+		Region:  area.Region.String(),
+		Country: area.Country.String(),
+	}, nil
+}
+
+// areaDistance returns how far the nearest area wttr.in resolved a
+// "lat,lon" location to lies from the queried point, in kilometers. Place
+// name queries have no point to measure from.
+func areaDistance(location string, w DetailedWeather) (float64, bool) {
+	p, ok := parseCoordinates(location)
+	if !ok || len(w.NearestArea) == 0 {
+		return 0, false
+	}
+	area := w.NearestArea[0]
+	return haversine(p, Coordinates{Latitude: float64(area.Latitude), Longitude: float64(area.Longitude)}), true
+}
+
+// placeName labels a resolved place as "Area, Country".
+func (g GeocodeResult) placeName() string {
+	if g.Country == "" || g.Country == g.Area {
+		return g.Area
+	}
+	return g.Area + ", " + g.Country
+}
+
+// labelOneLiner replaces the location label before the first colon of a
+// one-liner, which wttr.in leaves blank or as raw coordinates for
+// coordinate queries.
+func labelOneLiner(line, label string) string {
+	_, rest, found := strings.Cut(line, ":")
+	if !found {
+		return label + ": " + strings.TrimSpace(line)
+	}
+	return label + ": " + strings.TrimSpace(rest)
+}
+
+// resolutionConfidence hints whether wttr.in resolved a place name to the
+// place the user meant: "high" when the resolved area name matches the
+// query (ignoring case, accents and punctuation, and anything after a
+// comma), contains it, or is within a typo of it; "low" otherwise.
+// Coordinates, ~landmark and @domain queries get no hint, since they are
+// not expected to match the area name.
+func resolutionConfidence(query, area string) string {
+	if coordinatePattern.MatchString(query) || strings.HasPrefix(query, "~") || strings.HasPrefix(query, "@") {
+		return ""
+	}
+	if i := strings.Index(query, ","); i >= 0 {
+		query = query[:i]
+	}
+
+	q, a := normalizeName(query), normalizeName(area)
+	if q == "" || a == "" {
+		return ""
+	}
+	if strings.Contains(a, q) || strings.Contains(q, a) {
+		return "high"
+	}
+
+	longest := len([]rune(q))
+	if n := len([]rune(a)); n > longest {
+		longest = n
+	}
+	if similarity := 1 - float64(levenshtein(q, a))/float64(longest); similarity >= 0.8 {
+		return "high"
+	}
+	return "low"
+}
+
+// normalizeName lowercases a place name and keeps only its letters and
+// digits, dropping accents from Latin letters.
+func normalizeName(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if folded, ok := accentFold[r]; ok {
+			r = folded
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// accentFold maps the common accented Latin letters to their base letter.
+var accentFold = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a',
+	'ç': 'c', 'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ñ': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ý': 'y', 'ÿ': 'y',
+}
+
+// levenshtein returns the edit distance between a and b in runes.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+// Geocode returns the coordinates and name of the place wttr.in resolves
+// the location to as JSON.
+func (c *WeatherClient) Geocode(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := geocode(w)
+	if err != nil {
+		return "", err
+	}
+	result.Confidence = resolutionConfidence(location, result.Area)
+	if d, ok := areaDistance(location, w); ok {
+		d = c.round(d)
+		result.DistanceKm = &d
+	}
+	return marshalResult(result)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGeocode(t *testing.T) {
+	w, err := parseDetailed(`{"nearest_area": [{
+		"areaName": [{"value": "London"}], "region": [{"value": "City of London, Greater London"}],
+		"country": [{"value": "United Kingdom"}], "latitude": "51.517", "longitude": "-0.106"
+	}]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := geocode(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := GeocodeResult{Lat: 51.517, Lon: -0.106, Area: "London", Region: "City of London, Greater London", Country: "United Kingdom"}
+	if result != want {
+		t.Errorf("got %+v, want %+v", result, want)
+	}
+}
+
+func TestGeocodeMissingArea(t *testing.T) {
+	if _, err := geocode(DetailedWeather{}); err == nil {
+		t.Fatal("expected error without nearest_area")
+	}
+}
+
+func TestWeatherClientGeocode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"current_condition": [{"temp_C": "15"}],
+			"nearest_area": [{"areaName": [{"value": "London"}], "country": [{"value": "United Kingdom"}], "latitude": "51.517", "longitude": "-0.106"}]
+		}`))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.Geocode("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != `{"lat":51.517,"lon":-0.106,"area":"London","country":"United Kingdom","confidence":"high"}` {
+		t.Errorf("unexpected result: %s", result)
+	}
+
+	// Coordinate queries report how far off the resolved area is.
+	client.precision = 1
+	result, err = client.Geocode("51.5,-0.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != `{"lat":51.517,"lon":-0.106,"area":"London","country":"United Kingdom","distance_km":1.9}` {
+		t.Errorf("unexpected result: %s", result)
+	}
+}
+
+func TestResolutionConfidence(t *testing.T) {
+	tests := []struct {
+		query, area, want string
+	}{
+		{"London", "London", "high"},
+		{"london, uk", "London", "high"},
+		{"Zurich", "Zürich", "high"},
+		{"Springfeld", "Springfield", "high"},
+		{"New York", "New York City", "high"},
+		{"Paris", "Lyon", "low"},
+		{"Bay Area", "Oakland", "low"},
+		{"51.5,-0.12", "London", ""},
+		{"~Eiffel Tower", "Paris", ""},
+	}
+
+	for _, tt := range tests {
+		if got := resolutionConfidence(tt.query, tt.area); got != tt.want {
+			t.Errorf("resolutionConfidence(%q, %q) = %q, want %q", tt.query, tt.area, got, tt.want)
+		}
+	}
+}
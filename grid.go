@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	// earthRadiusKm is the mean radius of the Earth.
+	earthRadiusKm = 6371.0
+
+	// defaultGridRadiusKm and maxGridRadiusKm bound how far the grid points
+	// are from the center; further out it is no longer the same area.
+	defaultGridRadiusKm = 5.0
+	maxGridRadiusKm     = 25.0
+
+	// maxGridPoints caps the upstream requests one grid costs, center
+	// included.
+	maxGridPoints = 9
+)
+
+// gridBearings are the compass directions sampled around the center, in
+// degrees clockwise from north.
+var gridBearings = []struct {
+	Name    string
+	Degrees float64
+}{
+	{"N", 0}, {"NE", 45}, {"E", 90}, {"SE", 135},
+	{"S", 180}, {"SW", 225}, {"W", 270}, {"NW", 315},
+}
+
+// offsetCoordinates returns the point distanceKm away from p along the
+// bearing, on a spherical Earth, rounded like antipode.
+func offsetCoordinates(p Coordinates, bearingDeg, distanceKm float64) Coordinates {
+	lat1 := p.Latitude * math.Pi / 180
+	lon1 := p.Longitude * math.Pi / 180
+	theta := bearingDeg * math.Pi / 180
+	d := distanceKm / earthRadiusKm
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(theta))
+	lon2 := lon1 + math.Atan2(math.Sin(theta)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+
+	lon := lon2 * 180 / math.Pi
+	if lon > 180 {
+		lon -= 360
+	} else if lon <= -180 {
+		lon += 360
+	}
+	return Coordinates{Latitude: roundTo(lat2*180/math.Pi, 4), Longitude: roundTo(lon, 4)}
+}
+
+// haversine returns the great-circle distance in kilometers between two
+// points on a spherical Earth.
+func haversine(a, b Coordinates) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(min(h, 1)))
+}
+
+// parseCoordinates returns the point a "lat,lon" location names.
+func parseCoordinates(location string) (Coordinates, bool) {
+	m := coordinatePattern.FindStringSubmatch(location)
+	if m == nil {
+		return Coordinates{}, false
+	}
+	lat, _ := strconv.ParseFloat(m[1], 64)
+	lon, _ := strconv.ParseFloat(m[2], 64)
+	return Coordinates{Latitude: lat, Longitude: lon}, true
+}
+
+// GridPoint is the weather at one point of an area grid.
+type GridPoint struct {
+	Bearing     string      `json:"bearing"`
+	Coordinates Coordinates `json:"coordinates"`
+	Name        string      `json:"name,omitempty"`
+	TempC       *float64    `json:"temp_c,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Error       string      `json:"error,omitempty"`
+}
+
+// AreaGrid is the weather at a center and the points around it. The
+// spread is the difference between the warmest and coldest points.
+type AreaGrid struct {
+	Center       Coordinates `json:"center"`
+	RadiusKm     float64     `json:"radius_km"`
+	Points       []GridPoint `json:"points"`
+	TempSpreadC  float64     `json:"temp_spread_c"`
+	WarmestPoint string      `json:"warmest_point,omitempty"`
+	ColdestPoint string      `json:"coldest_point,omitempty"`
+}
+
+// gridPoints returns the center followed by the points around it, at most
+// maxGridPoints in all.
+func gridPoints(center Coordinates, radiusKm float64) []GridPoint {
+	points := []GridPoint{{Bearing: "center", Coordinates: center}}
+	for _, b := range gridBearings {
+		if len(points) == maxGridPoints {
+			break
+		}
+		points = append(points, GridPoint{Bearing: b.Name, Coordinates: offsetCoordinates(center, b.Degrees, radiusKm)})
+	}
+	return points
+}
+
+// gridCenter resolves the center to coordinates: "lat,lon" is taken as is,
+// anything else is looked up through wttr.in.
+func (c *WeatherClient) gridCenter(center string) (Coordinates, error) {
+	if p, ok := parseCoordinates(center); ok {
+		return p, nil
+	}
+	w, err := c.fetchDetailed(center)
+	if err != nil {
+		return Coordinates{}, err
+	}
+	if len(w.NearestArea) == 0 {
+		return Coordinates{}, fmt.Errorf("could not resolve coordinates for %q", center)
+	}
+	area := w.NearestArea[0]
+	return Coordinates{Latitude: float64(area.Latitude), Longitude: float64(area.Longitude)}, nil
+}
+
+// GetAreaGrid samples the weather at the center and at points radiusKm
+// around it, fetched concurrently, and returns them as JSON. Points wttr.in
+// fails for are reported with their error rather than failing the grid.
+func (c *WeatherClient) GetAreaGrid(center string, radiusKm float64) (string, error) {
+	if radiusKm <= 0 || radiusKm > maxGridRadiusKm {
+		return "", invalidArgument("radius_km must be greater than 0 and at most %g", maxGridRadiusKm)
+	}
+	origin, err := c.gridCenter(center)
+	if err != nil {
+		return "", err
+	}
+
+	points := gridPoints(origin, radiusKm)
+	queries := make([]string, len(points))
+	for i, p := range points {
+		queries[i] = p.Coordinates.query()
+	}
+	results := fetchAll(queries, c.GetDetailed)
+
+	grid := AreaGrid{Center: origin, RadiusKm: radiusKm, Points: points}
+	var warmest, coldest *GridPoint
+	for i, r := range results {
+		p := &grid.Points[i]
+		if r.Err != nil {
+			p.Error = r.Err.Error()
+			continue
+		}
+		w, err := parseDetailed(r.Text)
+		if err != nil {
+			p.Error = err.Error()
+			continue
+		}
+		place := antipodePlace(w, p.Coordinates)
+		p.Name, p.TempC, p.Description = place.Name, place.TempC, place.Description
+		if p.TempC == nil {
+			continue
+		}
+		if warmest == nil || *p.TempC > *warmest.TempC {
+			warmest = p
+		}
+		if coldest == nil || *p.TempC < *coldest.TempC {
+			coldest = p
+		}
+	}
+	if warmest != nil {
+		grid.TempSpreadC = c.round(*warmest.TempC - *coldest.TempC)
+		grid.WarmestPoint, grid.ColdestPoint = warmest.Bearing, coldest.Bearing
+	}
+	return marshalResult(grid)
+}
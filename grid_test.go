@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOffsetCoordinates(t *testing.T) {
+	center := Coordinates{Latitude: 50.8225, Longitude: -0.1372}
+
+	north := offsetCoordinates(center, 0, 10)
+	if north.Longitude != center.Longitude || math.Abs(north.Latitude-center.Latitude-0.0899) > 0.0001 {
+		t.Errorf("10 km north = %v", north)
+	}
+
+	// A degree of longitude shrinks with the cosine of the latitude.
+	east := offsetCoordinates(center, 90, 10)
+	if want := 10 / (111.195 * math.Cos(center.Latitude*math.Pi/180)); math.Abs(east.Longitude-center.Longitude-want) > 0.0005 {
+		t.Errorf("10 km east = %v, want a longitude shift of %.4f", east, want)
+	}
+	if math.Abs(east.Latitude-center.Latitude) > 0.001 {
+		t.Errorf("10 km east moved the latitude: %v", east)
+	}
+
+	wrapped := offsetCoordinates(Coordinates{Latitude: 0, Longitude: 179.99}, 90, 10)
+	if wrapped.Longitude > -179.9 || wrapped.Longitude < -180 {
+		t.Errorf("expected the longitude to wrap past 180, got %v", wrapped)
+	}
+}
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Coordinates
+		want float64
+	}{
+		{"same point", Coordinates{51.5074, -0.1278}, Coordinates{51.5074, -0.1278}, 0},
+		{"London to Paris", Coordinates{51.5074, -0.1278}, Coordinates{48.8566, 2.3522}, 343.6},
+		{"New York to Los Angeles", Coordinates{40.7128, -74.0060}, Coordinates{34.0522, -118.2437}, 3935.7},
+		{"across the date line", Coordinates{0, 179.5}, Coordinates{0, -179.5}, 111.2},
+		{"antipodes", Coordinates{0, 0}, Coordinates{0, 180}, math.Pi * earthRadiusKm},
+	}
+	for _, tt := range tests {
+		if got := haversine(tt.a, tt.b); math.Abs(got-tt.want) > 0.5 {
+			t.Errorf("%s: got %.1f km, want %.1f", tt.name, got, tt.want)
+		}
+		if got, back := haversine(tt.a, tt.b), haversine(tt.b, tt.a); math.Abs(got-back) > 1e-9 {
+			t.Errorf("%s: not symmetric: %g vs %g", tt.name, got, back)
+		}
+	}
+}
+
+func TestGridPoints(t *testing.T) {
+	points := gridPoints(Coordinates{Latitude: 10, Longitude: 20}, 5)
+
+	if len(points) != maxGridPoints {
+		t.Fatalf("expected %d points, got %d", maxGridPoints, len(points))
+	}
+	if points[0].Bearing != "center" || points[0].Coordinates != (Coordinates{Latitude: 10, Longitude: 20}) {
+		t.Errorf("expected the center first, got %+v", points[0])
+	}
+	if points[1].Bearing != "N" || points[5].Bearing != "S" {
+		t.Errorf("unexpected bearings: %+v", points)
+	}
+}
+
+func TestWeatherClientGetAreaGrid(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		temp, desc := "17", "Sunny"
+		switch r.URL.Path {
+		case "/50.8225,-0.1372":
+			temp, desc = "15", "Light drizzle"
+		case "/50.7775,-0.1372":
+			// Due south of Brighton is the open sea.
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case "/50.8225,-0.2084":
+			temp = "19"
+		}
+		w.Write([]byte(`{"current_condition": [{"temp_C": "` + temp + `", "weatherDesc": [{"value": "` + desc + `"}]}]}`))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.GetAreaGrid("50.8225,-0.1372", 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != maxGridPoints {
+		t.Errorf("expected %d fetches, got %v", maxGridPoints, paths)
+	}
+
+	var grid AreaGrid
+	if err := json.Unmarshal([]byte(result), &grid); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(grid.Points) != maxGridPoints {
+		t.Fatalf("expected %d points, got %+v", maxGridPoints, grid.Points)
+	}
+	if p := grid.Points[0]; p.TempC == nil || *p.TempC != 15 || p.Description != "Light drizzle" {
+		t.Errorf("unexpected center: %+v", p)
+	}
+	if p := grid.Points[5]; p.Bearing != "S" || p.TempC != nil || !strings.Contains(p.Error, "404") {
+		t.Errorf("expected the south point to carry its error, got %+v", p)
+	}
+	if grid.TempSpreadC != 4 || grid.WarmestPoint != "W" || grid.ColdestPoint != "center" {
+		t.Errorf("unexpected spread: %g (%s to %s)", grid.TempSpreadC, grid.ColdestPoint, grid.WarmestPoint)
+	}
+}
+
+func TestWeatherClientGetAreaGridRadius(t *testing.T) {
+	client := &WeatherClient{}
+	for _, radius := range []float64{0, maxGridRadiusKm + 1} {
+		if _, err := client.GetAreaGrid("51.5,-0.12", radius); errorCode(err) != errorCodeInvalidArguments {
+			t.Errorf("radius %g: expected an invalid argument error, got %v", radius, err)
+		}
+	}
+}
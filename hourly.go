@@ -0,0 +1,18 @@
+package main
+
+// WithRawHourly returns a copy of the client whose day summaries carry the
+// parsed hourly slots they were derived from.
+func (c *WeatherClient) WithRawHourly() WeatherService {
+	raw := *c
+	raw.hourly = true
+	return &raw
+}
+
+// rawHourly returns the hourly slots of a forecast day for a parsed result,
+// or nil unless raw hourly data was requested.
+func (c *WeatherClient) rawHourly(d DayForecast) []HourlyWeather {
+	if !c.hourly {
+		return nil
+	}
+	return d.Hourly
+}
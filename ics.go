@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icsDateLayout  = "20060102"
+	icsStampLayout = "20060102T150405Z"
+
+	// icsLineLimit is the longest content line iCalendar allows, in octets,
+	// before it has to be folded.
+	icsLineLimit = 75
+)
+
+// icsUIDUnsafe matches what is dropped from a location to build event UIDs.
+var icsUIDUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// icsText escapes a TEXT property value.
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// foldICSLine splits a content line into CRLF-terminated lines of at most
+// icsLineLimit octets, continuation lines starting with a space. Lines are
+// only split between characters, never inside a multi-byte one.
+func foldICSLine(line string) string {
+	var b strings.Builder
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// The leading space counts towards the next line's limit.
+		limit = icsLineLimit - 1
+	}
+	b.WriteString(line + "\r\n")
+	return b.String()
+}
+
+// renderForecastICS renders forecast days as an iCalendar feed with one
+// all-day VEVENT per day. Days whose date cannot be parsed are skipped.
+func renderForecastICS(days []DayForecast, location string) string {
+	slug := strings.Trim(icsUIDUnsafe.ReplaceAllString(strings.ToLower(location), "-"), "-")
+	stamp := time.Now().UTC().Format(icsStampLayout)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//wttr-weather-mcp//Forecast//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:" + icsText("Weather in "+location),
+	}
+	for _, d := range days {
+		date, err := time.Parse(j1DateLayout, d.Date)
+		if err != nil {
+			continue
+		}
+		day := summarizeDay(d)
+		summary := fmt.Sprintf("High %g°C / Low %g°C", day.MaxTempC, day.MinTempC)
+		if day.Description != "" {
+			summary += ", " + day.Description
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%s-%s@wttr-weather-mcp", date.Format(icsDateLayout), slug),
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+date.Format(icsDateLayout),
+			"DTEND;VALUE=DATE:"+date.AddDate(0, 0, 1).Format(icsDateLayout),
+			"SUMMARY:"+icsText(summary),
+			"DESCRIPTION:"+icsText(fmt.Sprintf("%s in %s. Chance of rain: %d%%.", summary, location, day.ChanceOfRain)),
+			"LOCATION:"+icsText(location),
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(foldICSLine(line))
+	}
+	return b.String()
+}
+
+// GetForecastICS returns the daily forecast for the given number of days
+// as an iCalendar feed.
+func (c *WeatherClient) GetForecastICS(location string, days int) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	return renderForecastICS(w.Weather[:min(max(days, 1), len(w.Weather))], location), nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func icsFixture() []DayForecast {
+	return []DayForecast{
+		{Date: "2024-06-12", MinTempC: 14, MaxTempC: 24, Hourly: []HourlyWeather{{Time: 1200, ChanceOfRain: 10, WeatherDesc: text{{Value: "Sunny"}}}}},
+		{Date: "2024-06-13", MinTempC: 13, MaxTempC: 19, Hourly: []HourlyWeather{{Time: 1200, ChanceOfRain: 80, WeatherDesc: text{{Value: "Light rain"}}}}},
+		{Date: "2024-06-14", MinTempC: 12, MaxTempC: 21, Hourly: []HourlyWeather{{Time: 1200, WeatherDesc: text{{Value: "Partly cloudy"}}}}},
+	}
+}
+
+func TestRenderForecastICS(t *testing.T) {
+	ics := renderForecastICS(icsFixture(), "New York")
+
+	if !strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(ics, "END:VCALENDAR\r\n") {
+		t.Errorf("expected a VCALENDAR wrapper, got:\n%s", ics)
+	}
+	if n := strings.Count(ics, "BEGIN:VEVENT\r\n"); n != 3 {
+		t.Errorf("expected 3 events, got %d", n)
+	}
+	if n := strings.Count(ics, "END:VEVENT\r\n"); n != 3 {
+		t.Errorf("expected 3 closed events, got %d", n)
+	}
+	for _, want := range []string{
+		"UID:20240612-new-york@wttr-weather-mcp\r\n",
+		"DTSTART;VALUE=DATE:20240612\r\nDTEND;VALUE=DATE:20240613\r\n",
+		`SUMMARY:High 24°C / Low 14°C\, Sunny` + "\r\n",
+		"DTSTART;VALUE=DATE:20240614\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("expected %q in:\n%s", want, ics)
+		}
+	}
+}
+
+func TestFoldICSLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("°C ", 40)
+	folded := foldICSLine(line)
+
+	parts := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+	if len(parts) < 2 {
+		t.Fatalf("expected the line to be folded, got %q", folded)
+	}
+	var unfolded strings.Builder
+	for i, part := range parts {
+		if len(part) > icsLineLimit {
+			t.Errorf("line %d is %d octets long", i, len(part))
+		}
+		if i > 0 {
+			if !strings.HasPrefix(part, " ") {
+				t.Errorf("continuation line %d does not start with a space: %q", i, part)
+			}
+			part = part[1:]
+		}
+		unfolded.WriteString(part)
+	}
+	if unfolded.String() != line {
+		t.Errorf("unfolding did not restore the line:\n got %q\nwant %q", unfolded.String(), line)
+	}
+}
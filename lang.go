@@ -0,0 +1,77 @@
+package main
+
+import "strings"
+
+// defaultForecastLang is the language of text forecasts unless one is
+// detected from the location.
+const defaultForecastLang = "ru"
+
+// countryLangs maps the country names wttr.in reports in nearest_area to
+// the wttr.in language code spoken there.
+var countryLangs = map[string]string{
+	"Argentina":                "es",
+	"Austria":                  "de",
+	"Belarus":                  "be",
+	"Belgium":                  "nl",
+	"Brazil":                   "pt-br",
+	"Bulgaria":                 "bg",
+	"Canada":                   "en",
+	"China":                    "zh",
+	"Croatia":                  "hr",
+	"Czech Republic":           "cs",
+	"Denmark":                  "da",
+	"Finland":                  "fi",
+	"France":                   "fr",
+	"Germany":                  "de",
+	"Greece":                   "el",
+	"Hungary":                  "hu",
+	"Italy":                    "it",
+	"Japan":                    "ja",
+	"Mexico":                   "es",
+	"Netherlands":              "nl",
+	"Norway":                   "nb",
+	"Poland":                   "pl",
+	"Portugal":                 "pt",
+	"Romania":                  "ro",
+	"Russia":                   "ru",
+	"Serbia":                   "sr",
+	"Slovakia":                 "sk",
+	"South Korea":              "ko",
+	"Spain":                    "es",
+	"Sweden":                   "sv",
+	"Switzerland":              "de",
+	"Turkey":                   "tr",
+	"Ukraine":                  "uk",
+	"United Kingdom":           "en",
+	"United States of America": "en",
+}
+
+// forecastLang picks the forecast language for location: the language of
+// the country wttr.in resolves it to, if that language is in the allow-list,
+// and defaultForecastLang otherwise. Without an allow-list nothing is
+// resolved.
+func (c *WeatherClient) forecastLang(location string) (string, error) {
+	if len(c.autoLangs) == 0 {
+		return defaultForecastLang, nil
+	}
+
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	place, err := geocode(w)
+	if err != nil {
+		return "", err
+	}
+
+	lang, ok := countryLangs[place.Country]
+	if !ok {
+		return defaultForecastLang, nil
+	}
+	for _, allowed := range c.autoLangs {
+		if strings.EqualFold(allowed, lang) {
+			return lang, nil
+		}
+	}
+	return defaultForecastLang, nil
+}
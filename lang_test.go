@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// langServer resolves every location to country and records the query of
+// the text forecast request.
+func langServer(t *testing.T, country string, forecastQuery *string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery == "format=j1" {
+			w.Write([]byte(`{"nearest_area": [{"areaName": [{"value": "Lyon"}], "country": [{"value": "` + country + `"}]}]}`))
+			return
+		}
+		*forecastQuery = r.URL.RawQuery
+		w.Write([]byte("forecast"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetForecastDetectsLang(t *testing.T) {
+	var query string
+	srv := langServer(t, "France", &query)
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL, autoLangs: []string{"fr", "de"}}
+
+	if _, err := client.GetForecast("Lyon", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "2&lang=fr" {
+		t.Errorf("expected French forecast, got query %q", query)
+	}
+}
+
+func TestGetForecastLangNotAllowed(t *testing.T) {
+	var query string
+	srv := langServer(t, "Japan", &query)
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL, autoLangs: []string{"fr", "de"}}
+
+	if _, err := client.GetForecast("Osaka", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(query, "lang=ru") {
+		t.Errorf("expected the default language for a locale outside the allow-list, got query %q", query)
+	}
+}
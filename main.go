@@ -3,17 +3,66 @@ package main
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	serverName    = "wttr-weather"
 	serverVersion = "1.0.0"
 
-	toolGetCurrent  = "get_current_weather"
-	toolGetForecast = "get_forecast"
-	toolGetDetailed = "get_weather_detailed"
+	toolGetCurrent      = "get_current_weather"
+	toolGetForecast     = "get_forecast"
+	toolGetDetailed     = "get_weather_detailed"
+	toolGetScore        = "get_weather_score"
+	toolGetWeekend      = "get_weekend"
+	toolGetSunSafety    = "get_sun_safety"
+	toolGetDaylight     = "get_daylight"
+	toolGetProfiles     = "get_profiles_weather"
+	toolGetNowcast      = "get_nowcast"
+	toolGetDaySegments  = "get_day_segments"
+	toolGetDebugHeaders = "get_debug_headers"
+	toolGetCommute      = "get_commute"
+	toolGetWind         = "get_wind_forecast"
+	toolGetActivity     = "get_activity_suitability"
+	toolGetExtremes     = "get_temp_extremes"
+	toolGetOnThisDay    = "get_on_this_day"
+	toolGetAntipode     = "get_antipode_weather"
+	toolGeocode         = "geocode"
+	toolGetOverview     = "get_weather_overview"
+	toolGetDayBand      = "get_day_band"
+	toolGetRaw          = "get_raw"
+	toolGetDayDelta     = "get_day_delta"
+	toolGetMoonPhase    = "get_moon_phase"
+	toolGetSSML         = "get_weather_ssml"
+	toolGetCard         = "get_weather_card"
+	toolGetPrecip       = "get_precip_forecast"
+	toolGetTomorrow     = "get_tomorrow"
+	toolGetShortTrend   = "get_short_trend"
+	toolGetBriefing     = "get_briefing"
+	toolGetAreaGrid     = "get_area_grid"
+	toolGetByCode       = "get_weather_by_code"
+	toolGetForecastICS  = "get_forecast_ics"
+	toolGetWeekStrip    = "get_week_strip"
+	toolGetBestDay      = "get_best_day"
+	toolGetEvents       = "get_events_weather"
+	toolGetComfort      = "get_comfort"
+	toolGetOutlook      = "get_outlook"
+	toolRankByTemp      = "rank_by_temperature"
+	toolGetThreshold    = "get_threshold_check"
+	toolGetFlight       = "get_flight_weather"
+	toolGetEmoji        = "get_emoji_forecast"
+	toolGetA11y         = "get_weather_a11y"
+	toolGetSnapshot     = "get_snapshot"
+	toolGetNicest       = "get_nicest_window"
 )
 
 type JSONRPCRequest struct {
@@ -23,47 +72,169 @@ type JSONRPCRequest struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 }
 
+// JSONRPCResponse is a response to a request. ID is always serialized:
+// JSON-RPC requires "id": null when the request's id could not be read, as
+// for parse errors.
 type JSONRPCResponse struct {
 	JSONRPC string      `json:"jsonrpc"`
-	ID      interface{} `json:"id,omitempty"`
+	ID      interface{} `json:"id"`
 	Result  interface{} `json:"result,omitempty"`
 	Error   *RPCError   `json:"error,omitempty"`
 }
 
 type RPCError struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Data    interface{}            `json:"data,omitempty"`
+	Meta    map[string]interface{} `json:"_meta,omitempty"`
 }
 
 type WeatherService interface {
-	GetCurrent(location string) (string, error)
-	GetForecast(location string, days int) (string, error)
+	GetCurrent(location string, fields ...string) (string, error)
+	GetForecast(location string, days int, options ...string) (string, error)
+	GetForecastSummary(location string, days int, format string) (string, error)
 	GetDetailed(location string) (string, error)
+	GetWeatherScore(location string) (string, error)
+	GetWeekend(location string) (string, error)
+	GetSunSafety(location string) (string, error)
+	GetDaylight(location string) (string, error)
+	GetNowcast(location string, minutes int) (string, error)
+	GetOnThisDay(location string) (string, error)
+	GetDaySegments(location string, dayOffset int) (string, error)
+	GetDebugHeaders(location string) (string, error)
+	GetCommute(location string, morningHour, eveningHour int) (string, error)
+	GetWindForecast(location string, dayOffset int) (string, error)
+	GetActivitySuitability(location, activity string) (string, error)
+	GetTempExtremes(location string, dayOffset int) (string, error)
+	GetAntipodeWeather(location string) (string, error)
+	Geocode(location string) (string, error)
+	GetOverview(location string) (string, error)
+	GetDayBand(location string) (string, error)
+	GetRaw(location, rawQuery string) (string, error)
+	GetDayDelta(location string, dayA, dayB int) (string, error)
+	GetWeatherSSML(location string) (string, error)
+	GetWeatherCard(location string) (string, error)
+	GetTomorrow(location string) (string, error)
+	GetPrecipForecast(location string, days int) (string, error)
+	GetShortTrend(location string) (string, error)
+	GetBriefing(location string) (string, error)
+	GetAreaGrid(center string, radiusKm float64) (string, error)
+	GetWeatherByCode(code string) (string, error)
+	GetForecastICS(location string, days int) (string, error)
+	GetWeekStrip(location string) (string, error)
+	GetBestDay(location, activity string) (string, error)
+	GetEventsWeather(events []Event) (string, error)
+	GetComfort(location string) (string, error)
+	GetOutlook(location string) (string, error)
+	RankByTemperature(locations []string) (string, error)
+	GetThresholdCheck(location string, threshold float64, direction string) (string, error)
+	GetFlightWeather(origin, dest string) (string, error)
+	GetEmojiForecast(location string) (string, error)
+	GetWeatherA11y(location string) (string, error)
+	GetSnapshot(location string) (string, error)
+	GetNicestWindow(location string) (string, error)
+
+	// WithTrace returns a service that records its upstream fetches in trace.
+	WithTrace(trace *FetchTrace) WeatherService
+	// WithTimezone returns a service that renders times in loc.
+	WithTimezone(loc *time.Location) WeatherService
+
+	// WithEpochTimes returns a service that renders times as Unix seconds.
+	WithEpochTimes() WeatherService
+
+	// WithBothUnits returns a service that adds Fahrenheit temperatures.
+	WithBothUnits() WeatherService
+
+	// WithRawHourly returns a service that adds the parsed hourly slots to
+	// day summaries.
+	WithRawHourly() WeatherService
 }
 
 type Server struct {
 	weather WeatherService
+	config  Config
+
+	// out receives the responses; nil means stdout.
+	out io.Writer
+
+	requestLog *requestLog
 }
 
 func main() {
-	server := &Server{weather: NewWeatherClient()}
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	server := &Server{weather: NewWeatherClient(cfg), config: cfg}
+
+	if cfg.RequestLogPath != "" {
+		server.requestLog, err = newRequestLog(cfg.RequestLogPath, cfg.RequestLogMaxBytes)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer server.requestLog.Close()
+	}
+
 	server.run()
 }
 
 func (s *Server) run() {
-	scanner := bufio.NewScanner(os.Stdin)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
+	s.serve(os.Stdin)
+}
+
+// serve decodes successive JSON-RPC messages from r until it is exhausted.
+// Messages are not required to be one per line: pretty-printed requests
+// spanning several lines and several requests on one line both work.
+func (s *Server) serve(r io.Reader) {
+	limit := &messageLimit{r: r}
+	dec := json.NewDecoder(limit)
+
+	for {
+		limit.reset(s.config.maxMessageBytes())
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			return
+		}
+		if errors.Is(err, errMessageTooLarge) {
+			s.sendError(nil, -32600, "Invalid Request", fmt.Sprintf("message exceeds %d bytes", s.config.maxMessageBytes()))
+
+			// Skip the rest of the oversized line without buffering it, then
+			// resume decoding after it.
+			rest := bufio.NewReader(limit.r)
+			if !skipLine(rest) {
+				return
+			}
+			limit = &messageLimit{r: rest}
+			dec = json.NewDecoder(limit)
+			continue
+		}
+		if err != nil {
+			var syntaxErr *json.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				// Truncated input or a read error; nothing more can be decoded.
+				if err == io.ErrUnexpectedEOF {
+					s.sendError(nil, -32700, "Parse error", err.Error())
+				}
+				return
+			}
 
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
+			s.sendError(nil, -32700, "Parse error", err.Error())
+
+			// The decoder cannot continue past a syntax error. Drop the rest
+			// of the offending line and resume decoding after it.
+			rest := bufio.NewReader(io.MultiReader(dec.Buffered(), limit.r))
+			rest.ReadString('\n')
+			limit = &messageLimit{r: rest}
+			dec = json.NewDecoder(limit)
 			continue
 		}
 
 		var req JSONRPCRequest
-		if err := json.Unmarshal(line, &req); err != nil {
+		if err := json.Unmarshal(raw, &req); err != nil {
 			s.sendError(nil, -32700, "Parse error", err.Error())
 			continue
 		}
@@ -75,9 +246,56 @@ func (s *Server) run() {
 	}
 }
 
+// errMessageTooLarge is returned by messageLimit once a message has used
+// up its budget.
+var errMessageTooLarge = errors.New("message too large")
+
+// messageLimit caps how much the decoder reads for one message, so a huge
+// or never-ending value isn't buffered whole. The budget is reset before
+// each message.
+type messageLimit struct {
+	r io.Reader
+	n int64
+}
+
+func (l *messageLimit) reset(n int64) {
+	l.n = n
+}
+
+func (l *messageLimit) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, errMessageTooLarge
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// skipLine discards input up to and including the next newline, a buffer
+// at a time. It reports false when the input ends first.
+func skipLine(r *bufio.Reader) bool {
+	for {
+		_, err := r.ReadSlice('\n')
+		if err == nil {
+			return true
+		}
+		if err != bufio.ErrBufferFull {
+			return false
+		}
+	}
+}
+
 func (s *Server) sendResponse(resp *JSONRPCResponse) {
+	out := s.out
+	if out == nil {
+		out = os.Stdout
+	}
+
 	data, _ := json.Marshal(resp)
-	fmt.Println(string(data))
+	fmt.Fprintln(out, string(data))
 }
 
 func (s *Server) sendError(id interface{}, code int, message string, data interface{}) {
@@ -94,6 +312,16 @@ func (s *Server) sendError(id interface{}, code int, message string, data interf
 }
 
 func (s *Server) handleRequest(req JSONRPCRequest) *JSONRPCResponse {
+	start := time.Now()
+	resp := s.dispatch(req)
+
+	if s.requestLog != nil {
+		s.requestLog.Log(newRequestLogEntry(req, resp, start))
+	}
+	return resp
+}
+
+func (s *Server) dispatch(req JSONRPCRequest) *JSONRPCResponse {
 	switch req.Method {
 	case "initialize":
 		return s.handleInitialize(req)
@@ -103,6 +331,12 @@ func (s *Server) handleRequest(req JSONRPCRequest) *JSONRPCResponse {
 		return s.handleToolsList(req)
 	case "tools/call":
 		return s.handleToolsCall(req)
+	case "resources/list":
+		return s.handleResourcesList(req)
+	case "resources/templates/list":
+		return s.handleResourceTemplatesList(req)
+	case "resources/read":
+		return s.handleResourcesRead(req)
 	default:
 		return &JSONRPCResponse{
 			JSONRPC: "2.0",
@@ -116,6 +350,10 @@ func (s *Server) handleRequest(req JSONRPCRequest) *JSONRPCResponse {
 }
 
 func (s *Server) handleInitialize(req JSONRPCRequest) *JSONRPCResponse {
+	if s.config.WarmupLocation != "" {
+		go s.warmUp(s.config.WarmupLocation)
+	}
+
 	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      req.ID,
@@ -126,13 +364,31 @@ func (s *Server) handleInitialize(req JSONRPCRequest) *JSONRPCResponse {
 				"version": serverVersion,
 			},
 			"capabilities": map[string]interface{}{
-				"tools": map[string]interface{}{},
+				"tools":     map[string]interface{}{},
+				"resources": map[string]interface{}{},
 			},
 		},
 	}
 }
 
+// warmUp performs a throwaway fetch so the first real tool call reuses an
+// established connection. Failures are ignored; they will surface on that call.
+func (s *Server) warmUp(location string) {
+	s.weather.GetCurrent(location)
+}
+
 func (s *Server) handleToolsList(req JSONRPCRequest) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"tools": s.tools(),
+		},
+	}
+}
+
+// tools returns the definitions of the available tools, sorted by name.
+func (s *Server) tools() []map[string]interface{} {
 	tools := []map[string]interface{}{
 		{
 			"name":        toolGetCurrent,
@@ -144,13 +400,25 @@ func (s *Server) handleToolsList(req JSONRPCRequest) *JSONRPCResponse {
 						"type":        "string",
 						"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
 					},
+					"fields": map[string]interface{}{
+						"type":        "array",
+						"description": "Extra fields to append to the one-liner, in order",
+						"items": map[string]interface{}{
+							"type": "string",
+							"enum": currentFieldNames(),
+						},
+					},
+					"resolve_name": map[string]interface{}{
+						"type":        "boolean",
+						"description": "For lat,lon locations, label the summary with the nearest named place instead of the coordinates (one extra request)",
+					},
 				},
 				"required": []string{"location"},
 			},
 		},
 		{
 			"name":        toolGetForecast,
-			"description": "Get weather forecast for a location (text format with ASCII art)",
+			"description": "Get weather forecast for a location (text format with ASCII art, or JSON or a Markdown table)",
 			"inputSchema": map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -160,10 +428,23 @@ func (s *Server) handleToolsList(req JSONRPCRequest) *JSONRPCResponse {
 					},
 					"days": map[string]interface{}{
 						"type":        "integer",
-						"description": "Number of forecast days (1-3, default: 3)",
-						"default":     3,
-						"minimum":     1,
-						"maximum":     3,
+						"description": fmt.Sprintf("Number of forecast days (0-3, default: %d); 0 is today only, without the day panels", s.config.forecastDays()),
+						"default":     s.config.forecastDays(),
+						"minimum":     0,
+						"maximum":     maxForecastDays,
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"description": "Rendering: ascii (wttr.in's text forecast), json or markdown (default: ascii unless configured otherwise)",
+						"enum":        forecastFormats,
+					},
+					"options": map[string]interface{}{
+						"type":        "array",
+						"description": "Display options for the ascii rendering, rejected with the other formats: narrow (day and night only), plain (no color sequences), quiet (no caption)",
+						"items": map[string]interface{}{
+							"type": "string",
+							"enum": forecastOptionNames(),
+						},
 					},
 				},
 				"required": []string{"location"},
@@ -179,140 +460,1723 @@ func (s *Server) handleToolsList(req JSONRPCRequest) *JSONRPCResponse {
 						"type":        "string",
 						"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
 					},
+					"fields": map[string]interface{}{
+						"type":        "array",
+						"description": "Only return these current condition fields, by their wttr.in names (e.g. [\"temp_C\", \"humidity\", \"windspeedKmph\"]); unknown names are listed in unknown_fields",
+						"items":       map[string]interface{}{"type": "string"},
+					},
 				},
 				"required": []string{"location"},
 			},
 		},
-	}
-
-	return &JSONRPCResponse{
-		JSONRPC: "2.0",
-		ID:      req.ID,
-		Result: map[string]interface{}{
-			"tools": tools,
+		{
+			"name":        toolGetScore,
+			"description": "Get a 0-100 weather \"niceness\" score for a location, with its temperature, precipitation, wind and visibility components",
+			"inputSchema": locationOnlySchema(),
 		},
-	}
-}
-
-func (s *Server) handleToolsCall(req JSONRPCRequest) *JSONRPCResponse {
-	var params struct {
-		Name      string          `json:"name"`
-		Arguments json.RawMessage `json:"arguments"`
-	}
-
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		return &JSONRPCResponse{
-			JSONRPC: "2.0",
-			ID:      req.ID,
-			Error: &RPCError{
-				Code:    -32602,
-				Message: "Invalid params",
-				Data:    err.Error(),
+		{
+			"name":        toolGetWeekend,
+			"description": "Get the forecast for the upcoming Saturday and Sunday, or a note if the weekend is beyond the 3-day forecast",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetSunSafety,
+			"description": "Get sun protection advice for a location combining UV index, cloud cover and time of day, with an estimated safe exposure time",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetDaylight,
+			"description": "Get today's sunrise, sunset and day length for a location, and whether days are lengthening or shortening",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetNowcast,
+			"description": "Estimate whether it will rain at a location in the next N minutes (up to 180), interpolated from the 3-hourly forecast",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
+					},
+					"minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Length of the window in minutes (1-180, default: 60)",
+						"default":     defaultNowcastMinutes,
+						"minimum":     1,
+						"maximum":     maxNowcastMinutes,
+					},
+				},
+				"required": []string{"location"},
 			},
-		}
-	}
-
-	switch params.Name {
-	case toolGetCurrent:
-		return s.callGetCurrent(req.ID, params.Arguments)
-	case toolGetForecast:
-		return s.callGetForecast(req.ID, params.Arguments)
-	case toolGetDetailed:
-		return s.callGetDetailed(req.ID, params.Arguments)
-	default:
-		return &JSONRPCResponse{
-			JSONRPC: "2.0",
-			ID:      req.ID,
-			Error: &RPCError{
-				Code:    -32602,
-				Message: "Unknown tool: " + params.Name,
+		},
+		{
+			"name":        toolGetDaySegments,
+			"description": "Get the morning, noon, evening and night forecast for a day at a location",
+			"inputSchema": dayOffsetSchema(),
+		},
+		{
+			"name":        toolGetCommute,
+			"description": "Get today's conditions for the morning and evening commute at a location: temperature, chance of rain and wind",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
+					},
+					"morning_hour": map[string]interface{}{
+						"type":        "integer",
+						"description": "Hour of the morning commute (0-23, default: 8), snapped to the nearest 3-hourly forecast slot",
+						"default":     defaultMorningHour,
+						"minimum":     0,
+						"maximum":     23,
+					},
+					"evening_hour": map[string]interface{}{
+						"type":        "integer",
+						"description": "Hour of the evening commute (0-23, default: 18), snapped to the nearest 3-hourly forecast slot",
+						"default":     defaultEveningHour,
+						"minimum":     0,
+						"maximum":     23,
+					},
+				},
+				"required": []string{"location"},
 			},
-		}
-	}
-}
-
-func (s *Server) callGetCurrent(id interface{}, args json.RawMessage) *JSONRPCResponse {
-	var input struct {
-		Location string `json:"location"`
-	}
-
-	if err := json.Unmarshal(args, &input); err != nil {
-		return s.paramError(id, "Invalid arguments", err.Error())
-	}
-
-	if input.Location == "" {
-		return s.paramError(id, "location is required", nil)
-	}
-
-	result, err := s.weather.GetCurrent(input.Location)
-	if err != nil {
-		return s.errorResponse(id, err)
-	}
-
-	return s.successResponse(id, result)
-}
-
-func (s *Server) callGetForecast(id interface{}, args json.RawMessage) *JSONRPCResponse {
-	var input struct {
-		Location string `json:"location"`
-		Days     int    `json:"days"`
-	}
-	input.Days = 3
-
-	if err := json.Unmarshal(args, &input); err != nil {
-		return s.paramError(id, "Invalid arguments", err.Error())
-	}
-
-	if input.Location == "" {
-		return s.paramError(id, "location is required", nil)
-	}
-
-	if input.Days < 1 || input.Days > 3 {
-		input.Days = 3
-	}
-
-	result, err := s.weather.GetForecast(input.Location, input.Days)
-	if err != nil {
-		return s.errorResponse(id, err)
-	}
-
-	return s.successResponse(id, result)
-}
-
-func (s *Server) callGetDetailed(id interface{}, args json.RawMessage) *JSONRPCResponse {
-	var input struct {
-		Location string `json:"location"`
-	}
-
-	if err := json.Unmarshal(args, &input); err != nil {
-		return s.paramError(id, "Invalid arguments", err.Error())
-	}
-
-	if input.Location == "" {
-		return s.paramError(id, "location is required", nil)
-	}
-
-	result, err := s.weather.GetDetailed(input.Location)
-	if err != nil {
-		return s.errorResponse(id, err)
-	}
-
-	return s.successResponse(id, result)
-}
-
-func (s *Server) successResponse(id interface{}, text string) *JSONRPCResponse {
-	return &JSONRPCResponse{
-		JSONRPC: "2.0",
-		ID:      id,
-		Result: map[string]interface{}{
-			"content": []map[string]string{
-				{"type": "text", "text": text},
+		},
+		{
+			"name":        toolGetWind,
+			"description": "Get the wind speed, gusts and direction through a day at a location, for kiting, sailing and other wind sports",
+			"inputSchema": dayOffsetSchema(),
+		},
+		{
+			"name":        toolGetActivity,
+			"description": "Judge whether the current conditions and today's forecast suit an activity such as running, cycling or hiking, with reasons",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
+					},
+					"activity": map[string]interface{}{
+						"type":        "string",
+						"description": "Activity to judge the weather for",
+						"enum":        activityNames(),
+					},
+				},
+				"required": []string{"location", "activity"},
 			},
 		},
-	}
-}
-
-func (s *Server) errorResponse(id interface{}, err error) *JSONRPCResponse {
+		{
+			"name":        toolGetExtremes,
+			"description": "Get the coldest and warmest time of a day at a location, with their temperatures",
+			"inputSchema": dayOffsetSchema(),
+		},
+		{
+			"name":        toolGetOnThisDay,
+			"description": "Compare today's temperature at a location with the same day in prior years. Requires a configured historical archive.",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetAntipode,
+			"description": "Get the weather at a location and at its antipode, the point on the exact opposite side of the globe",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGeocode,
+			"description": "Resolve a location to coordinates, area name and country, without the weather data",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetOverview,
+			"description": "Get a readable one-line summary together with the structured JSON weather data for a location, fetched concurrently",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetDayBand,
+			"description": "Get today's minimum and maximum temperature and the spread between them, noting large swings, for deciding what to wear",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetDayDelta,
+			"description": "Compare two forecast days at a location: how much the maximum and minimum temperatures differ and whether conditions change",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
+					},
+					"day_a": map[string]interface{}{
+						"type":        "integer",
+						"description": "Day to compare from: 0 is today, 1 tomorrow, 2 the day after",
+						"minimum":     0,
+						"maximum":     maxDayOffset,
+					},
+					"day_b": map[string]interface{}{
+						"type":        "integer",
+						"description": "Day to compare to; positive deltas mean it is warmer than day_a",
+						"minimum":     0,
+						"maximum":     maxDayOffset,
+					},
+				},
+				"required": []string{"location", "day_a", "day_b"},
+			},
+		},
+		{
+			"name":        toolGetPrecip,
+			"description": "Get the chance, type (rain or snow) and amount of precipitation for each forecast day at a location",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of forecast days (1-3, default: 3)",
+						"default":     3,
+						"minimum":     1,
+						"maximum":     3,
+					},
+				},
+				"required": []string{"location"},
+			},
+		},
+		{
+			"name":        toolGetMoonPhase,
+			"description": "Get the moon phase, illuminated percentage and date of the next full moon for a date, computed locally",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"date": map[string]interface{}{
+						"type":        "string",
+						"description": "Date as YYYY-MM-DD (default: today), taken at noon UTC",
+					},
+				},
+			},
+		},
+		{
+			"name":        toolGetRaw,
+			"description": "Fetch a location from wttr.in with a raw query string, for wttr.in options the other tools don't cover. Returns the response unprocessed",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
+					},
+					"raw_query": map[string]interface{}{
+						"type":        "string",
+						"description": "wttr.in query string appended to the location, e.g. \"format=%l:+%m&lang=de\" (letters, digits and = & % . _ + , : ~ - only, at most 200 characters)",
+					},
+				},
+				"required": []string{"location", "raw_query"},
+			},
+		},
+		{
+			"name":        toolGetSSML,
+			"description": "Get the current weather at a location as a short SSML snippet for text-to-speech, with pauses and units spelled out",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetCard,
+			"description": "Get a compact three-line weather card for a location (place, temperature, wind and humidity) for pasting into chat",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetTomorrow,
+			"description": "Get tomorrow's forecast summary for a location: minimum and maximum temperature, conditions and chance of rain",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetShortTrend,
+			"description": "Get whether temperature and pressure at a location are rising or falling, comparing the observations of the last hour (needs the response cache)",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetBriefing,
+			"description": "Get a one-paragraph weather briefing for a location: current conditions, today's high and low, the rain outlook and a preview of tomorrow",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetAreaGrid,
+			"description": "Get current conditions at a location and at points around it, showing local variation such as coast versus inland",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "Center of the grid: a city or location name, or \"lat,lon\"",
+					},
+					"radius_km": map[string]interface{}{
+						"type":             "number",
+						"description":      "Distance from the center to the surrounding points in kilometers",
+						"exclusiveMinimum": 0,
+						"maximum":          maxGridRadiusKm,
+						"default":          defaultGridRadiusKm,
+					},
+				},
+				"required": []string{"location"},
+			},
+		},
+		{
+			"name":        toolGetByCode,
+			"description": "Get current weather for a US telephone area code (e.g. \"212\") or a common city abbreviation (e.g. \"NYC\"), labeled with the city it resolves to",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"code": map[string]interface{}{
+						"type":        "string",
+						"description": "Area code or city abbreviation",
+					},
+				},
+				"required": []string{"code"},
+			},
+		},
+		{
+			"name":        toolGetForecastICS,
+			"description": "Get the daily forecast for a location as an iCalendar feed, one all-day event per day with the high, low and conditions",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of forecast days (1-3, default: 3)",
+						"default":     3,
+						"minimum":     1,
+						"maximum":     3,
+					},
+				},
+				"required": []string{"location"},
+			},
+		},
+		{
+			"name":        toolGetWeekStrip,
+			"description": "Get a compact strip of the forecast days at a location: one condition icon and the maximum temperature per day",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetBestDay,
+			"description": "Find the upcoming forecast day that suits an activity such as running, cycling or hiking best, with each day's score and reasons",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
+					},
+					"activity": map[string]interface{}{
+						"type":        "string",
+						"description": "Activity to find the best day for",
+						"enum":        activityNames(),
+					},
+				},
+				"required": []string{"location", "activity"},
+			},
+		},
+		{
+			"name":        toolGetComfort,
+			"description": "Get how comfortable the current conditions at a location feel: comfortable, humid, hot, chilly or windy, from temperature, humidity (humidex) and wind (wind chill), with a short explanation",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetEvents,
+			"description": "Get the forecast for calendar events: the temperature, conditions and chance of rain at each event's location and start time, snapped to the 3-hourly forecast slots",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"events": map[string]interface{}{
+						"type":        "array",
+						"description": "Events to forecast, at most 20",
+						"maxItems":    maxEvents,
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"title": map[string]interface{}{
+									"type": "string",
+								},
+								"location": map[string]interface{}{
+									"type":        "string",
+									"description": "City or location name",
+								},
+								"datetime": map[string]interface{}{
+									"type":        "string",
+									"description": "Start time as YYYY-MM-DDTHH:MM, local time at the location",
+								},
+							},
+							"required": []string{"location", "datetime"},
+						},
+					},
+				},
+				"required": []string{"events"},
+			},
+		},
+		{
+			"name":        toolGetOutlook,
+			"description": "Get whether the weather at a location is improving or worsening over the next nine hours, comparing the current conditions with the upcoming forecast slots, with reasons",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolRankByTemp,
+			"description": "Rank several locations by current temperature, warmest first, with each location's conditions; locations that could not be fetched are listed last with their error",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"locations": map[string]interface{}{
+						"type":        "array",
+						"description": "Cities or location names to rank, at most 20",
+						"minItems":    1,
+						"maxItems":    maxRankLocations,
+						"items": map[string]interface{}{
+							"type": "string",
+						},
+					},
+				},
+				"required": []string{"locations"},
+			},
+		},
+		{
+			"name":        toolGetThreshold,
+			"description": "Check whether today's hourly temperature at a location goes above or below a threshold, and when: the first and every slot past it, plus the warmest or coldest slot",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location": map[string]interface{}{
+						"type":        "string",
+						"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
+					},
+					"temp_c": map[string]interface{}{
+						"type":        "number",
+						"description": "Threshold temperature in degrees Celsius",
+					},
+					"direction": map[string]interface{}{
+						"type":        "string",
+						"description": "above to check for temperatures over the threshold, below for under it",
+						"enum":        []string{thresholdAbove, thresholdBelow},
+					},
+				},
+				"required": []string{"location", "temp_c", "direction"},
+			},
+		},
+		{
+			"name":        toolGetFlight,
+			"description": "Get the current weather at both ends of a flight route, given the origin and destination IATA airport codes (e.g. MUC, JFK), each labeled with its code",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"origin": map[string]interface{}{
+						"type":        "string",
+						"description": "IATA code of the departure airport (e.g. \"MUC\")",
+					},
+					"destination": map[string]interface{}{
+						"type":        "string",
+						"description": "IATA code of the arrival airport (e.g. \"JFK\")",
+					},
+				},
+				"required": []string{"origin", "destination"},
+			},
+		},
+		{
+			"name":        toolGetEmoji,
+			"description": "Get a one-line forecast with a day name and condition emoji per available forecast day (e.g. \"Mon ☀️ Tue 🌧 Wed ⛅\"), for quick chat replies",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetA11y,
+			"description": "Get the current conditions at a location as one plain sentence for screen readers, without emoji or symbols and with units spelled out (e.g. \"Overcast skies, 15 degrees Celsius, light wind from the northwest\")",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetSnapshot,
+			"description": "Get a compact, fixed-shape snapshot of the current conditions for polling and logging: {\"t\":temperature °C,\"h\":humidity %,\"w\":wind km/h,\"d\":wind direction degrees,\"c\":weather code,\"ts\":observation time}",
+			"inputSchema": locationOnlySchema(),
+		},
+		{
+			"name":        toolGetNicest,
+			"description": "Find the most comfortable upcoming part of a day (morning, noon, evening or night) over the forecast at a location, by comfort level, then chance of rain, then feels-like temperature, with its conditions",
+			"inputSchema": locationOnlySchema(),
+		},
+	}
+
+	if len(s.config.Profiles) > 0 {
+		tools = append(tools, map[string]interface{}{
+			"name":        toolGetProfiles,
+			"description": "Get current weather conditions for every saved location profile (e.g. work, home), labeled by profile name",
+			"inputSchema": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		})
+	}
+
+	if s.config.Debug {
+		tools = append(tools, map[string]interface{}{
+			"name":        toolGetDebugHeaders,
+			"description": "Debugging: fetch the current weather for a location and return the upstream wttr.in response headers (Age, Cache-Control, X-*)",
+			"inputSchema": locationOnlySchema(),
+		})
+	}
+
+	for _, tool := range tools {
+		if timezoneTools[tool["name"].(string)] {
+			schema := tool["inputSchema"].(map[string]interface{})
+			properties := schema["properties"].(map[string]interface{})
+			properties["tz"] = map[string]interface{}{
+				"type":        "string",
+				"description": "IANA timezone (e.g. \"America/New_York\") to express times in instead of the location's local time. The location's UTC offset is taken from today's observation and used for every forecast day, so days after a daylight saving change at the location are off by an hour",
+			}
+			properties["time_format"] = map[string]interface{}{
+				"type":        "string",
+				"description": "text (default) for formatted times, or epoch for Unix seconds. Like tz, it applies today's UTC offset of the location to every forecast day",
+				"enum":        []string{"text", "epoch"},
+			}
+		}
+
+		if temperatureTools[tool["name"].(string)] {
+			schema := tool["inputSchema"].(map[string]interface{})
+			schema["properties"].(map[string]interface{})["units"] = map[string]interface{}{
+				"type":        "string",
+				"description": "metric (default) for Celsius only, or both to add Fahrenheit next to every temperature",
+				"enum":        []string{unitsMetric, unitsBoth},
+			}
+		}
+
+		if rawHourlyTools[tool["name"].(string)] {
+			schema := tool["inputSchema"].(map[string]interface{})
+			schema["properties"].(map[string]interface{})["include_raw_hourly"] = map[string]interface{}{
+				"type":        "boolean",
+				"description": "Add each day's parsed hourly slots to the summary as hourly (default false)",
+			}
+		}
+
+		if description, ok := s.config.ToolDescriptions[tool["name"].(string)]; ok {
+			tool["description"] = description
+		}
+
+		schema := tool["inputSchema"].(map[string]interface{})
+		schema["properties"].(map[string]interface{})["include_provenance"] = map[string]interface{}{
+			"type":        "boolean",
+			"description": "Wrap the result with its source URL and fetch time",
+			"default":     false,
+		}
+	}
+
+	// Keep the listing stable for clients and tests regardless of how tools
+	// are assembled above.
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i]["name"].(string) < tools[j]["name"].(string)
+	})
+
+	return tools
+}
+
+// toolSchema returns the input schema of the named tool, if it is available.
+func (s *Server) toolSchema(name string) (map[string]interface{}, bool) {
+	for _, tool := range s.tools() {
+		if tool["name"] == name {
+			return tool["inputSchema"].(map[string]interface{}), true
+		}
+	}
+	return nil, false
+}
+
+// dayOffsetSchema returns the input schema of tools describing one forecast day.
+func dayOffsetSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"location": map[string]interface{}{
+				"type":        "string",
+				"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
+			},
+			"day_offset": map[string]interface{}{
+				"type":        "integer",
+				"description": "Day to describe: 0 is today, 1 tomorrow, 2 the day after (default: 0)",
+				"default":     0,
+				"minimum":     0,
+				"maximum":     maxDayOffset,
+			},
+		},
+		"required": []string{"location"},
+	}
+}
+
+// schemaVersion is the version of the structured result shapes reported
+// with WTTR_SCHEMA_VERSION. Bump it whenever a field of a JSON result is
+// renamed, removed or changes meaning.
+const schemaVersion = 1
+
+// schemaRef is the $schema identifier of a tool's structured result, such
+// as "urn:wttr-weather-mcp:get_weekend:v1". It changes with schemaVersion.
+func schemaRef(tool string) string {
+	return fmt.Sprintf("urn:wttr-weather-mcp:%s:v%d", tool, schemaVersion)
+}
+
+// timezoneTools are the tools whose results contain times that can be
+// expressed in a client-requested timezone.
+var timezoneTools = map[string]bool{
+	toolGetNowcast:     true,
+	toolGetDaylight:    true,
+	toolGetDaySegments: true,
+	toolGetWind:        true,
+	toolGetExtremes:    true,
+	toolGetThreshold:   true,
+	toolGetNicest:      true,
+}
+
+// temperatureTools are the tools whose results can carry temperatures in
+// both units.
+var temperatureTools = map[string]bool{
+	toolGetExtremes:    true,
+	toolGetDayBand:     true,
+	toolGetDaySegments: true,
+	toolGetCommute:     true,
+	toolGetThreshold:   true,
+	toolGetNicest:      true,
+}
+
+// rawHourlyTools are the tools whose day summaries can carry the parsed
+// hourly slots. get_forecast only adds them to its json format.
+var rawHourlyTools = map[string]bool{
+	toolGetForecast: true,
+	toolGetTomorrow: true,
+	toolGetDayBand:  true,
+}
+
+// locationOnlySchema returns the input schema of tools whose only argument is the location.
+func locationOnlySchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"location": map[string]interface{}{
+				"type":        "string",
+				"description": "City or location name (e.g. \"London\", \"New York\", \"Tokyo\")",
+			},
+		},
+		"required": []string{"location"},
+	}
+}
+
+func (s *Server) handleToolsCall(req JSONRPCRequest) (resp *JSONRPCResponse) {
+	var params struct {
+		Name      string                     `json:"name"`
+		Arguments json.RawMessage            `json:"arguments"`
+		Meta      map[string]json.RawMessage `json:"_meta"`
+	}
+
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error: &RPCError{
+				Code:    -32602,
+				Message: "Invalid params",
+				Data:    err.Error(),
+			},
+		}
+	}
+
+	// Every response from here on, including rejected arguments, echoes the
+	// request's _meta.
+	defer func() { resp = s.withEchoedMeta(resp, params.Meta) }()
+
+	// Missing and null arguments are treated as an empty object, so handlers
+	// report the missing required fields rather than a decoding error.
+	if len(params.Arguments) == 0 || string(params.Arguments) == "null" {
+		params.Arguments = json.RawMessage("{}")
+	}
+
+	if s.config.StrictSchema {
+		if schema, ok := s.toolSchema(params.Name); ok {
+			if violations := validateArguments(schema, params.Arguments); len(violations) > 0 {
+				return s.paramError(req.ID, "Arguments do not match the input schema", violations)
+			}
+		}
+	}
+
+	var common struct {
+		Location          string `json:"location"`
+		IncludeProvenance bool   `json:"include_provenance"`
+		Timezone          string `json:"tz"`
+		TimeFormat        string `json:"time_format"`
+		Units             string `json:"units"`
+		IncludeRawHourly  bool   `json:"include_raw_hourly"`
+	}
+	// Malformed arguments are reported by the tool handler itself.
+	json.Unmarshal(params.Arguments, &common)
+
+	// Impossible coordinates are rejected before anything is fetched.
+	if err := checkCoordinates(common.Location); err != nil {
+		return s.paramError(req.ID, "Invalid location", err.Error())
+	}
+
+	weather := s.weather
+	if common.Timezone != "" && timezoneTools[params.Name] {
+		loc, err := time.LoadLocation(common.Timezone)
+		if err != nil {
+			return s.paramError(req.ID, "Invalid tz", err.Error())
+		}
+		weather = weather.WithTimezone(loc)
+	}
+	if timezoneTools[params.Name] {
+		switch common.TimeFormat {
+		case "", "text":
+		case "epoch":
+			weather = weather.WithEpochTimes()
+		default:
+			return s.paramError(req.ID, "Invalid time_format", fmt.Sprintf("expected text or epoch, got %q", common.TimeFormat))
+		}
+	}
+	if temperatureTools[params.Name] {
+		switch common.Units {
+		case "", unitsMetric:
+		case unitsBoth:
+			weather = weather.WithBothUnits()
+		default:
+			return s.paramError(req.ID, "Invalid units", fmt.Sprintf("expected metric or both, got %q", common.Units))
+		}
+	}
+	if common.IncludeRawHourly && rawHourlyTools[params.Name] {
+		weather = weather.WithRawHourly()
+	}
+	var trace *FetchTrace
+	if common.IncludeProvenance {
+		trace = &FetchTrace{}
+		weather = weather.WithTrace(trace)
+	}
+
+	resp = s.callTool(weather, req.ID, params.Name, params.Arguments)
+	if s.config.SchemaVersion {
+		resp = s.withResultField(resp, "schema_version", schemaVersion)
+	}
+	if s.config.SchemaRef {
+		resp = s.withResultField(resp, "$schema", schemaRef(params.Name))
+	}
+	if s.config.Footer != "" {
+		resp = s.withFooter(resp)
+	}
+	if trace != nil {
+		resp = s.withProvenance(resp, trace)
+	}
+	return resp
+}
+
+func (s *Server) callTool(weather WeatherService, id interface{}, name string, args json.RawMessage) *JSONRPCResponse {
+	switch name {
+	case toolGetCurrent:
+		return s.callGetCurrent(weather, id, args)
+	case toolGetForecast:
+		return s.callGetForecast(weather, id, args)
+	case toolGetDetailed:
+		return s.callGetDetailed(weather, id, args)
+	case toolGetScore:
+		return s.callLocationTool(id, args, weather.GetWeatherScore)
+	case toolGetWeekend:
+		return s.callLocationTool(id, args, weather.GetWeekend)
+	case toolGetSunSafety:
+		return s.callLocationTool(id, args, weather.GetSunSafety)
+	case toolGetDaylight:
+		return s.callLocationTool(id, args, weather.GetDaylight)
+	case toolGetNowcast:
+		return s.callGetNowcast(weather, id, args)
+	case toolGetDaySegments:
+		return s.callDayOffsetTool(id, args, weather.GetDaySegments)
+	case toolGetWind:
+		return s.callDayOffsetTool(id, args, weather.GetWindForecast)
+	case toolGetExtremes:
+		return s.callDayOffsetTool(id, args, weather.GetTempExtremes)
+	case toolGetActivity:
+		return s.callGetActivitySuitability(weather, id, args)
+	case toolGetCommute:
+		return s.callGetCommute(weather, id, args)
+	case toolGetProfiles:
+		return s.callGetProfilesWeather(weather, id)
+	case toolGetDebugHeaders:
+		if !s.config.Debug {
+			return s.errorResponse(id, fmt.Errorf("debug tools are disabled; set WTTR_DEBUG=1"))
+		}
+		return s.callLocationTool(id, args, weather.GetDebugHeaders)
+	case toolGetOnThisDay:
+		return s.callLocationTool(id, args, weather.GetOnThisDay)
+	case toolGetAntipode:
+		return s.callLocationTool(id, args, weather.GetAntipodeWeather)
+	case toolGeocode:
+		return s.callLocationTool(id, args, weather.Geocode)
+	case toolGetOverview:
+		return s.callLocationTool(id, args, weather.GetOverview)
+	case toolGetDayBand:
+		return s.callLocationTool(id, args, weather.GetDayBand)
+	case toolGetDayDelta:
+		return s.callGetDayDelta(weather, id, args)
+	case toolGetPrecip:
+		return s.callGetPrecipForecast(weather, id, args)
+	case toolGetMoonPhase:
+		return s.callGetMoonPhase(id, args)
+	case toolGetRaw:
+		return s.callGetRaw(weather, id, args)
+	case toolGetSSML:
+		return s.callLocationTool(id, args, weather.GetWeatherSSML)
+	case toolGetCard:
+		return s.callLocationTool(id, args, weather.GetWeatherCard)
+	case toolGetTomorrow:
+		return s.callLocationTool(id, args, weather.GetTomorrow)
+	case toolGetShortTrend:
+		return s.callLocationTool(id, args, weather.GetShortTrend)
+	case toolGetBriefing:
+		return s.callLocationTool(id, args, weather.GetBriefing)
+	case toolGetAreaGrid:
+		return s.callGetAreaGrid(weather, id, args)
+	case toolGetByCode:
+		return s.callGetWeatherByCode(weather, id, args)
+	case toolGetForecastICS:
+		return s.callGetForecastICS(weather, id, args)
+	case toolGetEvents:
+		return s.callGetEventsWeather(weather, id, args)
+	case toolGetBestDay:
+		return s.callGetBestDay(weather, id, args)
+	case toolGetWeekStrip:
+		return s.callLocationTool(id, args, weather.GetWeekStrip)
+	case toolGetComfort:
+		return s.callLocationTool(id, args, weather.GetComfort)
+	case toolGetOutlook:
+		return s.callLocationTool(id, args, weather.GetOutlook)
+	case toolRankByTemp:
+		return s.callRankByTemperature(weather, id, args)
+	case toolGetThreshold:
+		return s.callGetThresholdCheck(weather, id, args)
+	case toolGetFlight:
+		return s.callGetFlightWeather(weather, id, args)
+	case toolGetEmoji:
+		return s.callLocationTool(id, args, weather.GetEmojiForecast)
+	case toolGetA11y:
+		return s.callLocationTool(id, args, weather.GetWeatherA11y)
+	case toolGetSnapshot:
+		return s.callLocationTool(id, args, weather.GetSnapshot)
+	case toolGetNicest:
+		return s.callLocationTool(id, args, weather.GetNicestWindow)
+	default:
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      id,
+			Error: &RPCError{
+				Code:    -32602,
+				Message: "Unknown tool: " + name,
+			},
+		}
+	}
+}
+
+func (s *Server) callGetCurrent(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location    string   `json:"location"`
+		Fields      []string `json:"fields"`
+		ResolveName bool     `json:"resolve_name"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if _, err := buildCurrentFormat(input.Fields); err != nil {
+		return s.paramError(id, "Invalid fields", err.Error())
+	}
+
+	result, err := weather.GetCurrent(input.Location, input.Fields...)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	// The name is a convenience: if it can't be resolved, the one-liner is
+	// returned as is.
+	if input.ResolveName && coordinatePattern.MatchString(input.Location) {
+		var place GeocodeResult
+		if geocoded, err := weather.Geocode(input.Location); err == nil && json.Unmarshal([]byte(geocoded), &place) == nil && place.Area != "" {
+			result = labelOneLiner(result, place.placeName())
+		}
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetForecast(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location string   `json:"location"`
+		Days     flexInt  `json:"days"`
+		Format   string   `json:"format"`
+		Options  []string `json:"options"`
+	}
+	input.Days = flexInt(s.config.forecastDays())
+	input.Format = s.config.ForecastFormat
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if input.Days < 0 || input.Days > maxForecastDays {
+		input.Days = flexInt(s.config.forecastDays())
+	}
+
+	if _, err := forecastFlags(input.Options); err != nil {
+		return s.paramError(id, "Invalid options", err.Error())
+	}
+
+	switch input.Format {
+	case "", forecastFormatASCII:
+	case forecastFormatJSON, forecastFormatMarkdown:
+		if len(input.Options) > 0 {
+			return s.paramError(id, "Invalid options", fmt.Sprintf("options apply only to the ascii format, not %s", input.Format))
+		}
+		result, err := weather.GetForecastSummary(input.Location, int(input.Days), input.Format)
+		if err != nil {
+			return s.errorResponse(id, err)
+		}
+		return s.successResponse(id, result)
+	default:
+		return s.paramError(id, "format must be one of ascii, json or markdown", nil)
+	}
+
+	result, err := weather.GetForecast(input.Location, int(input.Days), input.Options...)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	uri := fmt.Sprintf("weather://forecast/%s?days=%d", url.PathEscape(input.Location), input.Days)
+	return s.resourceResponse(id, uri, "text/plain", result)
+}
+
+func (s *Server) callGetDetailed(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location string   `json:"location"`
+		Fields   []string `json:"fields"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	result, err := weather.GetDetailed(input.Location)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	if len(input.Fields) > 0 {
+		projection, err := projectCurrent(result, input.Fields)
+		if err != nil {
+			return s.errorResponse(id, err)
+		}
+		if result, err = marshalResult(projection); err != nil {
+			return s.errorResponse(id, err)
+		}
+		return s.successResponse(id, result)
+	}
+
+	if w, err := parseDetailed(result); err == nil && len(w.NearestArea) > 0 {
+		if confidence := resolutionConfidence(input.Location, w.NearestArea[0].AreaName.String()); confidence != "" {
+			if result, err = addJSONField(result, "confidence", confidence); err != nil {
+				return s.errorResponse(id, err)
+			}
+		}
+		if d, ok := areaDistance(input.Location, w); ok {
+			if result, err = addJSONField(result, "distance_km", roundTo(d, s.config.Precision)); err != nil {
+				return s.errorResponse(id, err)
+			}
+		}
+	}
+
+	return s.resourceResponse(id, "weather://detailed/"+url.PathEscape(input.Location), "application/json", result)
+}
+
+func (s *Server) callGetNowcast(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location string  `json:"location"`
+		Minutes  flexInt `json:"minutes"`
+	}
+	input.Minutes = defaultNowcastMinutes
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if input.Minutes < 1 {
+		return s.paramError(id, "minutes must be between 1 and 180", nil)
+	}
+	if input.Minutes > maxNowcastMinutes {
+		input.Minutes = maxNowcastMinutes
+	}
+
+	result, err := weather.GetNowcast(input.Location, int(input.Minutes))
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+// callDayOffsetTool handles the tools taking a location and a day_offset.
+func (s *Server) callDayOffsetTool(id interface{}, args json.RawMessage, fetch func(location string, dayOffset int) (string, error)) *JSONRPCResponse {
+	var input struct {
+		Location  string  `json:"location"`
+		DayOffset flexInt `json:"day_offset"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if input.DayOffset < 0 || input.DayOffset > maxDayOffset {
+		return s.paramError(id, "day_offset must be between 0 and 2", nil)
+	}
+
+	result, err := fetch(input.Location, int(input.DayOffset))
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetCommute(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location    string  `json:"location"`
+		MorningHour flexInt `json:"morning_hour"`
+		EveningHour flexInt `json:"evening_hour"`
+	}
+	input.MorningHour = defaultMorningHour
+	input.EveningHour = defaultEveningHour
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if input.MorningHour < 0 || input.MorningHour > 23 || input.EveningHour < 0 || input.EveningHour > 23 {
+		return s.paramError(id, "morning_hour and evening_hour must be between 0 and 23", nil)
+	}
+
+	result, err := weather.GetCommute(input.Location, int(input.MorningHour), int(input.EveningHour))
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetDayDelta(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location string   `json:"location"`
+		DayA     *flexInt `json:"day_a"`
+		DayB     *flexInt `json:"day_b"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if input.DayA == nil || input.DayB == nil {
+		return s.paramError(id, "day_a and day_b are required", nil)
+	}
+
+	if *input.DayA < 0 || *input.DayA > maxDayOffset || *input.DayB < 0 || *input.DayB > maxDayOffset {
+		return s.paramError(id, "day_a and day_b must be between 0 and 2", nil)
+	}
+
+	result, err := weather.GetDayDelta(input.Location, int(*input.DayA), int(*input.DayB))
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetPrecipForecast(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location string  `json:"location"`
+		Days     flexInt `json:"days"`
+	}
+	input.Days = 3
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if input.Days < 1 || input.Days > 3 {
+		return s.paramError(id, "days must be between 1 and 3", nil)
+	}
+
+	result, err := weather.GetPrecipForecast(input.Location, int(input.Days))
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetAreaGrid(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location string  `json:"location"`
+		RadiusKm float64 `json:"radius_km"`
+	}
+	input.RadiusKm = defaultGridRadiusKm
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if input.RadiusKm <= 0 || input.RadiusKm > maxGridRadiusKm {
+		return s.paramError(id, fmt.Sprintf("radius_km must be greater than 0 and at most %g", maxGridRadiusKm), nil)
+	}
+
+	result, err := weather.GetAreaGrid(input.Location, input.RadiusKm)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetWeatherByCode(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Code string `json:"code"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if _, err := resolveAreaCode(input.Code); err != nil {
+		return s.paramError(id, "Invalid code", err.Error())
+	}
+
+	result, err := weather.GetWeatherByCode(input.Code)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetFlightWeather(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Origin      string `json:"origin"`
+		Destination string `json:"destination"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	for _, code := range []string{input.Origin, input.Destination} {
+		if err := checkAirportCode(code); err != nil {
+			return s.paramError(id, "Invalid airport code", err.Error())
+		}
+	}
+
+	result, err := weather.GetFlightWeather(input.Origin, input.Destination)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetForecastICS(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location string  `json:"location"`
+		Days     flexInt `json:"days"`
+	}
+	input.Days = 3
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if input.Days < 1 || input.Days > 3 {
+		return s.paramError(id, "days must be between 1 and 3", nil)
+	}
+
+	result, err := weather.GetForecastICS(input.Location, int(input.Days))
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetMoonPhase(id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Date string `json:"date"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	at := time.Now().UTC()
+	if input.Date != "" {
+		date, err := time.Parse(j1DateLayout, input.Date)
+		if err != nil {
+			return s.paramError(id, "date must be formatted as YYYY-MM-DD", nil)
+		}
+		at = date.Add(12 * time.Hour)
+	}
+
+	result, err := marshalResult(moonPhase(at))
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetRaw(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location string `json:"location"`
+		RawQuery string `json:"raw_query"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if _, err := checkRawQuery(input.RawQuery); err != nil {
+		return s.paramError(id, "Invalid raw_query", err.Error())
+	}
+
+	result, err := weather.GetRaw(input.Location, input.RawQuery)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetActivitySuitability(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location string `json:"location"`
+		Activity string `json:"activity"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if _, ok := activities[input.Activity]; !ok {
+		return s.paramError(id, "activity must be one of: "+strings.Join(activityNames(), ", "), nil)
+	}
+
+	result, err := weather.GetActivitySuitability(input.Location, input.Activity)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetEventsWeather(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Events []Event `json:"events"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if err := checkEvents(input.Events); err != nil {
+		return s.paramError(id, "Invalid events", err.Error())
+	}
+
+	result, err := weather.GetEventsWeather(input.Events)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callRankByTemperature(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Locations []string `json:"locations"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if err := checkRankLocations(input.Locations); err != nil {
+		return s.paramError(id, "Invalid locations", err.Error())
+	}
+
+	result, err := weather.RankByTemperature(input.Locations)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetThresholdCheck(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location  string   `json:"location"`
+		TempC     *float64 `json:"temp_c"`
+		Direction string   `json:"direction"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if input.TempC == nil {
+		return s.paramError(id, "temp_c is required", nil)
+	}
+
+	if input.Direction != thresholdAbove && input.Direction != thresholdBelow {
+		return s.paramError(id, "direction must be one of: "+thresholdAbove+", "+thresholdBelow, nil)
+	}
+
+	result, err := weather.GetThresholdCheck(input.Location, *input.TempC, input.Direction)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetBestDay(weather WeatherService, id interface{}, args json.RawMessage) *JSONRPCResponse {
+	var input struct {
+		Location string `json:"location"`
+		Activity string `json:"activity"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	if _, ok := activities[input.Activity]; !ok {
+		return s.paramError(id, "activity must be one of: "+strings.Join(activityNames(), ", "), nil)
+	}
+
+	result, err := weather.GetBestDay(input.Location, input.Activity)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+func (s *Server) callGetProfilesWeather(weather WeatherService, id interface{}) *JSONRPCResponse {
+	if len(s.config.Profiles) == 0 {
+		return s.errorResponse(id, fmt.Errorf("no profiles configured; set WTTR_PROFILES or WTTR_PROFILES_FILE"))
+	}
+
+	names := make([]string, 0, len(s.config.Profiles))
+	for name := range s.config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	locations := make([]string, len(names))
+	for i, name := range names {
+		locations[i] = s.config.Profiles[name]
+	}
+
+	results := fetchAll(locations, func(location string) (string, error) {
+		return weather.GetCurrent(location)
+	})
+
+	lines := make([]string, len(names))
+	failed := 0
+	for i, r := range results {
+		if r.Err != nil {
+			failed++
+			lines[i] = fmt.Sprintf("%s: Error: %v", names[i], r.Err)
+			continue
+		}
+		lines[i] = fmt.Sprintf("%s: %s", names[i], strings.TrimSpace(r.Text))
+	}
+
+	if failed == len(results) {
+		return s.errorResponse(id, fmt.Errorf("all profiles failed:\n%s", strings.Join(lines, "\n")))
+	}
+	return s.successResponse(id, strings.Join(lines, "\n"))
+}
+
+// locationResult is the outcome of fetching one of several locations.
+type locationResult struct {
+	Location string
+	Text     string
+	Err      error
+}
+
+// fetchAll fetches every location concurrently and returns the results in
+// the order of locations.
+func fetchAll(locations []string, fetch func(location string) (string, error)) []locationResult {
+	results := make([]locationResult, len(locations))
+
+	var wg sync.WaitGroup
+	for i, location := range locations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			text, err := fetch(location)
+			results[i] = locationResult{Location: location, Text: text, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// flexInt decodes integer arguments sent either as JSON numbers or as
+// numeric strings ("2"), which some clients produce. A null leaves the
+// default in place, as if the argument were omitted.
+type flexInt int
+
+func (n *flexInt) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %s", data)
+		}
+		*n = flexInt(v)
+		return nil
+	}
+
+	var v int
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("expected an integer, got %s", data)
+	}
+	*n = flexInt(v)
+	return nil
+}
+
+// callLocationTool handles tools whose only argument is the location.
+func (s *Server) callLocationTool(id interface{}, args json.RawMessage, fetch func(location string) (string, error)) *JSONRPCResponse {
+	var input struct {
+		Location string `json:"location"`
+	}
+
+	if err := json.Unmarshal(args, &input); err != nil {
+		return s.paramError(id, "Invalid arguments", err.Error())
+	}
+
+	if input.Location == "" {
+		return s.paramError(id, "location is required", nil)
+	}
+
+	result, err := fetch(input.Location)
+	if err != nil {
+		return s.errorResponse(id, err)
+	}
+
+	return s.successResponse(id, result)
+}
+
+// provenanceSource is one upstream request behind a result.
+type provenanceSource struct {
+	URL       string `json:"url"`
+	FetchedAt string `json:"fetched_at"`
+}
+
+// withProvenance wraps a successful text result with the upstream URL and
+// fetch time recorded in trace. Tools that fetch more than once, such as
+// rank_by_temperature, list every request under sources; url and
+// fetched_at are the first.
+func (s *Server) withProvenance(resp *JSONRPCResponse, trace *FetchTrace) *JSONRPCResponse {
+	text, ok := successText(resp)
+	if !ok {
+		return resp
+	}
+
+	var data interface{} = text
+	if json.Valid([]byte(text)) {
+		data = json.RawMessage(text)
+	}
+
+	sources := []provenanceSource{}
+	for _, f := range trace.Fetches() {
+		sources = append(sources, provenanceSource{URL: f.URL, FetchedAt: f.FetchedAt.UTC().Format(time.RFC3339)})
+	}
+	wrapped := map[string]interface{}{
+		"source":     "wttr.in",
+		"url":        "",
+		"fetched_at": time.Now().UTC().Format(time.RFC3339),
+		"sources":    sources,
+		"data":       data,
+	}
+	if len(sources) > 0 {
+		wrapped["url"] = sources[0].URL
+		wrapped["fetched_at"] = sources[0].FetchedAt
+	}
+
+	out, err := json.Marshal(wrapped)
+	if err != nil {
+		return s.errorResponse(resp.ID, fmt.Errorf("encoding provenance: %w", err))
+	}
+	return s.withText(resp, string(out), "application/json")
+}
+
+// withResultField adds a top-level field to a successful result that is a
+// JSON object. Other results are returned unchanged.
+func (s *Server) withResultField(resp *JSONRPCResponse, key string, value interface{}) *JSONRPCResponse {
+	text, ok := successText(resp)
+	if !ok {
+		return resp
+	}
+
+	out, err := addJSONField(text, key, value)
+	if err != nil {
+		return s.errorResponse(resp.ID, err)
+	}
+	return s.withText(resp, out, "")
+}
+
+// defaultEchoMeta is the request _meta fields echoed when not configured.
+var defaultEchoMeta = []string{"correlation_id"}
+
+// withEchoedMeta copies the agreed-upon fields of the request's _meta into
+// the result's _meta, next to any error_code. Arguments rejected as JSON-RPC
+// errors carry the fields in the error's _meta instead.
+func (s *Server) withEchoedMeta(resp *JSONRPCResponse, requestMeta map[string]json.RawMessage) *JSONRPCResponse {
+	if len(requestMeta) == 0 {
+		return resp
+	}
+	keys := s.config.EchoMeta
+	if keys == nil {
+		keys = defaultEchoMeta
+	}
+
+	echoed := map[string]interface{}{}
+	for _, key := range keys {
+		if value, ok := requestMeta[key]; ok {
+			echoed[key] = value
+		}
+	}
+	if len(echoed) == 0 {
+		return resp
+	}
+	if resp.Error != nil {
+		resp.Error.Meta = echoed
+		return resp
+	}
+	result, ok := resp.Result.(map[string]interface{})
+	if !ok {
+		return resp
+	}
+	if existing, ok := result["_meta"].(map[string]string); ok {
+		for k, v := range existing {
+			echoed[k] = v
+		}
+	}
+	result["_meta"] = echoed
+	return resp
+}
+
+// addJSONField adds a top-level field to text if it is a JSON object. The
+// field is spliced in front so the object keeps its own field order.
+func addJSONField(text, key string, value interface{}) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(text), &fields); err != nil || fields == nil {
+		return text, nil
+	}
+
+	name, _ := json.Marshal(key)
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("encoding %s: %w", key, err)
+	}
+
+	field := string(name) + ":" + string(encoded)
+	if len(fields) == 0 {
+		return "{" + field + "}", nil
+	}
+	return "{" + field + "," + strings.TrimSpace(text)[1:], nil
+}
+
+// withFooter adds the configured footer to a successful result: as a
+// "footer" field of JSON objects and as a trailing paragraph of text.
+func (s *Server) withFooter(resp *JSONRPCResponse) *JSONRPCResponse {
+	text, ok := successText(resp)
+	if !ok {
+		return resp
+	}
+
+	if trimmed := strings.TrimSpace(text); json.Valid([]byte(trimmed)) {
+		if strings.HasPrefix(trimmed, "{") {
+			return s.withResultField(resp, "footer", s.config.Footer)
+		}
+		return resp
+	}
+	return s.withText(resp, strings.TrimRight(text, "\n")+"\n\n"+s.config.Footer, "")
+}
+
+// successText returns the text of a successful result with a single text
+// or embedded resource item.
+func successText(resp *JSONRPCResponse) (string, bool) {
+	if resp.Error != nil {
+		return "", false
+	}
+	result, ok := resp.Result.(map[string]interface{})
+	if !ok || result["isError"] == true {
+		return "", false
+	}
+	if resource, ok := successResource(result); ok {
+		return resource["text"], true
+	}
+	content, ok := result["content"].([]map[string]string)
+	if !ok || len(content) != 1 || content[0]["type"] != "text" {
+		return "", false
+	}
+	return content[0]["text"], true
+}
+
+// successResource returns the embedded resource of a result with a single
+// resource item.
+func successResource(result map[string]interface{}) (map[string]string, bool) {
+	content, ok := result["content"].([]map[string]interface{})
+	if !ok || len(content) != 1 || content[0]["type"] != "resource" {
+		return nil, false
+	}
+	resource, ok := content[0]["resource"].(map[string]string)
+	return resource, ok
+}
+
+// withText replaces the text of a successful result, keeping it an embedded
+// resource when it is one. An empty mimeType keeps the resource's own.
+func (s *Server) withText(resp *JSONRPCResponse, text, mimeType string) *JSONRPCResponse {
+	result, _ := resp.Result.(map[string]interface{})
+	resource, ok := successResource(result)
+	if !ok {
+		return s.successResponse(resp.ID, text)
+	}
+	if mimeType == "" {
+		mimeType = resource["mimeType"]
+	}
+	return s.embeddedResource(resp.ID, resource["uri"], mimeType, text)
+}
+
+func (s *Server) successResponse(id interface{}, text string) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Result: map[string]interface{}{
+			"content": []map[string]string{
+				{"type": "text", "text": text},
+			},
+		},
+	}
+}
+
+// resourceResponse returns results of at least the configured threshold as an
+// embedded resource, which clients can keep out of the model context until
+// needed. Smaller results, or all results when disabled, are plain text.
+func (s *Server) resourceResponse(id interface{}, uri, mimeType, text string) *JSONRPCResponse {
+	if s.config.ResourceThreshold <= 0 || len(text) < s.config.ResourceThreshold {
+		return s.successResponse(id, text)
+	}
+	return s.embeddedResource(id, uri, mimeType, text)
+}
+
+// embeddedResource returns text as a single embedded resource.
+func (s *Server) embeddedResource(id interface{}, uri, mimeType, text string) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "resource",
+					"resource": map[string]string{
+						"uri":      uri,
+						"mimeType": mimeType,
+						"text":     text,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *Server) errorResponse(id interface{}, err error) *JSONRPCResponse {
+	if s.config.ErrorStyle == errorStyleRPC {
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      id,
+			Error: &RPCError{
+				Code:    errToolFailed,
+				Message: err.Error(),
+				Data:    map[string]string{"error_code": errorCode(err)},
+			},
+		}
+	}
+
 	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      id,
@@ -321,6 +2185,7 @@ func (s *Server) errorResponse(id interface{}, err error) *JSONRPCResponse {
 				{"type": "text", "text": fmt.Sprintf("Error: %v", err)},
 			},
 			"isError": true,
+			"_meta":   map[string]string{"error_code": errorCode(err)},
 		},
 	}
 }
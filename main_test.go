@@ -1,37 +1,369 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // mockWeather implements WeatherService for testing.
 type mockWeather struct {
-	currentResult  string
-	forecastResult string
-	detailedResult string
-	err            error
-	lastLocation   string
-	lastDays       int
+	currentResult    string
+	currentResults   map[string]string
+	forecastResult   string
+	detailedResult   string
+	scoreResult      string
+	weekendResult    string
+	sunSafetyResult  string
+	daylightResult   string
+	nowcastResult    string
+	onThisDayResult  string
+	segmentsResult   string
+	headersResult    string
+	antipodeResult   string
+	commuteResult    string
+	windResult       string
+	activityResult   string
+	extremesResult   string
+	summaryResult    string
+	precipResult     string
+	lastFormat       string
+	lastActivity     string
+	geocodeResult    string
+	overviewResult   string
+	dayBandResult    string
+	rawResult        string
+	dayDeltaResult   string
+	lastDayPair      [2]int
+	lastRawQuery     string
+	ssmlResult       string
+	cardResult       string
+	tomorrowResult   string
+	shortTrendResult string
+	briefingResult   string
+	areaGridResult   string
+	lastRadiusKm     float64
+	byCodeResult     string
+	lastCode         string
+	icsResult        string
+	bestDayResult    string
+	eventsResult     string
+	lastEvents       []Event
+	weekStripResult  string
+	comfortResult    string
+	outlookResult    string
+	rankResult       string
+	thresholdResult  string
+	lastThreshold    float64
+	lastDirection    string
+	flightResult     string
+	lastRoute        [2]string
+	lastRanked       []string
+	emojiResult      string
+	a11yResult       string
+	snapshotResult   string
+	nicestResult     string
+	err              error
+	lastLocation     string
+	lastDays         int
+	lastOptions      []string
+	lastFields       []string
+	lastMinutes      int
+	lastDayOffset    int
+	lastHours        [2]int
+	trace            *FetchTrace
+	tz               *time.Location
+	epoch            bool
+	bothUnits        bool
+	rawHourly        bool
+	currentCalls     chan string
+
+	mu        sync.Mutex
+	locations []string
 }
 
-func (m *mockWeather) GetCurrent(location string) (string, error) {
+// record notes a requested location; it is safe for concurrent fetches.
+func (m *mockWeather) record(location string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.lastLocation = location
+	m.locations = append(m.locations, location)
+}
+
+func (m *mockWeather) GetCurrent(location string, fields ...string) (string, error) {
+	m.mu.Lock()
+	m.lastFields = fields
+	m.mu.Unlock()
+	if m.currentCalls != nil {
+		m.currentCalls <- location
+		return m.currentResult, m.err
+	}
+	m.record(location)
+	if result, ok := m.currentResults[location]; ok {
+		return result, m.err
+	}
+	if m.trace != nil {
+		m.trace.record("http://wttr.in/"+location+"?format=3", time.Date(2024, 6, 12, 14, 35, 0, 0, time.UTC))
+	}
 	return m.currentResult, m.err
 }
 
-func (m *mockWeather) GetForecast(location string, days int) (string, error) {
-	m.lastLocation = location
+func (m *mockWeather) GetForecast(location string, days int, options ...string) (string, error) {
+	m.record(location)
 	m.lastDays = days
+	m.lastOptions = options
 	return m.forecastResult, m.err
 }
 
+func (m *mockWeather) GetForecastSummary(location string, days int, format string) (string, error) {
+	m.record(location)
+	m.lastDays = days
+	m.lastFormat = format
+	return m.summaryResult, m.err
+}
+
 func (m *mockWeather) GetDetailed(location string) (string, error) {
-	m.lastLocation = location
+	m.record(location)
 	return m.detailedResult, m.err
 }
 
+func (m *mockWeather) GetWeatherScore(location string) (string, error) {
+	m.record(location)
+	return m.scoreResult, m.err
+}
+
+func (m *mockWeather) GetWeekend(location string) (string, error) {
+	m.record(location)
+	return m.weekendResult, m.err
+}
+
+func (m *mockWeather) GetSunSafety(location string) (string, error) {
+	m.record(location)
+	return m.sunSafetyResult, m.err
+}
+
+func (m *mockWeather) GetDaylight(location string) (string, error) {
+	m.record(location)
+	return m.daylightResult, m.err
+}
+
+func (m *mockWeather) GetNowcast(location string, minutes int) (string, error) {
+	m.record(location)
+	m.lastMinutes = minutes
+	return m.nowcastResult, m.err
+}
+
+func (m *mockWeather) GetOnThisDay(location string) (string, error) {
+	m.record(location)
+	return m.onThisDayResult, m.err
+}
+
+func (m *mockWeather) GetDaySegments(location string, dayOffset int) (string, error) {
+	m.record(location)
+	m.lastDayOffset = dayOffset
+	return m.segmentsResult, m.err
+}
+
+func (m *mockWeather) GetDebugHeaders(location string) (string, error) {
+	m.record(location)
+	return m.headersResult, m.err
+}
+
+func (m *mockWeather) GetAntipodeWeather(location string) (string, error) {
+	m.record(location)
+	return m.antipodeResult, m.err
+}
+
+func (m *mockWeather) GetCommute(location string, morningHour, eveningHour int) (string, error) {
+	m.record(location)
+	m.lastHours = [2]int{morningHour, eveningHour}
+	return m.commuteResult, m.err
+}
+
+func (m *mockWeather) Geocode(location string) (string, error) {
+	m.record(location)
+	return m.geocodeResult, m.err
+}
+
+func (m *mockWeather) GetWindForecast(location string, dayOffset int) (string, error) {
+	m.record(location)
+	m.lastDayOffset = dayOffset
+	return m.windResult, m.err
+}
+
+func (m *mockWeather) GetActivitySuitability(location, activity string) (string, error) {
+	m.record(location)
+	m.lastActivity = activity
+	return m.activityResult, m.err
+}
+
+func (m *mockWeather) GetOverview(location string) (string, error) {
+	m.record(location)
+	return m.overviewResult, m.err
+}
+
+func (m *mockWeather) GetTempExtremes(location string, dayOffset int) (string, error) {
+	m.record(location)
+	m.lastDayOffset = dayOffset
+	return m.extremesResult, m.err
+}
+
+func (m *mockWeather) GetDayBand(location string) (string, error) {
+	m.record(location)
+	return m.dayBandResult, m.err
+}
+
+func (m *mockWeather) GetRaw(location, rawQuery string) (string, error) {
+	m.record(location)
+	m.lastRawQuery = rawQuery
+	return m.rawResult, m.err
+}
+
+func (m *mockWeather) GetDayDelta(location string, dayA, dayB int) (string, error) {
+	m.record(location)
+	m.lastDayPair = [2]int{dayA, dayB}
+	return m.dayDeltaResult, m.err
+}
+
+func (m *mockWeather) GetWeatherSSML(location string) (string, error) {
+	m.record(location)
+	return m.ssmlResult, m.err
+}
+
+func (m *mockWeather) GetWeatherCard(location string) (string, error) {
+	m.record(location)
+	return m.cardResult, m.err
+}
+
+func (m *mockWeather) GetTomorrow(location string) (string, error) {
+	m.record(location)
+	return m.tomorrowResult, m.err
+}
+
+func (m *mockWeather) GetPrecipForecast(location string, days int) (string, error) {
+	m.record(location)
+	m.lastDays = days
+	return m.precipResult, m.err
+}
+
+func (m *mockWeather) GetShortTrend(location string) (string, error) {
+	m.record(location)
+	return m.shortTrendResult, m.err
+}
+
+func (m *mockWeather) GetBriefing(location string) (string, error) {
+	m.record(location)
+	return m.briefingResult, m.err
+}
+
+func (m *mockWeather) GetAreaGrid(center string, radiusKm float64) (string, error) {
+	m.record(center)
+	m.lastRadiusKm = radiusKm
+	return m.areaGridResult, m.err
+}
+
+func (m *mockWeather) GetWeatherByCode(code string) (string, error) {
+	m.lastCode = code
+	return m.byCodeResult, m.err
+}
+
+func (m *mockWeather) GetForecastICS(location string, days int) (string, error) {
+	m.record(location)
+	m.lastDays = days
+	return m.icsResult, m.err
+}
+
+func (m *mockWeather) GetWeekStrip(location string) (string, error) {
+	m.record(location)
+	return m.weekStripResult, m.err
+}
+
+func (m *mockWeather) GetBestDay(location, activity string) (string, error) {
+	m.record(location)
+	m.lastActivity = activity
+	return m.bestDayResult, m.err
+}
+
+func (m *mockWeather) GetComfort(location string) (string, error) {
+	m.record(location)
+	return m.comfortResult, m.err
+}
+
+func (m *mockWeather) GetEventsWeather(events []Event) (string, error) {
+	m.lastEvents = events
+	return m.eventsResult, m.err
+}
+
+func (m *mockWeather) GetOutlook(location string) (string, error) {
+	m.record(location)
+	return m.outlookResult, m.err
+}
+
+func (m *mockWeather) RankByTemperature(locations []string) (string, error) {
+	m.lastRanked = locations
+	return m.rankResult, m.err
+}
+
+func (m *mockWeather) GetThresholdCheck(location string, threshold float64, direction string) (string, error) {
+	m.record(location)
+	m.lastThreshold, m.lastDirection = threshold, direction
+	return m.thresholdResult, m.err
+}
+
+func (m *mockWeather) GetFlightWeather(origin, dest string) (string, error) {
+	m.lastRoute = [2]string{origin, dest}
+	return m.flightResult, m.err
+}
+
+func (m *mockWeather) GetEmojiForecast(location string) (string, error) {
+	m.record(location)
+	return m.emojiResult, m.err
+}
+
+func (m *mockWeather) GetWeatherA11y(location string) (string, error) {
+	m.record(location)
+	return m.a11yResult, m.err
+}
+
+func (m *mockWeather) GetSnapshot(location string) (string, error) {
+	m.record(location)
+	return m.snapshotResult, m.err
+}
+
+func (m *mockWeather) GetNicestWindow(location string) (string, error) {
+	m.record(location)
+	return m.nicestResult, m.err
+}
+
+func (m *mockWeather) WithTrace(trace *FetchTrace) WeatherService {
+	m.trace = trace
+	return m
+}
+
+func (m *mockWeather) WithTimezone(loc *time.Location) WeatherService {
+	m.tz = loc
+	return m
+}
+
+func (m *mockWeather) WithEpochTimes() WeatherService {
+	m.epoch = true
+	return m
+}
+
+func (m *mockWeather) WithBothUnits() WeatherService {
+	m.bothUnits = true
+	return m
+}
+
+func (m *mockWeather) WithRawHourly() WeatherService {
+	m.rawHourly = true
+	return m
+}
+
 func makeRequest(method string, id interface{}, params interface{}) JSONRPCRequest {
 	var raw json.RawMessage
 	if params != nil {
@@ -45,6 +377,123 @@ func makeRequest(method string, id interface{}, params interface{}) JSONRPCReque
 	}
 }
 
+// serveInput runs the server loop over input and returns the decoded responses.
+func serveInput(t *testing.T, s *Server, input string) []JSONRPCResponse {
+	t.Helper()
+	var out bytes.Buffer
+	s.out = &out
+	s.serve(strings.NewReader(input))
+
+	var responses []JSONRPCResponse
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp JSONRPCResponse
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("invalid response output: %v\n%s", err, out.String())
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func TestServePrettyPrintedRequest(t *testing.T) {
+	mock := &mockWeather{currentResult: "London: ☀️ +20°C"}
+	s := &Server{weather: mock}
+
+	input := `{
+  "jsonrpc": "2.0",
+  "id": 7,
+  "method": "tools/call",
+  "params": {
+    "name": "get_current_weather",
+    "arguments": {
+      "location": "London"
+    }
+  }
+}
+`
+	responses := serveInput(t, s, input)
+
+	if len(responses) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(responses))
+	}
+	if responses[0].ID != float64(7) || responses[0].Error != nil {
+		t.Errorf("unexpected response: %+v", responses[0])
+	}
+	if mock.lastLocation != "London" {
+		t.Errorf("expected location London, got %s", mock.lastLocation)
+	}
+}
+
+func TestServeSeveralRequestsOnOneLine(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+
+	input := `{"jsonrpc":"2.0","id":1,"method":"initialize"}{"jsonrpc":"2.0","method":"initialized"} {"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+	responses := serveInput(t, s, input)
+
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses, got %d", len(responses))
+	}
+	if responses[0].ID != float64(1) || responses[1].ID != float64(2) {
+		t.Errorf("unexpected response ids: %v, %v", responses[0].ID, responses[1].ID)
+	}
+}
+
+func TestServeRecoversFromParseError(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+
+	input := "{\"jsonrpc\": \"2.0\", \"id\": 1, oops}\n{\"jsonrpc\":\"2.0\",\"id\":2,\"method\":\"tools/list\"}\n"
+	responses := serveInput(t, s, input)
+
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses, got %d", len(responses))
+	}
+	if responses[0].Error == nil || responses[0].Error.Code != -32700 {
+		t.Errorf("expected parse error first, got %+v", responses[0])
+	}
+	if responses[1].ID != float64(2) || responses[1].Error != nil {
+		t.Errorf("expected tools/list to succeed after the parse error, got %+v", responses[1])
+	}
+}
+
+func TestServeRejectsOversizedMessage(t *testing.T) {
+	s := &Server{weather: &mockWeather{}, config: Config{MaxMessageBytes: 64}}
+
+	long := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"` + strings.Repeat("x", 200) + `"}}`
+	input := long + "\n" + `{"jsonrpc":"2.0","id":2,"method":"tools/list"}` + "\n"
+	responses := serveInput(t, s, input)
+
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses, got %d", len(responses))
+	}
+	if responses[0].Error == nil || responses[0].Error.Code != -32600 {
+		t.Errorf("expected the oversized message to be rejected, got %+v", responses[0])
+	}
+	if responses[1].ID != float64(2) || responses[1].Error != nil {
+		t.Errorf("expected tools/list to succeed after the oversized message, got %+v", responses[1])
+	}
+}
+
+func TestServeParseErrorHasNullID(t *testing.T) {
+	var out bytes.Buffer
+	s := &Server{weather: &mockWeather{}, out: &out}
+	s.serve(strings.NewReader("{oops}\n"))
+
+	if !strings.Contains(out.String(), `"id":null`) {
+		t.Errorf(`expected "id":null in the parse error, got %s`, out.String())
+	}
+}
+
+func TestServeTruncatedInput(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+
+	responses := serveInput(t, s, `{"jsonrpc":"2.0","id":1,"method":`)
+
+	if len(responses) != 1 || responses[0].Error == nil || responses[0].Error.Code != -32700 {
+		t.Errorf("expected a single parse error, got %+v", responses)
+	}
+}
+
 func TestHandleInitialize(t *testing.T) {
 	s := &Server{weather: &mockWeather{}}
 	req := makeRequest("initialize", 1, nil)
@@ -66,6 +515,38 @@ func TestHandleInitialize(t *testing.T) {
 	}
 }
 
+func TestHandleInitializeWarmUp(t *testing.T) {
+	mock := &mockWeather{currentCalls: make(chan string, 1)}
+	s := &Server{weather: mock, config: Config{WarmupLocation: "Berlin"}}
+
+	resp := s.handleRequest(makeRequest("initialize", 1, nil))
+	if resp == nil || resp.Error != nil {
+		t.Fatalf("unexpected initialize response: %+v", resp)
+	}
+
+	select {
+	case location := <-mock.currentCalls:
+		if location != "Berlin" {
+			t.Errorf("expected warm-up fetch for Berlin, got %s", location)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected warm-up fetch after initialize")
+	}
+}
+
+func TestHandleInitializeNoWarmUp(t *testing.T) {
+	mock := &mockWeather{currentCalls: make(chan string, 1)}
+	s := &Server{weather: mock}
+
+	s.handleRequest(makeRequest("initialize", 1, nil))
+
+	select {
+	case location := <-mock.currentCalls:
+		t.Errorf("unexpected warm-up fetch for %s", location)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
 func TestHandleInitialized(t *testing.T) {
 	s := &Server{weather: &mockWeather{}}
 	req := makeRequest("initialized", nil, nil)
@@ -95,8 +576,8 @@ func TestHandleToolsList(t *testing.T) {
 		t.Fatal("tools is not a slice")
 	}
 
-	if len(tools) != 3 {
-		t.Fatalf("expected 3 tools, got %d", len(tools))
+	if len(tools) != 42 {
+		t.Fatalf("expected 42 tools, got %d", len(tools))
 	}
 
 	names := map[string]bool{}
@@ -104,13 +585,26 @@ func TestHandleToolsList(t *testing.T) {
 		names[tool["name"].(string)] = true
 	}
 
-	for _, expected := range []string{"get_current_weather", "get_forecast", "get_weather_detailed"} {
+	for _, expected := range []string{"get_current_weather", "get_forecast", "get_weather_detailed", "get_weather_score", "get_weekend", "get_sun_safety", "get_daylight", "get_nowcast", "get_on_this_day", "get_day_segments", "get_antipode_weather", "get_commute", "get_wind_forecast", "get_activity_suitability", "get_temp_extremes", "geocode", "get_weather_overview", "get_day_band", "get_raw", "get_day_delta", "get_moon_phase", "get_weather_ssml", "get_weather_card", "get_tomorrow", "get_precip_forecast", "get_short_trend", "get_briefing", "get_area_grid", "get_weather_by_code", "get_forecast_ics", "get_week_strip", "get_best_day", "get_comfort", "get_events_weather", "get_outlook", "rank_by_temperature", "get_threshold_check", "get_flight_weather", "get_emoji_forecast", "get_weather_a11y", "get_snapshot", "get_nicest_window"} {
 		if !names[expected] {
 			t.Errorf("missing tool: %s", expected)
 		}
 	}
 }
 
+func TestToolsListSorted(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+	resp := s.handleRequest(makeRequest("tools/list", 1, nil))
+
+	tools := resp.Result.(map[string]interface{})["tools"].([]map[string]interface{})
+	for i := 1; i < len(tools); i++ {
+		prev, cur := tools[i-1]["name"].(string), tools[i]["name"].(string)
+		if prev >= cur {
+			t.Errorf("tools not sorted by name: %s before %s", prev, cur)
+		}
+	}
+}
+
 func TestToolsListLocationRequired(t *testing.T) {
 	s := &Server{weather: &mockWeather{}}
 	req := makeRequest("tools/list", 1, nil)
@@ -120,6 +614,17 @@ func TestToolsListLocationRequired(t *testing.T) {
 	tools := result["tools"].([]map[string]interface{})
 
 	for _, tool := range tools {
+		// The moon phase is computed locally and needs no location, and
+		// the area code stands in for one.
+		if tool["name"] == "get_moon_phase" || tool["name"] == "get_weather_by_code" {
+			continue
+		}
+		// Calendar events and rankings carry their own locations, and
+		// flights take airport codes.
+		if tool["name"] == "get_events_weather" || tool["name"] == "rank_by_temperature" || tool["name"] == "get_flight_weather" {
+			continue
+		}
+
 		schema := tool["inputSchema"].(map[string]interface{})
 		required, ok := schema["required"].([]string)
 		if !ok {
@@ -162,6 +667,42 @@ func TestCallGetCurrent(t *testing.T) {
 	assertSuccessText(t, resp, "London: ☀️ +20°C (19°C) 45% ↑5km/h")
 }
 
+func TestCallGetCurrentFields(t *testing.T) {
+	mock := &mockWeather{currentResult: "London: ☀️ +20°C 0.0mm 1015hPa"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_current_weather",
+		"arguments": map[string]interface{}{"location": "London", "fields": []string{"precipitation", "pressure"}},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	if strings.Join(mock.lastFields, ",") != "precipitation,pressure" {
+		t.Errorf("expected fields to be passed through, got %v", mock.lastFields)
+	}
+}
+
+func TestCallGetCurrentUnknownField(t *testing.T) {
+	mock := &mockWeather{}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_current_weather",
+		"arguments": map[string]interface{}{"location": "London", "fields": []string{"pressure", "pollen"}},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("expected -32602 for unknown field, got %+v", resp.Error)
+	}
+	if mock.lastLocation != "" {
+		t.Error("weather should not be fetched for unknown fields")
+	}
+}
+
 func TestCallGetCurrentMissingLocation(t *testing.T) {
 	s := &Server{weather: &mockWeather{}}
 
@@ -180,6 +721,90 @@ func TestCallGetCurrentMissingLocation(t *testing.T) {
 	}
 }
 
+func TestCallNullArguments(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+
+	req := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name": "get_current_weather", "arguments": null}`),
+	}
+	resp := s.handleRequest(req)
+
+	if resp.Error == nil {
+		t.Fatal("expected error for null arguments")
+	}
+	if resp.Error.Code != -32602 {
+		t.Errorf("expected error code -32602, got %d", resp.Error.Code)
+	}
+	if resp.Error.Message != "location is required" {
+		t.Errorf("expected missing location message, got %q", resp.Error.Message)
+	}
+}
+
+func TestCallMissingArguments(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+
+	params := map[string]interface{}{"name": "get_forecast"}
+	req := makeRequest("tools/call", 1, params)
+	resp := s.handleRequest(req)
+
+	if resp.Error == nil {
+		t.Fatal("expected error for missing arguments")
+	}
+	if resp.Error.Message != "location is required" {
+		t.Errorf("expected missing location message, got %q", resp.Error.Message)
+	}
+}
+
+func TestCallGetCurrentResolveName(t *testing.T) {
+	mock := &mockWeather{
+		currentResult: ": ☀️ +20°C (+19°C) 45% ↖5km/h",
+		geocodeResult: `{"lat":51.517,"lon":-0.106,"area":"London","country":"United Kingdom"}`,
+	}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_current_weather",
+		"arguments": map[string]interface{}{"location": "51.5,-0.1", "resolve_name": true},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, "London, United Kingdom: ☀️ +20°C (+19°C) 45% ↖5km/h")
+
+	// Place names are left alone.
+	params["arguments"] = map[string]interface{}{"location": "London", "resolve_name": true}
+	mock.currentResult = "London: ☀️ +20°C"
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	assertSuccessText(t, resp, "London: ☀️ +20°C")
+}
+
+func TestCallGetForecastFormat(t *testing.T) {
+	mock := &mockWeather{forecastResult: "ascii forecast", summaryResult: "| Date |"}
+	s := &Server{weather: mock, config: Config{ForecastFormat: "markdown"}}
+
+	params := map[string]interface{}{
+		"name":      "get_forecast",
+		"arguments": map[string]interface{}{"location": "Oslo", "days": 2},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+	assertSuccessText(t, resp, "| Date |")
+	if mock.lastFormat != "markdown" || mock.lastDays != 2 {
+		t.Errorf("expected the configured markdown default for 2 days, got %q for %d", mock.lastFormat, mock.lastDays)
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "Oslo", "format": "ascii"}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	assertSuccessText(t, resp, "ascii forecast")
+
+	params["arguments"] = map[string]interface{}{"location": "Oslo", "format": "csv"}
+	resp = s.handleRequest(makeRequest("tools/call", 3, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected invalid params error for an unknown format, got %+v", resp)
+	}
+}
+
 func TestCallGetForecast(t *testing.T) {
 	mock := &mockWeather{forecastResult: "forecast data"}
 	s := &Server{weather: mock}
@@ -205,27 +830,90 @@ func TestCallGetForecast(t *testing.T) {
 	assertSuccessText(t, resp, "forecast data")
 }
 
-func TestCallGetForecastDefaultDays(t *testing.T) {
+func TestCallGetForecastOptions(t *testing.T) {
 	mock := &mockWeather{forecastResult: "forecast"}
 	s := &Server{weather: mock}
 
 	params := map[string]interface{}{
 		"name":      "get_forecast",
-		"arguments": map[string]string{"location": "Berlin"},
+		"arguments": map[string]interface{}{"location": "Oslo", "days": 0, "options": []string{"narrow", "plain"}},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	if mock.lastDays != 0 || strings.Join(mock.lastOptions, ",") != "narrow,plain" {
+		t.Errorf("unexpected call: %d days with %v", mock.lastDays, mock.lastOptions)
 	}
-	req := makeRequest("tools/call", 1, params)
-	s.handleRequest(req)
 
-	if mock.lastDays != 3 {
-		t.Errorf("expected default 3 days, got %d", mock.lastDays)
+	params["arguments"] = map[string]interface{}{"location": "Oslo", "options": []string{"wide"}}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected invalid params error for an unknown option, got %+v", resp)
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "Oslo", "format": "markdown", "options": []string{"narrow"}}
+	resp = s.handleRequest(makeRequest("tools/call", 3, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected invalid params error for options with markdown, got %+v", resp)
+	}
+
+	// The configured default format counts too.
+	s.config.ForecastFormat = forecastFormatJSON
+	params["arguments"] = map[string]interface{}{"location": "Oslo", "options": []string{"quiet"}}
+	resp = s.handleRequest(makeRequest("tools/call", 4, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected invalid params error for options with the json default, got %+v", resp)
 	}
 }
 
-func TestCallGetForecastInvalidDays(t *testing.T) {
+func TestCallGetForecastDefaultDays(t *testing.T) {
 	mock := &mockWeather{forecastResult: "forecast"}
 	s := &Server{weather: mock}
 
-	for _, days := range []int{0, -1, 5, 100} {
+	params := map[string]interface{}{
+		"name":      "get_forecast",
+		"arguments": map[string]string{"location": "Berlin"},
+	}
+	req := makeRequest("tools/call", 1, params)
+	s.handleRequest(req)
+
+	if mock.lastDays != 3 {
+		t.Errorf("expected default 3 days, got %d", mock.lastDays)
+	}
+}
+
+func TestCallGetForecastConfiguredDays(t *testing.T) {
+	mock := &mockWeather{forecastResult: "forecast"}
+	days := 1
+	s := &Server{weather: mock, config: Config{ForecastDays: &days}}
+
+	params := map[string]interface{}{
+		"name":      "get_forecast",
+		"arguments": map[string]string{"location": "Berlin"},
+	}
+	s.handleRequest(makeRequest("tools/call", 1, params))
+	if mock.lastDays != 1 {
+		t.Errorf("expected the configured 1 day, got %d", mock.lastDays)
+	}
+
+	resp := s.handleRequest(makeRequest("tools/list", 2, nil))
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]map[string]interface{}) {
+		if tool["name"] != "get_forecast" {
+			continue
+		}
+		schema := tool["inputSchema"].(map[string]interface{})
+		if d := schema["properties"].(map[string]interface{})["days"].(map[string]interface{})["default"]; d != 1 {
+			t.Errorf("expected the advertised default to be 1, got %v", d)
+		}
+	}
+}
+
+func TestCallGetForecastInvalidDays(t *testing.T) {
+	mock := &mockWeather{forecastResult: "forecast"}
+	s := &Server{weather: mock}
+
+	for _, days := range []int{-1, 5, 100} {
 		params := map[string]interface{}{
 			"name":      "get_forecast",
 			"arguments": map[string]interface{}{"location": "Paris", "days": days},
@@ -239,6 +927,111 @@ func TestCallGetForecastInvalidDays(t *testing.T) {
 	}
 }
 
+func TestCallGetForecastTodayOnly(t *testing.T) {
+	mock := &mockWeather{forecastResult: "today"}
+	s := &Server{weather: mock, config: Config{ResourceThreshold: 1}}
+
+	params := map[string]interface{}{
+		"name":      "get_forecast",
+		"arguments": map[string]interface{}{"location": "Paris", "days": 0},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	if mock.lastDays != 0 {
+		t.Errorf("expected days 0 to pass through, got %d", mock.lastDays)
+	}
+	content := resp.Result.(map[string]interface{})["content"].([]map[string]interface{})
+	resource := content[0]["resource"].(map[string]string)
+	if resource["uri"] != "weather://forecast/Paris?days=0" {
+		t.Errorf("unexpected resource uri: %v", resource["uri"])
+	}
+}
+
+func TestCallGetForecastDaysAsString(t *testing.T) {
+	mock := &mockWeather{forecastResult: "forecast"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_forecast",
+		"arguments": map[string]interface{}{"location": "Rome", "days": "2"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	if mock.lastDays != 2 {
+		t.Errorf("expected days coerced to 2, got %d", mock.lastDays)
+	}
+}
+
+func TestCallNullIntegerKeepsDefault(t *testing.T) {
+	mock := &mockWeather{forecastResult: "forecast", commuteResult: "ok"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_forecast",
+		"arguments": map[string]interface{}{"location": "Rome", "days": nil},
+	}
+	if resp := s.handleRequest(makeRequest("tools/call", 1, params)); resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	if mock.lastDays != 3 {
+		t.Errorf("expected null days to keep the default 3, got %d", mock.lastDays)
+	}
+
+	params = map[string]interface{}{
+		"name":      "get_commute",
+		"arguments": map[string]interface{}{"location": "Leeds", "morning_hour": nil, "evening_hour": nil},
+	}
+	assertSuccessText(t, s.handleRequest(makeRequest("tools/call", 2, params)), "ok")
+	if mock.lastHours != [2]int{8, 18} {
+		t.Errorf("expected null hours to keep the defaults 8 and 18, got %v", mock.lastHours)
+	}
+}
+
+func TestCallGetForecastDaysNonNumericString(t *testing.T) {
+	mock := &mockWeather{forecastResult: "forecast"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_forecast",
+		"arguments": map[string]interface{}{"location": "Rome", "days": "abc"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	if resp.Error == nil {
+		t.Fatal("expected error for non-numeric days")
+	}
+	if resp.Error.Code != -32602 {
+		t.Errorf("expected error code -32602, got %d", resp.Error.Code)
+	}
+	if data, _ := resp.Error.Data.(string); !strings.Contains(data, `expected an integer, got "abc"`) {
+		t.Errorf("expected a clear error message, got %v", resp.Error.Data)
+	}
+	if mock.lastLocation != "" {
+		t.Error("weather should not be fetched for invalid arguments")
+	}
+}
+
+func TestCallGetForecastDaysAsNumber(t *testing.T) {
+	mock := &mockWeather{forecastResult: "forecast"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_forecast",
+		"arguments": map[string]interface{}{"location": "Rome", "days": 2},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	if mock.lastDays != 2 {
+		t.Errorf("expected 2 days, got %d", mock.lastDays)
+	}
+}
+
 func TestCallGetDetailed(t *testing.T) {
 	mock := &mockWeather{detailedResult: `{"current_condition": [{"temp_C": "25"}]}`}
 	s := &Server{weather: mock}
@@ -259,25 +1052,1306 @@ func TestCallGetDetailed(t *testing.T) {
 	}
 }
 
-func TestCallWeatherError(t *testing.T) {
-	mock := &mockWeather{err: fmt.Errorf("network timeout")}
+func TestCallGetDetailedFields(t *testing.T) {
+	mock := &mockWeather{detailedResult: `{"current_condition": [{"temp_C": "25", "humidity": "40", "windspeedKmph": "13", "uvIndex": "9"}]}`}
 	s := &Server{weather: mock}
 
 	params := map[string]interface{}{
-		"name":      "get_current_weather",
-		"arguments": map[string]string{"location": "Nowhere"},
+		"name":      "get_weather_detailed",
+		"arguments": map[string]interface{}{"location": "Dubai", "fields": []string{"temp_C", "humidity"}},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"current":{"humidity":"40","temp_C":"25"}}`)
+}
+
+func TestCallGetDetailedUnknownField(t *testing.T) {
+	mock := &mockWeather{detailedResult: `{"current_condition": [{"temp_C": "25", "humidity": "40"}]}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_weather_detailed",
+		"arguments": map[string]interface{}{"location": "Dubai", "fields": []string{"temp_C", "pollen"}},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"current":{"temp_C":"25"},"unknown_fields":["pollen"]}`)
+}
+
+func TestCallGetWeatherScore(t *testing.T) {
+	mock := &mockWeather{scoreResult: `{"score":82}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_weather_score",
+		"arguments": map[string]string{"location": "Lisbon"},
 	}
 	req := makeRequest("tools/call", 1, params)
 	resp := s.handleRequest(req)
 
 	if resp.Error != nil {
-		t.Fatal("weather errors should be returned as isError result, not RPC error")
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	if mock.lastLocation != "Lisbon" {
+		t.Errorf("expected location Lisbon, got %s", mock.lastLocation)
+	}
+
+	assertSuccessText(t, resp, `{"score":82}`)
+}
+
+func TestCallGetWeatherScoreMissingLocation(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+
+	params := map[string]interface{}{
+		"name":      "get_weather_score",
+		"arguments": map[string]string{},
+	}
+	req := makeRequest("tools/call", 1, params)
+	resp := s.handleRequest(req)
+
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("expected -32602 error for missing location, got %+v", resp.Error)
+	}
+}
+
+func TestCallGetWeekend(t *testing.T) {
+	mock := &mockWeather{weekendResult: `{"today":"2024-06-14"}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_weekend",
+		"arguments": map[string]string{"location": "Paris"},
+	}
+	req := makeRequest("tools/call", 1, params)
+	resp := s.handleRequest(req)
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	if mock.lastLocation != "Paris" {
+		t.Errorf("expected location Paris, got %s", mock.lastLocation)
+	}
+
+	assertSuccessText(t, resp, `{"today":"2024-06-14"}`)
+}
+
+func TestCallGetDetailedAsResource(t *testing.T) {
+	detailed := `{"current_condition": [{"temp_C": "25"}]}`
+	mock := &mockWeather{detailedResult: detailed}
+	s := &Server{weather: mock, config: Config{ResourceThreshold: 16}}
+
+	params := map[string]interface{}{
+		"name":      "get_weather_detailed",
+		"arguments": map[string]string{"location": "New York"},
+	}
+	req := makeRequest("tools/call", 1, params)
+	resp := s.handleRequest(req)
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
 	}
 
 	result := resp.Result.(map[string]interface{})
-	isError, ok := result["isError"]
-	if !ok || isError != true {
-		t.Error("expected isError: true in result")
+	content, ok := result["content"].([]map[string]interface{})
+	if !ok || len(content) != 1 {
+		t.Fatalf("unexpected content: %#v", result["content"])
+	}
+	if content[0]["type"] != "resource" {
+		t.Fatalf("expected resource content, got %v", content[0]["type"])
+	}
+
+	resource := content[0]["resource"].(map[string]string)
+	if resource["uri"] != "weather://detailed/New%20York" {
+		t.Errorf("unexpected uri: %s", resource["uri"])
+	}
+	if resource["mimeType"] != "application/json" {
+		t.Errorf("unexpected mime type: %s", resource["mimeType"])
+	}
+	if resource["text"] != detailed {
+		t.Errorf("unexpected text: %s", resource["text"])
+	}
+}
+
+func TestCallGetDetailedBelowResourceThreshold(t *testing.T) {
+	detailed := `{"current_condition": [{"temp_C": "25"}]}`
+	mock := &mockWeather{detailedResult: detailed}
+	s := &Server{weather: mock, config: Config{ResourceThreshold: 1024}}
+
+	params := map[string]interface{}{
+		"name":      "get_weather_detailed",
+		"arguments": map[string]string{"location": "Dubai"},
+	}
+	req := makeRequest("tools/call", 1, params)
+	resp := s.handleRequest(req)
+
+	assertSuccessText(t, resp, detailed)
+}
+
+func TestCallGetDetailedResourcesDisabled(t *testing.T) {
+	detailed := `{"current_condition": [{"temp_C": "25"}]}`
+	s := &Server{weather: &mockWeather{detailedResult: detailed}}
+
+	params := map[string]interface{}{
+		"name":      "get_weather_detailed",
+		"arguments": map[string]string{"location": "Dubai"},
+	}
+	req := makeRequest("tools/call", 1, params)
+	resp := s.handleRequest(req)
+
+	assertSuccessText(t, resp, detailed)
+}
+
+func TestCallGetNowcast(t *testing.T) {
+	tests := []struct {
+		minutes interface{}
+		want    int
+	}{
+		{nil, 60},
+		{30, 30},
+		{"90", 90},
+		{500, 180},
+	}
+
+	for _, tt := range tests {
+		mock := &mockWeather{nowcastResult: `{"chance_of_rain":35}`}
+		s := &Server{weather: mock}
+
+		args := map[string]interface{}{"location": "Dublin"}
+		if tt.minutes != nil {
+			args["minutes"] = tt.minutes
+		}
+		params := map[string]interface{}{"name": "get_nowcast", "arguments": args}
+		resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+		if resp.Error != nil {
+			t.Errorf("minutes=%v: unexpected error: %v", tt.minutes, resp.Error)
+			continue
+		}
+		if mock.lastMinutes != tt.want {
+			t.Errorf("minutes=%v: expected %d, got %d", tt.minutes, tt.want, mock.lastMinutes)
+		}
+	}
+}
+
+func TestCallGetNowcastInvalidMinutes(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+
+	params := map[string]interface{}{
+		"name":      "get_nowcast",
+		"arguments": map[string]interface{}{"location": "Dublin", "minutes": 0},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("expected -32602 for zero minutes, got %+v", resp.Error)
+	}
+}
+
+func TestCallGetDaySegments(t *testing.T) {
+	mock := &mockWeather{segmentsResult: `{"segments":[]}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_day_segments",
+		"arguments": map[string]interface{}{"location": "Rome", "day_offset": "1"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"segments":[]}`)
+	if mock.lastDayOffset != 1 {
+		t.Errorf("expected day offset 1, got %d", mock.lastDayOffset)
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "Rome", "day_offset": 3}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected -32602 for day_offset 3, got %+v", resp.Error)
+	}
+}
+
+func TestCallGetWindForecast(t *testing.T) {
+	mock := &mockWeather{windResult: `{"slots":[]}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_wind_forecast",
+		"arguments": map[string]interface{}{"location": "Tarifa", "day_offset": 2},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"slots":[]}`)
+	if mock.lastLocation != "Tarifa" || mock.lastDayOffset != 2 {
+		t.Errorf("unexpected call: %s day %d", mock.lastLocation, mock.lastDayOffset)
+	}
+}
+
+func TestCallGetActivitySuitability(t *testing.T) {
+	mock := &mockWeather{activityResult: `{"verdict":"good"}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_activity_suitability",
+		"arguments": map[string]string{"location": "Oslo", "activity": "cycling"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+	assertSuccessText(t, resp, `{"verdict":"good"}`)
+	if mock.lastActivity != "cycling" {
+		t.Errorf("expected cycling, got %q", mock.lastActivity)
+	}
+
+	params["arguments"] = map[string]string{"location": "Oslo", "activity": "skydiving"}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected -32602 for an unknown activity, got %+v", resp.Error)
+	}
+}
+
+func TestCallGetTempExtremes(t *testing.T) {
+	mock := &mockWeather{extremesResult: `{"coldest":{}}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_temp_extremes",
+		"arguments": map[string]interface{}{"location": "Madrid"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"coldest":{}}`)
+	if mock.lastDayOffset != 0 {
+		t.Errorf("expected today by default, got day %d", mock.lastDayOffset)
+	}
+}
+
+func TestCallGetRaw(t *testing.T) {
+	mock := &mockWeather{rawResult: "London: +15°C"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_raw",
+		"arguments": map[string]interface{}{"location": "London", "raw_query": "format=%l:+%t"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, "London: +15°C")
+	if mock.lastRawQuery != "format=%l:+%t" {
+		t.Errorf("unexpected raw query: %q", mock.lastRawQuery)
+	}
+}
+
+func TestCallGetRawRejectsInjection(t *testing.T) {
+	mock := &mockWeather{}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_raw",
+		"arguments": map[string]interface{}{"location": "London", "raw_query": "format=3@evil.example/"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("expected invalid params error, got %+v", resp)
+	}
+	if len(mock.locations) != 0 {
+		t.Error("expected nothing to be fetched")
+	}
+}
+
+func TestCallGetDayDelta(t *testing.T) {
+	mock := &mockWeather{dayDeltaResult: `{"max_temp_delta_c":5}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_day_delta",
+		"arguments": map[string]interface{}{"location": "Berlin", "day_a": 1, "day_b": 2},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"max_temp_delta_c":5}`)
+	if mock.lastDayPair != [2]int{1, 2} {
+		t.Errorf("unexpected days: %v", mock.lastDayPair)
+	}
+}
+
+func TestCallGetDayDeltaOutOfWindow(t *testing.T) {
+	for _, args := range []map[string]interface{}{
+		{"location": "Berlin", "day_a": 0, "day_b": 3},
+		{"location": "Berlin", "day_a": -1, "day_b": 1},
+		{"location": "Berlin", "day_a": 0},
+	} {
+		mock := &mockWeather{}
+		s := &Server{weather: mock}
+
+		params := map[string]interface{}{"name": "get_day_delta", "arguments": args}
+		resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("%v: expected invalid params error, got %+v", args, resp)
+		}
+		if len(mock.locations) != 0 {
+			t.Errorf("%v: expected nothing to be fetched", args)
+		}
+	}
+}
+
+func TestCallGetAreaGrid(t *testing.T) {
+	mock := &mockWeather{areaGridResult: `{"temp_spread_c":3}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_area_grid",
+		"arguments": map[string]interface{}{"location": "Brighton"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"temp_spread_c":3}`)
+	if mock.lastLocation != "Brighton" || mock.lastRadiusKm != defaultGridRadiusKm {
+		t.Errorf("unexpected call: %s, %g km", mock.lastLocation, mock.lastRadiusKm)
+	}
+}
+
+func TestCallGetAreaGridRadiusOutOfRange(t *testing.T) {
+	for _, radius := range []float64{0, -1, 26} {
+		mock := &mockWeather{}
+		s := &Server{weather: mock}
+
+		params := map[string]interface{}{
+			"name":      "get_area_grid",
+			"arguments": map[string]interface{}{"location": "Brighton", "radius_km": radius},
+		}
+		resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("%g: expected invalid params error, got %+v", radius, resp)
+		}
+		if len(mock.locations) != 0 {
+			t.Errorf("%g: expected nothing to be fetched", radius)
+		}
+	}
+}
+
+func TestCallGetWeatherByCode(t *testing.T) {
+	mock := &mockWeather{byCodeResult: "New York (212): ☀️ +20°C"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_weather_by_code",
+		"arguments": map[string]interface{}{"code": "212"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, "New York (212): ☀️ +20°C")
+	if mock.lastCode != "212" {
+		t.Errorf("unexpected code: %q", mock.lastCode)
+	}
+}
+
+func TestCallGetWeatherByCodeUnknown(t *testing.T) {
+	mock := &mockWeather{}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_weather_by_code",
+		"arguments": map[string]interface{}{"code": "999"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("expected invalid params error, got %+v", resp)
+	}
+	if mock.lastCode != "" {
+		t.Error("expected nothing to be fetched")
+	}
+}
+
+func TestCallGetForecastICS(t *testing.T) {
+	mock := &mockWeather{icsResult: "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_forecast_ics",
+		"arguments": map[string]interface{}{"location": "Oslo"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+	if mock.lastDays != 3 {
+		t.Errorf("expected the default of 3 days, got %d", mock.lastDays)
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "Oslo", "days": 0}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected invalid params error for 0 days, got %+v", resp)
+	}
+}
+
+func TestCallGetBestDay(t *testing.T) {
+	mock := &mockWeather{bestDayResult: `{"activity":"hiking"}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_best_day",
+		"arguments": map[string]interface{}{"location": "Innsbruck", "activity": "hiking"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"activity":"hiking"}`)
+	if mock.lastActivity != "hiking" {
+		t.Errorf("unexpected activity: %q", mock.lastActivity)
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "Innsbruck", "activity": "kayaking"}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected invalid params error for an unknown activity, got %+v", resp)
+	}
+}
+
+func TestCallGetEventsWeather(t *testing.T) {
+	mock := &mockWeather{eventsResult: `[]`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name": "get_events_weather",
+		"arguments": map[string]interface{}{"events": []map[string]string{
+			{"title": "Picnic", "location": "London", "datetime": "2024-06-12T14:30"},
+		}},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `[]`)
+	if len(mock.lastEvents) != 1 || mock.lastEvents[0] != (Event{Title: "Picnic", Location: "London", Datetime: "2024-06-12T14:30"}) {
+		t.Errorf("unexpected events: %+v", mock.lastEvents)
+	}
+
+	params["arguments"] = map[string]interface{}{"events": []map[string]string{{"location": "London", "datetime": "noon"}}}
+	mock.lastEvents = nil
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected invalid params error for a bad datetime, got %+v", resp)
+	}
+	if mock.lastEvents != nil {
+		t.Error("expected nothing to be fetched")
+	}
+}
+
+func TestCallRankByTemperature(t *testing.T) {
+	mock := &mockWeather{rankResult: `[]`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "rank_by_temperature",
+		"arguments": map[string]interface{}{"locations": []string{"Oslo", "Madrid"}},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `[]`)
+	if strings.Join(mock.lastRanked, ",") != "Oslo,Madrid" {
+		t.Errorf("unexpected locations: %v", mock.lastRanked)
+	}
+
+	params["arguments"] = map[string]interface{}{"locations": []string{}}
+	mock.lastRanked = nil
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected invalid params error for no locations, got %+v", resp)
+	}
+	if mock.lastRanked != nil {
+		t.Error("expected nothing to be fetched")
+	}
+}
+
+func TestCallGetThresholdCheck(t *testing.T) {
+	mock := &mockWeather{thresholdResult: `{"crossed":true}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_threshold_check",
+		"arguments": map[string]interface{}{"location": "Seville", "temp_c": 30, "direction": "above"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"crossed":true}`)
+	if mock.lastLocation != "Seville" || mock.lastThreshold != 30 || mock.lastDirection != "above" {
+		t.Errorf("unexpected call: %s %g %s", mock.lastLocation, mock.lastThreshold, mock.lastDirection)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"location": "Seville", "direction": "above"},
+		{"location": "Seville", "temp_c": 30, "direction": "over"},
+	} {
+		params["arguments"] = args
+		resp = s.handleRequest(makeRequest("tools/call", 2, params))
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("%v: expected invalid params error, got %+v", args, resp)
+		}
+	}
+}
+
+func TestCallGetFlightWeather(t *testing.T) {
+	mock := &mockWeather{flightResult: "MUC (origin): ☀️ +20°C\nJFK (destination): 🌧 +15°C"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_flight_weather",
+		"arguments": map[string]interface{}{"origin": "MUC", "destination": "jfk"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, mock.flightResult)
+	if mock.lastRoute != [2]string{"MUC", "jfk"} {
+		t.Errorf("unexpected route: %v", mock.lastRoute)
+	}
+
+	params["arguments"] = map[string]interface{}{"origin": "MUC", "destination": "JFK1"}
+	mock.lastRoute = [2]string{}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected invalid params error for a bad destination code, got %+v", resp)
+	}
+	if mock.lastRoute != [2]string{} {
+		t.Error("expected nothing to be fetched")
+	}
+}
+
+func TestCallGetMoonPhase(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+
+	params := map[string]interface{}{
+		"name":      "get_moon_phase",
+		"arguments": map[string]interface{}{"date": "2024-06-18"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"phase":"Waxing Gibbous","illumination_percent":88,"next_full":"2024-06-22"}`)
+
+	params["arguments"] = map[string]interface{}{"date": "18.06.2024"}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected invalid params error for a malformed date, got %+v", resp)
+	}
+}
+
+func TestCallGetPrecipForecast(t *testing.T) {
+	mock := &mockWeather{precipResult: `{"days":[]}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_precip_forecast",
+		"arguments": map[string]interface{}{"location": "Bergen", "days": 2},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"days":[]}`)
+	if mock.lastDays != 2 {
+		t.Errorf("expected 2 days, got %d", mock.lastDays)
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "Bergen", "days": 5}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected invalid params error for 5 days, got %+v", resp)
+	}
+}
+
+func TestCallGetCommute(t *testing.T) {
+	mock := &mockWeather{commuteResult: "ok"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_commute",
+		"arguments": map[string]interface{}{"location": "Leeds"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+	assertSuccessText(t, resp, "ok")
+	if mock.lastHours != [2]int{8, 18} {
+		t.Errorf("expected default hours 8 and 18, got %v", mock.lastHours)
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "Leeds", "morning_hour": "7", "evening_hour": 17}
+	s.handleRequest(makeRequest("tools/call", 2, params))
+	if mock.lastHours != [2]int{7, 17} {
+		t.Errorf("expected hours 7 and 17, got %v", mock.lastHours)
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "Leeds", "evening_hour": 24}
+	resp = s.handleRequest(makeRequest("tools/call", 3, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected -32602 for hour 24, got %+v", resp.Error)
+	}
+}
+
+func TestCallLocationOnlyTools(t *testing.T) {
+	tests := []struct {
+		tool string
+		mock *mockWeather
+	}{
+		{"get_sun_safety", &mockWeather{sunSafetyResult: "result"}},
+		{"get_daylight", &mockWeather{daylightResult: "result"}},
+		{"get_on_this_day", &mockWeather{onThisDayResult: "result"}},
+		{"get_antipode_weather", &mockWeather{antipodeResult: "result"}},
+		{"geocode", &mockWeather{geocodeResult: "result"}},
+		{"get_weather_overview", &mockWeather{overviewResult: "result"}},
+		{"get_day_band", &mockWeather{dayBandResult: "result"}},
+		{"get_weather_ssml", &mockWeather{ssmlResult: "result"}},
+		{"get_weather_card", &mockWeather{cardResult: "result"}},
+		{"get_tomorrow", &mockWeather{tomorrowResult: "result"}},
+		{"get_short_trend", &mockWeather{shortTrendResult: "result"}},
+		{"get_briefing", &mockWeather{briefingResult: "result"}},
+		{"get_week_strip", &mockWeather{weekStripResult: "result"}},
+		{"get_comfort", &mockWeather{comfortResult: "result"}},
+		{"get_outlook", &mockWeather{outlookResult: "result"}},
+		{"get_emoji_forecast", &mockWeather{emojiResult: "result"}},
+		{"get_weather_a11y", &mockWeather{a11yResult: "result"}},
+		{"get_snapshot", &mockWeather{snapshotResult: "result"}},
+		{"get_nicest_window", &mockWeather{nicestResult: "result"}},
+	}
+
+	for _, tt := range tests {
+		s := &Server{weather: tt.mock}
+
+		params := map[string]interface{}{
+			"name":      tt.tool,
+			"arguments": map[string]string{"location": "Oslo"},
+		}
+		resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+		if resp.Error != nil {
+			t.Errorf("%s: unexpected error: %v", tt.tool, resp.Error)
+			continue
+		}
+		if tt.mock.lastLocation != "Oslo" {
+			t.Errorf("%s: expected location Oslo, got %s", tt.tool, tt.mock.lastLocation)
+		}
+		assertSuccessText(t, resp, "result")
+
+		params["arguments"] = map[string]string{}
+		resp = s.handleRequest(makeRequest("tools/call", 1, params))
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("%s: expected -32602 for missing location, got %+v", tt.tool, resp.Error)
+		}
+	}
+}
+
+func TestCallGetProfilesWeather(t *testing.T) {
+	mock := &mockWeather{currentResults: map[string]string{
+		"Berlin": "Berlin: ☁️ +12°C\n",
+		"Lyon":   "Lyon: ☀️ +21°C\n",
+	}}
+	s := &Server{weather: mock, config: Config{Profiles: map[string]string{
+		"work":    "Berlin",
+		"parents": "Lyon",
+	}}}
+
+	params := map[string]interface{}{
+		"name":      "get_profiles_weather",
+		"arguments": map[string]interface{}{},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	if len(mock.locations) != 2 {
+		t.Errorf("expected both profiles to be fetched, got %v", mock.locations)
+	}
+
+	assertSuccessText(t, resp, "parents: Lyon: ☀️ +21°C\nwork: Berlin: ☁️ +12°C")
+}
+
+func TestCallGetProfilesWeatherNotConfigured(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+
+	params := map[string]interface{}{"name": "get_profiles_weather"}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	result := resp.Result.(map[string]interface{})
+	if result["isError"] != true {
+		t.Error("expected isError result without profiles")
+	}
+}
+
+func TestToolsListProfiles(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+	resp := s.handleRequest(makeRequest("tools/list", 1, nil))
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]map[string]interface{}) {
+		if tool["name"] == "get_profiles_weather" {
+			t.Error("profiles tool should not be listed without profiles")
+		}
+	}
+
+	s.config.Profiles = map[string]string{"work": "Berlin"}
+	resp = s.handleRequest(makeRequest("tools/list", 1, nil))
+	found := false
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]map[string]interface{}) {
+		if tool["name"] == "get_profiles_weather" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected profiles tool to be listed when profiles are configured")
+	}
+}
+
+func TestCallGetDebugHeaders(t *testing.T) {
+	s := &Server{weather: &mockWeather{headersResult: `{"headers":{}}`}}
+
+	params := map[string]interface{}{
+		"name":      "get_debug_headers",
+		"arguments": map[string]string{"location": "Oslo"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+	if result := resp.Result.(map[string]interface{}); result["isError"] != true {
+		t.Error("expected isError result with debug disabled")
+	}
+
+	s.config.Debug = true
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	assertSuccessText(t, resp, `{"headers":{}}`)
+
+	resp = s.handleRequest(makeRequest("tools/list", 3, nil))
+	found := false
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]map[string]interface{}) {
+		if tool["name"] == "get_debug_headers" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected debug tool to be listed with debug enabled")
+	}
+}
+
+func TestToolsListDescriptionOverride(t *testing.T) {
+	s := &Server{weather: &mockWeather{}, config: Config{ToolDescriptions: map[string]string{
+		"get_current_weather": "Use for quick checks",
+	}}}
+
+	resp := s.handleRequest(makeRequest("tools/list", 1, nil))
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]map[string]interface{}) {
+		switch tool["name"] {
+		case "get_current_weather":
+			if tool["description"] != "Use for quick checks" {
+				t.Errorf("expected overridden description, got %q", tool["description"])
+			}
+		case "get_forecast":
+			if tool["description"] != "Get weather forecast for a location (text format with ASCII art, or JSON or a Markdown table)" {
+				t.Errorf("expected default description, got %q", tool["description"])
+			}
+		}
+	}
+}
+
+func TestCallStrictSchema(t *testing.T) {
+	mock := &mockWeather{nowcastResult: "ok"}
+	s := &Server{weather: mock, config: Config{StrictSchema: true}}
+
+	params := map[string]interface{}{
+		"name":      "get_nowcast",
+		"arguments": map[string]interface{}{"minutes": "90"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("expected -32602, got %+v", resp.Error)
+	}
+	violations, ok := resp.Error.Data.([]string)
+	if !ok || len(violations) != 2 {
+		t.Fatalf("expected two violations, got %#v", resp.Error.Data)
+	}
+	if mock.lastLocation != "" {
+		t.Error("expected the tool not to be called")
+	}
+
+	// The lenient handlers accept numeric strings; strict mode does not, but
+	// valid arguments still go through.
+	params["arguments"] = map[string]interface{}{"location": "Dublin", "minutes": 90}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	assertSuccessText(t, resp, "ok")
+}
+
+func TestCallTimezone(t *testing.T) {
+	mock := &mockWeather{daylightResult: "ok"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_daylight",
+		"arguments": map[string]interface{}{"location": "London", "tz": "America/New_York"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, "ok")
+	if mock.tz == nil || mock.tz.String() != "America/New_York" {
+		t.Errorf("expected America/New_York, got %v", mock.tz)
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "London", "tz": "Mars/Olympus_Mons"}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected -32602 for an invalid timezone, got %+v", resp.Error)
+	}
+}
+
+func TestCallTimeFormatEpoch(t *testing.T) {
+	mock := &mockWeather{daylightResult: "ok"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_daylight",
+		"arguments": map[string]interface{}{"location": "London", "time_format": "epoch"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, "ok")
+	if !mock.epoch {
+		t.Error("expected epoch times to be requested")
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "London", "time_format": "iso"}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected -32602 for an unknown time format, got %+v", resp.Error)
+	}
+}
+
+func TestCallUnitsBoth(t *testing.T) {
+	mock := &mockWeather{dayBandResult: "ok"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_day_band",
+		"arguments": map[string]interface{}{"location": "London", "units": "both"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, "ok")
+	if !mock.bothUnits {
+		t.Error("expected both units to be requested")
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "London", "units": "kelvin"}
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("expected -32602 for unknown units, got %+v", resp.Error)
+	}
+}
+
+func TestCallIncludeRawHourly(t *testing.T) {
+	mock := &mockWeather{tomorrowResult: "ok", currentResult: "ok"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_tomorrow",
+		"arguments": map[string]interface{}{"location": "London"},
+	}
+	s.handleRequest(makeRequest("tools/call", 1, params))
+	if mock.rawHourly {
+		t.Error("expected no raw hourly data by default")
+	}
+
+	params["arguments"] = map[string]interface{}{"location": "London", "include_raw_hourly": true}
+	resp := s.handleRequest(makeRequest("tools/call", 2, params))
+	assertSuccessText(t, resp, "ok")
+	if !mock.rawHourly {
+		t.Error("expected raw hourly data to be requested")
+	}
+
+	// Tools without day summaries ignore the flag.
+	mock.rawHourly = false
+	params["name"] = "get_current_weather"
+	s.handleRequest(makeRequest("tools/call", 3, params))
+	if mock.rawHourly {
+		t.Error("expected get_current_weather to ignore include_raw_hourly")
+	}
+}
+
+func TestCallSchemaVersion(t *testing.T) {
+	mock := &mockWeather{
+		weekendResult: `{"today":"2024-06-12","days":[]}`,
+		currentResult: "London: ☀️ +20°C",
+	}
+	s := &Server{weather: mock, config: Config{SchemaVersion: true}}
+
+	params := map[string]interface{}{
+		"name":      "get_weekend",
+		"arguments": map[string]string{"location": "London"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+	assertSuccessText(t, resp, fmt.Sprintf(`{"schema_version":%d,"today":"2024-06-12","days":[]}`, schemaVersion))
+
+	// Text results are left alone.
+	params["name"] = "get_current_weather"
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	assertSuccessText(t, resp, "London: ☀️ +20°C")
+}
+
+func TestCallSchemaRef(t *testing.T) {
+	mock := &mockWeather{
+		weekendResult: `{"today":"2024-06-12","days":[]}`,
+		currentResult: "London: ☀️ +20°C",
+	}
+	s := &Server{weather: mock, config: Config{SchemaVersion: true, SchemaRef: true}}
+
+	params := map[string]interface{}{
+		"name":      "get_weekend",
+		"arguments": map[string]string{"location": "London"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	text, _ := successText(resp)
+	var result struct {
+		Schema        string `json:"$schema"`
+		SchemaVersion int    `json:"schema_version"`
+	}
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", text, err)
+	}
+	if want := fmt.Sprintf("urn:wttr-weather-mcp:get_weekend:v%d", result.SchemaVersion); result.Schema != want {
+		t.Errorf("expected $schema %q matching the result version, got %q", want, result.Schema)
+	}
+	if !strings.HasPrefix(text, `{"$schema":`) {
+		t.Errorf("expected $schema first, got %s", text)
+	}
+
+	// Text results are left alone.
+	params["name"] = "get_current_weather"
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	assertSuccessText(t, resp, "London: ☀️ +20°C")
+}
+
+func TestWithResultFieldEmptyObject(t *testing.T) {
+	s := &Server{}
+
+	resp := s.withResultField(s.successResponse(1, "{}"), "schema_version", 1)
+	assertSuccessText(t, resp, `{"schema_version":1}`)
+}
+
+func TestCallInvalidCoordinates(t *testing.T) {
+	for _, location := range []string{"100,20", "45,200", "-90.5,0"} {
+		mock := &mockWeather{currentResult: "ok"}
+		s := &Server{weather: mock}
+
+		params := map[string]interface{}{
+			"name":      "get_current_weather",
+			"arguments": map[string]string{"location": location},
+		}
+		resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("%s: expected -32602, got %+v", location, resp.Error)
+		}
+		if mock.lastLocation != "" {
+			t.Errorf("%s: expected no fetch", location)
+		}
+	}
+}
+
+func TestCallGetDetailedConfidence(t *testing.T) {
+	mock := &mockWeather{detailedResult: `{"nearest_area":[{"areaName":[{"value":"Lyon"}]}]}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_weather_detailed",
+		"arguments": map[string]string{"location": "Paris"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"confidence":"low","nearest_area":[{"areaName":[{"value":"Lyon"}]}]}`)
+}
+
+func TestCallGetDetailedDistance(t *testing.T) {
+	mock := &mockWeather{detailedResult: `{"nearest_area":[{"latitude":"48.857","longitude":"2.352"}]}`}
+	s := &Server{weather: mock, config: Config{Precision: 1}}
+
+	params := map[string]interface{}{
+		"name":      "get_weather_detailed",
+		"arguments": map[string]string{"location": "48.9,2.35"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	assertSuccessText(t, resp, `{"distance_km":4.8,"nearest_area":[{"latitude":"48.857","longitude":"2.352"}]}`)
+}
+
+func TestCallWeatherError(t *testing.T) {
+	mock := &mockWeather{err: fmt.Errorf("network timeout")}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_current_weather",
+		"arguments": map[string]string{"location": "Nowhere"},
+	}
+	req := makeRequest("tools/call", 1, params)
+	resp := s.handleRequest(req)
+
+	if resp.Error != nil {
+		t.Fatal("weather errors should be returned as isError result, not RPC error")
+	}
+
+	result := resp.Result.(map[string]interface{})
+	isError, ok := result["isError"]
+	if !ok || isError != true {
+		t.Error("expected isError: true in result")
+	}
+	if meta := result["_meta"].(map[string]string); meta["error_code"] != "internal_error" {
+		t.Errorf("unexpected error code: %v", meta)
+	}
+}
+
+func TestCallEchoMeta(t *testing.T) {
+	mock := &mockWeather{currentResult: "London: ☀️ +20°C"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_current_weather",
+		"arguments": map[string]string{"location": "London"},
+		"_meta":     map[string]interface{}{"correlation_id": "req-42", "progressToken": 7},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	out, err := json.Marshal(resp.Result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), `"_meta":{"correlation_id":"req-42"}`) {
+		t.Errorf("expected the correlation id alone to be echoed, got %s", out)
+	}
+
+	// Errors keep their code next to the echoed fields.
+	mock.err = errEmptyResponse
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	out, _ = json.Marshal(resp.Result)
+	if !strings.Contains(string(out), `"correlation_id":"req-42"`) || !strings.Contains(string(out), `"error_code":`) {
+		t.Errorf("expected the error code and correlation id, got %s", out)
+	}
+
+	s.config.EchoMeta = []string{"requestId"}
+	mock.err = nil
+	resp = s.handleRequest(makeRequest("tools/call", 3, params))
+	if _, ok := resp.Result.(map[string]interface{})["_meta"]; ok {
+		t.Errorf("expected nothing echoed for unconfigured fields, got %+v", resp.Result)
+	}
+}
+
+func TestCallEchoMetaInvalidArguments(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+
+	params := map[string]interface{}{
+		"name":      "get_daylight",
+		"arguments": map[string]string{"location": "London", "tz": "Mars/Olympus"},
+		"_meta":     map[string]interface{}{"correlation_id": "req-7"},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+	if resp.Error == nil || resp.Error.Message != "Invalid tz" {
+		t.Fatalf("expected an invalid tz error, got %+v", resp)
+	}
+	out, err := json.Marshal(resp.Error)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), `"_meta":{"correlation_id":"req-7"}`) {
+		t.Errorf("expected the correlation id echoed in the error, got %s", out)
+	}
+}
+
+func TestCallFooter(t *testing.T) {
+	mock := &mockWeather{currentResult: "London: ☀️ +20°C", scoreResult: `{"score":80}`}
+	s := &Server{weather: mock, config: Config{Footer: "Data from wttr.in"}}
+
+	current := map[string]interface{}{"name": "get_current_weather", "arguments": map[string]string{"location": "London"}}
+	resp := s.handleRequest(makeRequest("tools/call", 1, current))
+	assertSuccessText(t, resp, "London: ☀️ +20°C\n\nData from wttr.in")
+
+	score := map[string]interface{}{"name": "get_weather_score", "arguments": map[string]string{"location": "London"}}
+	resp = s.handleRequest(makeRequest("tools/call", 2, score))
+	assertSuccessText(t, resp, `{"footer":"Data from wttr.in","score":80}`)
+
+	s.config.Footer = ""
+	resp = s.handleRequest(makeRequest("tools/call", 3, current))
+	assertSuccessText(t, resp, "London: ☀️ +20°C")
+}
+
+func TestCallErrorStyle(t *testing.T) {
+	upstream := &statusError{StatusCode: 503, Body: "Service Unavailable"}
+	params := map[string]interface{}{
+		"name":      "get_current_weather",
+		"arguments": map[string]string{"location": "London"},
+	}
+
+	s := &Server{weather: &mockWeather{err: upstream}, config: Config{ErrorStyle: "content"}}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+	if resp.Error != nil {
+		t.Fatalf("content style: expected an isError result, got RPC error %+v", resp.Error)
+	}
+	if result := resp.Result.(map[string]interface{}); result["isError"] != true {
+		t.Errorf("content style: expected isError result, got %+v", result)
+	}
+
+	s.config.ErrorStyle = "rpc"
+	resp = s.handleRequest(makeRequest("tools/call", 2, params))
+	if resp.Result != nil || resp.Error == nil {
+		t.Fatalf("rpc style: expected an RPC error, got %+v", resp)
+	}
+	if resp.Error.Code != -32000 || resp.Error.Message != upstream.Error() {
+		t.Errorf("rpc style: unexpected error %+v", resp.Error)
+	}
+	if data := resp.Error.Data.(map[string]string); data["error_code"] != "upstream_unavailable" {
+		t.Errorf("rpc style: unexpected data %v", data)
+	}
+}
+
+func TestCallIncludeProvenance(t *testing.T) {
+	mock := &mockWeather{currentResult: "London: ☀️ +20°C"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_current_weather",
+		"arguments": map[string]interface{}{"location": "London", "include_provenance": true},
+	}
+	req := makeRequest("tools/call", 1, params)
+	resp := s.handleRequest(req)
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+
+	content := resp.Result.(map[string]interface{})["content"].([]map[string]string)
+	var wrapped struct {
+		Source    string `json:"source"`
+		URL       string `json:"url"`
+		FetchedAt string `json:"fetched_at"`
+		Data      string `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(content[0]["text"]), &wrapped); err != nil {
+		t.Fatalf("result is not a provenance wrapper: %v", err)
+	}
+
+	if wrapped.Source != "wttr.in" {
+		t.Errorf("expected source wttr.in, got %q", wrapped.Source)
+	}
+	if wrapped.URL != "http://wttr.in/London?format=3" {
+		t.Errorf("unexpected url: %q", wrapped.URL)
+	}
+	if wrapped.FetchedAt != "2024-06-12T14:35:00Z" {
+		t.Errorf("unexpected fetched_at: %q", wrapped.FetchedAt)
+	}
+	if wrapped.Data != "London: ☀️ +20°C" {
+		t.Errorf("unexpected data: %q", wrapped.Data)
+	}
+}
+
+func TestWithProvenanceSeveralFetches(t *testing.T) {
+	s := &Server{}
+	trace := &FetchTrace{}
+	trace.record("http://wttr.in/muc?format=j1", time.Date(2024, 6, 12, 14, 35, 0, 0, time.UTC))
+	trace.record("http://wttr.in/jfk?format=j1", time.Date(2024, 6, 12, 14, 35, 1, 0, time.UTC))
+
+	resp := s.withProvenance(s.successResponse(1, "MUC (origin): ☀️"), trace)
+
+	var wrapped struct {
+		URL     string             `json:"url"`
+		Sources []provenanceSource `json:"sources"`
+	}
+	text, _ := successText(resp)
+	if err := json.Unmarshal([]byte(text), &wrapped); err != nil {
+		t.Fatalf("result is not a provenance wrapper: %v", err)
+	}
+	if wrapped.URL != "http://wttr.in/muc?format=j1" {
+		t.Errorf("expected the first fetch as url, got %q", wrapped.URL)
+	}
+	want := []provenanceSource{
+		{URL: "http://wttr.in/muc?format=j1", FetchedAt: "2024-06-12T14:35:00Z"},
+		{URL: "http://wttr.in/jfk?format=j1", FetchedAt: "2024-06-12T14:35:01Z"},
+	}
+	if len(wrapped.Sources) != len(want) || wrapped.Sources[0] != want[0] || wrapped.Sources[1] != want[1] {
+		t.Errorf("expected every fetch in sources, got %+v", wrapped.Sources)
+	}
+}
+
+func TestCallIncludeProvenanceJSONData(t *testing.T) {
+	mock := &mockWeather{scoreResult: `{"score":82}`}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_weather_score",
+		"arguments": map[string]interface{}{"location": "Lisbon", "include_provenance": true},
+	}
+	req := makeRequest("tools/call", 1, params)
+	resp := s.handleRequest(req)
+
+	content := resp.Result.(map[string]interface{})["content"].([]map[string]string)
+	var wrapped struct {
+		Data struct {
+			Score int `json:"score"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(content[0]["text"]), &wrapped); err != nil {
+		t.Fatalf("expected JSON data to be embedded as an object: %v", err)
+	}
+	if wrapped.Data.Score != 82 {
+		t.Errorf("expected score 82, got %d", wrapped.Data.Score)
+	}
+}
+
+func TestCallIncludeProvenanceResource(t *testing.T) {
+	mock := &mockWeather{forecastResult: "Weather report: Paris"}
+	s := &Server{weather: mock, config: Config{ResourceThreshold: 1, Footer: "Data: wttr.in"}}
+
+	params := map[string]interface{}{
+		"name":      "get_forecast",
+		"arguments": map[string]interface{}{"location": "Paris", "days": 1, "include_provenance": true},
+	}
+	resp := s.handleRequest(makeRequest("tools/call", 1, params))
+
+	content, ok := resp.Result.(map[string]interface{})["content"].([]map[string]interface{})
+	if !ok || len(content) != 1 || content[0]["type"] != "resource" {
+		t.Fatalf("expected a resource result, got %#v", resp.Result)
+	}
+	resource := content[0]["resource"].(map[string]string)
+	if resource["uri"] != "weather://forecast/Paris?days=1" || resource["mimeType"] != "application/json" {
+		t.Errorf("unexpected resource: %v", resource)
+	}
+	var wrapped struct {
+		Source string `json:"source"`
+		Data   string `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(resource["text"]), &wrapped); err != nil {
+		t.Fatalf("resource is not a provenance wrapper: %v", err)
+	}
+	if wrapped.Source != "wttr.in" || wrapped.Data != "Weather report: Paris\n\nData: wttr.in" {
+		t.Errorf("expected the footed forecast wrapped with provenance, got %+v", wrapped)
+	}
+}
+
+func TestCallWithoutProvenance(t *testing.T) {
+	mock := &mockWeather{currentResult: "London: ☀️ +20°C"}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_current_weather",
+		"arguments": map[string]interface{}{"location": "London"},
+	}
+	req := makeRequest("tools/call", 1, params)
+	resp := s.handleRequest(req)
+
+	assertSuccessText(t, resp, "London: ☀️ +20°C")
+	if mock.trace != nil {
+		t.Error("expected no trace without include_provenance")
+	}
+}
+
+func TestCallProvenanceKeepsErrors(t *testing.T) {
+	mock := &mockWeather{err: fmt.Errorf("network timeout")}
+	s := &Server{weather: mock}
+
+	params := map[string]interface{}{
+		"name":      "get_current_weather",
+		"arguments": map[string]interface{}{"location": "Nowhere", "include_provenance": true},
+	}
+	req := makeRequest("tools/call", 1, params)
+	resp := s.handleRequest(req)
+
+	result := resp.Result.(map[string]interface{})
+	if result["isError"] != true {
+		t.Error("expected error result to pass through unwrapped")
 	}
 }
 
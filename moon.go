@@ -0,0 +1,94 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// synodicMonth is the mean length of a lunar cycle in days.
+const synodicMonth = 29.530588853
+
+// referenceNewMoon is the new moon of 6 January 2000, the epoch the mean
+// lunar cycle is counted from.
+var referenceNewMoon = time.Date(2000, time.January, 6, 18, 14, 0, 0, time.UTC)
+
+// moonPhaseNames are the eight phases, each centered on its point of the
+// cycle starting with the new moon.
+var moonPhaseNames = [8]string{
+	"New Moon", "Waxing Crescent", "First Quarter", "Waxing Gibbous",
+	"Full Moon", "Waning Gibbous", "Last Quarter", "Waning Crescent",
+}
+
+// MoonInfo is the phase of the moon at a time.
+type MoonInfo struct {
+	Phase               string `json:"phase"`
+	IlluminationPercent int    `json:"illumination_percent"`
+	NextFull            string `json:"next_full"`
+}
+
+// moonPhase computes the phase of the moon at t. The phase and
+// illumination come from the mean synodic month, which ignores the orbit's
+// eccentricity and can be off by up to about half a day; the next full moon
+// is computed precisely, since it is reported as a date.
+func moonPhase(t time.Time) MoonInfo {
+	days := t.Sub(referenceNewMoon).Hours() / 24
+	age := math.Mod(days, synodicMonth)
+	if age < 0 {
+		age += synodicMonth
+	}
+	fraction := age / synodicMonth
+
+	return MoonInfo{
+		Phase:               moonPhaseNames[int(math.Floor(fraction*8+0.5))%8],
+		IlluminationPercent: int(math.Round((1 - math.Cos(2*math.Pi*fraction)) / 2 * 100)),
+		NextFull:            nextFullMoon(t).Format(j1DateLayout),
+	}
+}
+
+// nextFullMoon returns the first full moon after t, in UTC, using the
+// algorithm from Meeus, Astronomical Algorithms, chapter 49, with its
+// largest periodic terms. It is accurate to a few minutes.
+func nextFullMoon(t time.Time) time.Time {
+	// Lunations are counted from the new moon of 6 January 2000; full
+	// moons fall on k + 0.5.
+	k := math.Floor(t.Sub(referenceNewMoon).Hours()/24/synodicMonth) - 0.5
+	for {
+		full := fullMoon(k)
+		if full.After(t) {
+			return full
+		}
+		k++
+	}
+}
+
+// fullMoon is the time of the full moon of lunation k + 0.5.
+func fullMoon(k float64) time.Time {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	T := k / 1236.85
+	E := 1 - 0.002516*T - 0.0000074*T*T
+	M := rad(2.5534 + 29.1053567*k)
+	Mp := rad(201.5643 + 385.81693528*k)
+	F := rad(160.7108 + 390.67050284*k)
+	Omega := rad(124.7746 - 1.56375588*k)
+
+	jde := 2451550.09766 + synodicMonth*k + 0.00015437*T*T
+	jde += -0.40614*math.Sin(Mp) +
+		0.17302*E*math.Sin(M) +
+		0.01614*math.Sin(2*Mp) +
+		0.01043*math.Sin(2*F) +
+		0.00734*E*math.Sin(Mp-M) -
+		0.00515*E*math.Sin(Mp+M) +
+		0.00209*E*E*math.Sin(2*M) -
+		0.00111*math.Sin(Mp-2*F) -
+		0.00057*math.Sin(Mp+2*F) +
+		0.00056*E*math.Sin(2*Mp+M) -
+		0.00042*math.Sin(3*Mp) +
+		0.00042*E*math.Sin(M+2*F) +
+		0.00038*E*math.Sin(M-2*F) -
+		0.00024*E*math.Sin(2*Mp-M) -
+		0.00017*math.Sin(Omega)
+
+	// Julian day 2440587.5 is the Unix epoch.
+	return time.Unix(0, 0).UTC().Add(time.Duration((jde - 2440587.5) * 24 * float64(time.Hour)))
+}
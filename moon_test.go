@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMoonPhase(t *testing.T) {
+	tests := []struct {
+		at           time.Time
+		phase        string
+		illumination int
+		nextFull     time.Time
+	}{
+		// New moon 2024-06-06 12:38 UTC, full moon 2024-06-22 01:08 UTC.
+		{time.Date(2024, 6, 6, 12, 0, 0, 0, time.UTC), "New Moon", 0, time.Date(2024, 6, 22, 0, 0, 0, 0, time.UTC)},
+		// First quarter 2024-06-14 05:18 UTC.
+		{time.Date(2024, 6, 14, 5, 0, 0, 0, time.UTC), "First Quarter", 50, time.Date(2024, 6, 22, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 6, 18, 12, 0, 0, 0, time.UTC), "Waxing Gibbous", 85, time.Date(2024, 6, 22, 0, 0, 0, 0, time.UTC)},
+		// Full moon 2024-06-22; the next one is 2024-07-21 10:17 UTC.
+		{time.Date(2024, 6, 22, 3, 0, 0, 0, time.UTC), "Full Moon", 100, time.Date(2024, 7, 21, 0, 0, 0, 0, time.UTC)},
+		// Last quarter 2024-06-28 21:53 UTC.
+		{time.Date(2024, 6, 28, 22, 0, 0, 0, time.UTC), "Last Quarter", 50, time.Date(2024, 7, 21, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		info := moonPhase(tt.at)
+		if info.Phase != tt.phase {
+			t.Errorf("%s: expected %s, got %s", tt.at, tt.phase, info.Phase)
+		}
+		if diff := info.IlluminationPercent - tt.illumination; diff < -5 || diff > 5 {
+			t.Errorf("%s: expected about %d%% illuminated, got %d%%", tt.at, tt.illumination, info.IlluminationPercent)
+		}
+		next, err := time.Parse(j1DateLayout, info.NextFull)
+		if err != nil {
+			t.Fatalf("%s: invalid next_full %q", tt.at, info.NextFull)
+		}
+		if diff := next.Sub(tt.nextFull); diff < -24*time.Hour || diff > 24*time.Hour {
+			t.Errorf("%s: expected next full moon about %s, got %s", tt.at, tt.nextFull.Format(j1DateLayout), info.NextFull)
+		}
+	}
+}
+
+func TestNextFullMoon(t *testing.T) {
+	// Published full moons, UTC.
+	for _, want := range []time.Time{
+		time.Date(2024, 6, 22, 1, 8, 0, 0, time.UTC),
+		time.Date(2024, 12, 15, 9, 2, 0, 0, time.UTC),
+		time.Date(2025, 3, 14, 6, 55, 0, 0, time.UTC),
+	} {
+		got := nextFullMoon(want.Add(-72 * time.Hour))
+		if diff := got.Sub(want); diff < -10*time.Minute || diff > 10*time.Minute {
+			t.Errorf("expected full moon at %s, got %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// comfortRanks orders the comfort levels from most to least pleasant. The
+// wind alone is easier to bear than heat, humidity or cold.
+var comfortRanks = map[string]int{
+	"comfortable": 0,
+	"windy":       1,
+	"chilly":      2,
+	"hot":         2,
+	"humid":       2,
+}
+
+// NicestWindow is the most comfortable upcoming part of a day.
+type NicestWindow struct {
+	Date string `json:"date"`
+	Day  string `json:"day"`
+	DaySegment
+	Comfort     string `json:"comfort"`
+	Explanation string `json:"explanation"`
+	// Considered is the number of upcoming segments compared.
+	Considered int `json:"considered"`
+}
+
+// nicestWindow scans the morning, noon, evening and night segments of every
+// forecast day after the observation time and picks the most comfortable:
+// the best comfort level, then the lowest chance of rain, then the
+// feels-like temperature closest to the comfortable band. On ties the
+// earliest segment wins. Without a parsable observation time every
+// segment counts as upcoming.
+func (c *WeatherClient) nicestWindow(w DetailedWeather) (NicestWindow, error) {
+	var obs time.Time
+	if cur, err := w.current(); err == nil {
+		obs, _ = time.Parse(j1DateTimeLayout, cur.LocalObsDateTime)
+	}
+
+	type candidate struct {
+		window   NicestWindow
+		rank     int
+		rain     int
+		distance float64
+	}
+	better := func(a, b candidate) bool {
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.rain != b.rain {
+			return a.rain < b.rain
+		}
+		return a.distance < b.distance
+	}
+
+	var best *candidate
+	var err error
+	considered := 0
+	for _, d := range w.Weather {
+		var date time.Time
+		if date, err = time.Parse(j1DateLayout, d.Date); err != nil {
+			return NicestWindow{}, fmt.Errorf("parsing forecast date %q: %w", d.Date, err)
+		}
+		for _, seg := range daySegments {
+			for _, h := range d.Hourly {
+				if int(h.Time) != seg.time {
+					continue
+				}
+				if at := date.Add(time.Duration(seg.time/100) * time.Hour); !obs.IsZero() && !at.After(obs) {
+					break
+				}
+				considered++
+
+				comfort := c.classifyComfort(float64(h.TempC), float64(h.Humidity), float64(h.WindSpeedKmph))
+				cand := candidate{
+					window: NicestWindow{
+						Date:        d.Date,
+						Day:         summarizeDay(d).Day,
+						DaySegment:  c.daySegment(seg.name, h),
+						Comfort:     comfort.Level,
+						Explanation: comfort.Explanation,
+					},
+					rank:     comfortRanks[comfort.Level],
+					rain:     int(h.ChanceOfRain),
+					distance: comfortDistance(float64(h.FeelsLikeC)),
+				}
+				if best == nil || better(cand, *best) {
+					best = &cand
+				}
+				break
+			}
+		}
+	}
+
+	if best == nil {
+		return NicestWindow{}, fmt.Errorf("no upcoming day segments in the forecast")
+	}
+	best.window.Considered = considered
+	if c.convertsTimes() {
+		if best.window.Time, err = c.zoneClock(w, best.window.Date, best.window.Time); err != nil {
+			return NicestWindow{}, err
+		}
+	}
+	return best.window, nil
+}
+
+// GetNicestWindow returns the most comfortable upcoming morning, noon,
+// evening or night of the forecast as JSON.
+func (c *WeatherClient) GetNicestWindow(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	result, err := c.nicestWindow(w)
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(result)
+}
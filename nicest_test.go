@@ -0,0 +1,96 @@
+package main
+
+import "testing"
+
+// nicestFixture builds three days of chilly segments observed at 1 PM on
+// the first day. comfy lists the comfortable slots as date index, slot
+// time and chance of rain.
+func nicestFixture(comfy ...[3]int) DetailedWeather {
+	w := DetailedWeather{
+		CurrentCondition: []CurrentCondition{{LocalObsDateTime: "2024-06-12 01:00 PM"}},
+	}
+	for i, date := range []string{"2024-06-12", "2024-06-13", "2024-06-14"} {
+		d := DayForecast{Date: date}
+		for _, seg := range daySegments {
+			h := HourlyWeather{
+				Time:          number(seg.time),
+				TempC:         5,
+				FeelsLikeC:    3,
+				Humidity:      50,
+				WindSpeedKmph: 10,
+				WeatherDesc:   text{{Value: "Overcast"}},
+			}
+			for _, c := range comfy {
+				if c[0] == i && c[1] == seg.time {
+					h.TempC, h.FeelsLikeC, h.ChanceOfRain = 20, 20, number(c[2])
+					h.WeatherDesc = text{{Value: "Sunny"}}
+				}
+			}
+			d.Hourly = append(d.Hourly, h)
+		}
+		w.Weather = append(w.Weather, d)
+	}
+	return w
+}
+
+func TestNicestWindow(t *testing.T) {
+	// This morning is already past; of the rest, tomorrow's noon is
+	// comfortable with the least rain.
+	w := nicestFixture([3]int{0, 900, 0}, [3]int{0, 1800, 50}, [3]int{1, 1200, 10}, [3]int{2, 900, 30})
+
+	result, err := new(WeatherClient).nicestWindow(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Date != "2024-06-13" || result.Day != "Thursday" || result.Segment != "noon" || result.Time != "12:00" {
+		t.Errorf("expected Thursday noon, got %+v", result)
+	}
+	if result.Comfort != "comfortable" || result.Description != "Sunny" || result.ChanceOfRain != 10 {
+		t.Errorf("unexpected conditions: %+v", result)
+	}
+	// Two of today's four segments are upcoming, plus all of the next two days.
+	if result.Considered != 10 {
+		t.Errorf("expected 10 segments considered, got %d", result.Considered)
+	}
+}
+
+func TestNicestWindowTieGoesToEarliest(t *testing.T) {
+	w := nicestFixture([3]int{2, 900, 10}, [3]int{1, 2100, 10}, [3]int{2, 1200, 10})
+
+	result, err := new(WeatherClient).nicestWindow(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Date != "2024-06-13" || result.Segment != "night" {
+		t.Errorf("expected the earliest tied segment, got %+v", result)
+	}
+}
+
+func TestNicestWindowFallsBackToLesserComfort(t *testing.T) {
+	// Nothing is comfortable; a chilly segment with less rain wins.
+	w := nicestFixture()
+	for _, d := range w.Weather {
+		for i := range d.Hourly {
+			d.Hourly[i].ChanceOfRain = 20
+		}
+	}
+	w.Weather[1].Hourly[2].ChanceOfRain = 0
+
+	result, err := new(WeatherClient).nicestWindow(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Comfort != "chilly" || result.Date != "2024-06-13" || result.Segment != "evening" {
+		t.Errorf("unexpected window: %+v", result)
+	}
+}
+
+func TestNicestWindowNoneUpcoming(t *testing.T) {
+	w := nicestFixture()
+	w.CurrentCondition[0].LocalObsDateTime = "2024-06-14 11:30 PM"
+
+	if _, err := new(WeatherClient).nicestWindow(w); err == nil {
+		t.Fatal("expected error when every segment is past")
+	}
+}
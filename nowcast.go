@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultNowcastMinutes = 60
+	maxNowcastMinutes     = 180
+
+	// slotMinutes is the spacing of the j1 hourly slots.
+	slotMinutes = 180
+)
+
+// slotPoint is a forecast slot placed on a timeline of minutes since
+// midnight of the first forecast day.
+type slotPoint struct {
+	minute       float64
+	chanceOfRain float64
+	precipMM     float64
+}
+
+// slotTimeline flattens the hourly slots of all forecast days into one timeline.
+func slotTimeline(w DetailedWeather) []slotPoint {
+	var points []slotPoint
+	for day, d := range w.Weather {
+		for _, h := range d.Hourly {
+			t := float64(h.Time)
+			points = append(points, slotPoint{
+				minute:       float64(day*24*60) + math.Floor(t/100)*60 + math.Mod(t, 100),
+				chanceOfRain: float64(h.ChanceOfRain),
+				precipMM:     float64(h.PrecipMM),
+			})
+		}
+	}
+	return points
+}
+
+// interpolateSlot linearly interpolates the slot values at minute. Outside
+// the timeline the nearest slot is used.
+func interpolateSlot(points []slotPoint, minute float64) slotPoint {
+	if minute <= points[0].minute {
+		return slotPoint{minute, points[0].chanceOfRain, points[0].precipMM}
+	}
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if minute <= b.minute {
+			f := (minute - a.minute) / (b.minute - a.minute)
+			return slotPoint{
+				minute:       minute,
+				chanceOfRain: a.chanceOfRain + f*(b.chanceOfRain-a.chanceOfRain),
+				precipMM:     a.precipMM + f*(b.precipMM-a.precipMM),
+			}
+		}
+	}
+	last := points[len(points)-1]
+	return slotPoint{minute, last.chanceOfRain, last.precipMM}
+}
+
+// Nowcast estimates rain over the next few minutes.
+type Nowcast struct {
+	Minutes      int       `json:"minutes"`
+	From         timeValue `json:"from"`
+	To           timeValue `json:"to"`
+	ChanceOfRain int       `json:"chance_of_rain"`
+	PrecipMM     float64   `json:"precip_mm"`
+	Umbrella     bool      `json:"umbrella"`
+	Note         string    `json:"note"`
+}
+
+// nowcast estimates rain in the next minutes from the observation time.
+//
+// wttr.in only forecasts in 3-hourly slots, so this is an approximation:
+// chanceofrain and precipMM are interpolated linearly between the slots
+// around the window. The chance of rain is the highest interpolated value in
+// the window. Each slot's precipMM is treated as the amount falling over its
+// 3 hours, and the window's amount is the integral of that rate. An umbrella
+// is suggested from a 40% chance or 0.2 mm.
+func nowcast(w DetailedWeather, minutes int) (Nowcast, error) {
+	points := slotTimeline(w)
+	if len(points) == 0 {
+		return Nowcast{}, fmt.Errorf("no hourly forecast in weather data")
+	}
+
+	cur, err := w.current()
+	if err != nil {
+		return Nowcast{}, err
+	}
+	obs, err := time.Parse(j1DateTimeLayout, cur.LocalObsDateTime)
+	if err != nil {
+		return Nowcast{}, fmt.Errorf("parsing observation time %q: %w", cur.LocalObsDateTime, err)
+	}
+	first, err := time.Parse(j1DateLayout, w.Weather[0].Date)
+	if err != nil {
+		return Nowcast{}, fmt.Errorf("parsing forecast date %q: %w", w.Weather[0].Date, err)
+	}
+
+	start := obs.Sub(first).Minutes()
+	end := start + float64(minutes)
+
+	// Sample the window edges and every slot inside it; the interpolation is
+	// piecewise linear, so the extremes and the integral are exact on these.
+	samples := []slotPoint{interpolateSlot(points, start)}
+	for _, p := range points {
+		if p.minute > start && p.minute < end {
+			samples = append(samples, p)
+		}
+	}
+	samples = append(samples, interpolateSlot(points, end))
+
+	var chance, precip float64
+	for i, p := range samples {
+		chance = math.Max(chance, p.chanceOfRain)
+		if i > 0 {
+			prev := samples[i-1]
+			precip += (prev.precipMM + p.precipMM) / 2 / slotMinutes * (p.minute - prev.minute)
+		}
+	}
+
+	return Nowcast{
+		Minutes:      minutes,
+		From:         timeValue(obs.Format("15:04")),
+		To:           timeValue(obs.Add(time.Duration(minutes) * time.Minute).Format("15:04")),
+		ChanceOfRain: int(math.Round(chance)),
+		PrecipMM:     precip,
+		Umbrella:     chance >= 40 || precip >= 0.2,
+		Note:         "Interpolated from wttr.in's 3-hourly forecast; treat as an approximation.",
+	}, nil
+}
+
+// GetNowcast estimates rain over the next minutes (capped at 180) as JSON.
+func (c *WeatherClient) GetNowcast(location string, minutes int) (string, error) {
+	if minutes <= 0 {
+		minutes = defaultNowcastMinutes
+	}
+	if minutes > maxNowcastMinutes {
+		minutes = maxNowcastMinutes
+	}
+
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := nowcast(w, minutes)
+	if err != nil {
+		return "", err
+	}
+	result.PrecipMM = c.round(result.PrecipMM)
+	if c.convertsTimes() {
+		cur, _ := w.current()
+		obs, _ := time.Parse(j1DateTimeLayout, cur.LocalObsDateTime)
+		if result.From, err = c.zoneTime(w, obs); err != nil {
+			return "", err
+		}
+		if result.To, err = c.zoneTime(w, obs.Add(time.Duration(minutes)*time.Minute)); err != nil {
+			return "", err
+		}
+	}
+	if result.Umbrella {
+		result.Note = c.theme.decorate(glyphUmbrella, result.Note)
+	}
+	return marshalResult(result)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func nowcastFixture(obs string) DetailedWeather {
+	return DetailedWeather{
+		CurrentCondition: []CurrentCondition{{LocalObsDateTime: obs}},
+		Weather: []DayForecast{{
+			Date: "2024-06-12",
+			Hourly: []HourlyWeather{
+				{Time: 900, ChanceOfRain: 0, PrecipMM: 0},
+				{Time: 1200, ChanceOfRain: 20, PrecipMM: 0.6},
+				{Time: 1500, ChanceOfRain: 80, PrecipMM: 3},
+				{Time: 1800, ChanceOfRain: 40, PrecipMM: 1.2},
+			},
+		}},
+	}
+}
+
+func TestNowcastInterpolatesBetweenSlots(t *testing.T) {
+	// 13:00 to 14:00 lies between the 12:00 (20%) and 15:00 (80%) slots.
+	n, err := nowcast(nowcastFixture("2024-06-12 01:00 PM"), 60)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n.From != "13:00" || n.To != "14:00" {
+		t.Errorf("unexpected window: %s-%s", n.From, n.To)
+	}
+	// At 14:00 the chance is 20 + 2/3*60 = 60%.
+	if n.ChanceOfRain != 60 {
+		t.Errorf("expected interpolated chance 60, got %d", n.ChanceOfRain)
+	}
+	// The rate rises from 1.4 to 2.2 mm per 3h, averaging 1.8 mm per 3h over an hour.
+	if math.Abs(n.PrecipMM-0.6) > 1e-9 {
+		t.Errorf("expected 0.6 mm, got %v", n.PrecipMM)
+	}
+	if !n.Umbrella {
+		t.Error("expected umbrella advice")
+	}
+}
+
+func TestNowcastWindowSpanningSlot(t *testing.T) {
+	// 14:00 to 17:00 crosses the 15:00 peak, which bounds the chance.
+	n, err := nowcast(nowcastFixture("2024-06-12 02:00 PM"), 180)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n.ChanceOfRain != 80 {
+		t.Errorf("expected peak chance 80, got %d", n.ChanceOfRain)
+	}
+}
+
+func TestNowcastDryMorning(t *testing.T) {
+	n, err := nowcast(nowcastFixture("2024-06-12 09:00 AM"), 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n.ChanceOfRain != 3 || n.Umbrella {
+		t.Errorf("expected a dry half hour, got %+v", n)
+	}
+}
+
+func TestNowcastNoHourly(t *testing.T) {
+	if _, err := nowcast(DetailedWeather{}, 60); err == nil {
+		t.Fatal("expected error without hourly data")
+	}
+}
+
+func TestWeatherClientGetNowcastCapsMinutes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"current_condition": [{"localObsDateTime": "2024-06-12 01:00 PM"}],
+			"weather": [{"date": "2024-06-12", "hourly": [{"time": "1200", "chanceofrain": "20"}, {"time": "1500", "chanceofrain": "80"}]}]
+		}`))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	result, err := client.GetNowcast("Dublin", 600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, `"minutes":180`) {
+		t.Errorf("expected minutes capped at 180: %s", result)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Outlook rule thresholds.
+const (
+	// outlookSlots is how many upcoming 3-hourly slots are compared with
+	// the current conditions.
+	outlookSlots = 3
+
+	rainLikelyChance  = 50
+	rainClearedChance = 30
+	rainingNowMM      = 0.1
+	cloudShift        = 30
+	windShiftKmph     = 15
+	comfortShiftC     = 3
+
+	// comfortMinC and comfortMaxC bound the feels-like temperatures
+	// warming or cooling toward counts as improving.
+	comfortMinC = 18
+	comfortMaxC = 24
+)
+
+// Outlook is whether the weather is getting better or worse over the next
+// few slots, and why.
+type Outlook struct {
+	Outlook     string   `json:"outlook"`
+	Reasons     []string `json:"reasons"`
+	WindowHours int      `json:"window_hours"`
+}
+
+// upcomingSlots returns up to n hourly slots after the observation time,
+// running into the next days as needed.
+func upcomingSlots(w DetailedWeather, n int) ([]HourlyWeather, error) {
+	cur, err := w.current()
+	if err != nil {
+		return nil, err
+	}
+	obs, err := time.Parse(j1DateTimeLayout, cur.LocalObsDateTime)
+	if err != nil {
+		return nil, fmt.Errorf("parsing observation time %q: %w", cur.LocalObsDateTime, err)
+	}
+
+	var slots []HourlyWeather
+	for _, d := range w.Weather {
+		date, err := time.Parse(j1DateLayout, d.Date)
+		if err != nil {
+			return nil, fmt.Errorf("parsing forecast date %q: %w", d.Date, err)
+		}
+		for _, h := range d.Hourly {
+			at := date.Add(time.Duration(h.Time)/100*time.Hour + time.Duration(h.Time)%100*time.Minute)
+			if at.After(obs) && len(slots) < n {
+				slots = append(slots, h)
+			}
+		}
+	}
+	if len(slots) == 0 {
+		return nil, fmt.Errorf("no forecast slots after %s", cur.LocalObsDateTime)
+	}
+	return slots, nil
+}
+
+// comfortDistance is how far a feels-like temperature is from the
+// comfortable band.
+func comfortDistance(feelsLikeC float64) float64 {
+	return math.Max(math.Max(comfortMinC-feelsLikeC, feelsLikeC-comfortMaxC), 0)
+}
+
+// outlook compares the current conditions with the upcoming slots. Each
+// rule that fires counts as improving or worsening:
+//
+//   - rain: worsening when it is dry now and a slot has a rainLikelyChance
+//     or higher; improving when it rains now and the last slot's chance
+//     is below rainClearedChance
+//   - cloud: the last slot's cloud cover differs by cloudShift points
+//   - wind: the last slot's wind differs by windShiftKmph
+//   - temperature: the last slot's feels-like temperature is comfortShiftC
+//     closer to or further from the comfortMinC-comfortMaxC band
+//
+// The outlook is whichever side has more rules, or "steady" on a tie.
+func outlook(w DetailedWeather) (Outlook, error) {
+	cur, err := w.current()
+	if err != nil {
+		return Outlook{}, err
+	}
+	slots, err := upcomingSlots(w, outlookSlots)
+	if err != nil {
+		return Outlook{}, err
+	}
+	last := slots[len(slots)-1]
+
+	result := Outlook{Reasons: []string{}, WindowHours: len(slots) * slotMinutes / 60}
+	improving, worsening := 0, 0
+	note := func(better bool, reason string) {
+		if better {
+			improving++
+		} else {
+			worsening++
+		}
+		result.Reasons = append(result.Reasons, reason)
+	}
+
+	chance := 0
+	for _, h := range slots {
+		chance = max(chance, int(h.ChanceOfRain))
+	}
+	switch raining := float64(cur.PrecipMM) >= rainingNowMM; {
+	case !raining && chance >= rainLikelyChance:
+		note(false, fmt.Sprintf("rain moving in: %d%% chance", chance))
+	case raining && int(last.ChanceOfRain) < rainClearedChance:
+		note(true, fmt.Sprintf("rain clearing: %d%% chance by then", int(last.ChanceOfRain)))
+	}
+
+	switch shift := float64(last.CloudCover - cur.CloudCover); {
+	case shift <= -cloudShift:
+		note(true, fmt.Sprintf("clearing: cloud cover %g%% to %g%%", float64(cur.CloudCover), float64(last.CloudCover)))
+	case shift >= cloudShift:
+		note(false, fmt.Sprintf("clouding over: cloud cover %g%% to %g%%", float64(cur.CloudCover), float64(last.CloudCover)))
+	}
+
+	switch shift := float64(last.WindSpeedKmph - cur.WindSpeedKmph); {
+	case shift >= windShiftKmph:
+		note(false, fmt.Sprintf("wind rising: %g to %g km/h", float64(cur.WindSpeedKmph), float64(last.WindSpeedKmph)))
+	case shift <= -windShiftKmph:
+		note(true, fmt.Sprintf("wind easing: %g to %g km/h", float64(cur.WindSpeedKmph), float64(last.WindSpeedKmph)))
+	}
+
+	from, to := float64(cur.FeelsLikeC), float64(last.FeelsLikeC)
+	switch shift := comfortDistance(to) - comfortDistance(from); {
+	case shift <= -comfortShiftC && to > from:
+		note(true, fmt.Sprintf("warming toward comfort: feels like %g°C to %g°C", from, to))
+	case shift <= -comfortShiftC:
+		note(true, fmt.Sprintf("cooling toward comfort: feels like %g°C to %g°C", from, to))
+	case shift >= comfortShiftC && to > from:
+		note(false, fmt.Sprintf("getting hotter: feels like %g°C to %g°C", from, to))
+	case shift >= comfortShiftC:
+		note(false, fmt.Sprintf("getting colder: feels like %g°C to %g°C", from, to))
+	}
+
+	switch {
+	case improving > worsening:
+		result.Outlook = "improving"
+	case worsening > improving:
+		result.Outlook = "worsening"
+	default:
+		result.Outlook = "steady"
+	}
+	return result, nil
+}
+
+// GetOutlook returns whether the weather is improving or worsening over
+// the next few forecast slots, with reasons, as JSON.
+func (c *WeatherClient) GetOutlook(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := outlook(w)
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(result)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// outlookFixture observes cur at 10:05 and forecasts slots from 12:00.
+func outlookFixture(cur CurrentCondition, slots ...HourlyWeather) DetailedWeather {
+	cur.LocalObsDateTime = "2024-06-12 10:05 AM"
+	for i := range slots {
+		slots[i].Time = number(1200 + i*300)
+	}
+	return DetailedWeather{
+		CurrentCondition: []CurrentCondition{cur},
+		Weather: []DayForecast{
+			{Date: "2024-06-12", Hourly: append([]HourlyWeather{{Time: 900}}, slots...)},
+		},
+	}
+}
+
+func TestOutlookClearing(t *testing.T) {
+	w := outlookFixture(
+		CurrentCondition{PrecipMM: 1.2, CloudCover: 95, WindSpeedKmph: 30, FeelsLikeC: 11},
+		HourlyWeather{ChanceOfRain: 60, CloudCover: 70, WindSpeedKmph: 20, FeelsLikeC: 13},
+		HourlyWeather{ChanceOfRain: 30, CloudCover: 45, WindSpeedKmph: 15, FeelsLikeC: 15},
+		HourlyWeather{ChanceOfRain: 10, CloudCover: 20, WindSpeedKmph: 10, FeelsLikeC: 17},
+	)
+
+	result, err := outlook(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Outlook != "improving" || result.WindowHours != 9 {
+		t.Errorf("expected improving over 9 hours, got %+v", result)
+	}
+	want := "rain clearing: 10% chance by then; clearing: cloud cover 95% to 20%; wind easing: 30 to 10 km/h; warming toward comfort: feels like 11°C to 17°C"
+	if got := strings.Join(result.Reasons, "; "); got != want {
+		t.Errorf("unexpected reasons:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestOutlookDeteriorating(t *testing.T) {
+	w := outlookFixture(
+		CurrentCondition{CloudCover: 10, WindSpeedKmph: 8, FeelsLikeC: 21},
+		HourlyWeather{ChanceOfRain: 20, CloudCover: 40, WindSpeedKmph: 15, FeelsLikeC: 19},
+		HourlyWeather{ChanceOfRain: 80, CloudCover: 90, WindSpeedKmph: 35, FeelsLikeC: 14},
+	)
+
+	result, err := outlook(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Outlook != "worsening" || len(result.Reasons) != 4 {
+		t.Errorf("expected worsening for four reasons, got %+v", result)
+	}
+	if result.Reasons[0] != "rain moving in: 80% chance" {
+		t.Errorf("unexpected rain reason: %q", result.Reasons[0])
+	}
+}
+
+func TestOutlookSteady(t *testing.T) {
+	w := outlookFixture(
+		CurrentCondition{CloudCover: 50, WindSpeedKmph: 10, FeelsLikeC: 20},
+		HourlyWeather{ChanceOfRain: 10, CloudCover: 55, WindSpeedKmph: 12, FeelsLikeC: 21},
+	)
+
+	result, err := outlook(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Outlook != "steady" || len(result.Reasons) != 0 {
+		t.Errorf("expected a steady outlook without reasons, got %+v", result)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Overview pairs the readable one-liner with the structured j1 data.
+type Overview struct {
+	Summary string          `json:"summary"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// GetOverview fetches the one-liner and the j1 data concurrently and
+// returns both as JSON.
+func (c *WeatherClient) GetOverview(location string) (string, error) {
+	var (
+		wg                  sync.WaitGroup
+		summary, detailed   string
+		summaryErr, dataErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		summary, summaryErr = c.GetCurrent(location)
+	}()
+	go func() {
+		defer wg.Done()
+		detailed, dataErr = c.GetDetailed(location)
+	}()
+	wg.Wait()
+
+	if summaryErr != nil {
+		return "", fmt.Errorf("fetching summary: %w", summaryErr)
+	}
+	if dataErr != nil {
+		return "", fmt.Errorf("fetching detailed data: %w", dataErr)
+	}
+	if !json.Valid([]byte(detailed)) {
+		return "", fmt.Errorf("parsing weather data: invalid JSON")
+	}
+
+	return marshalResult(Overview{Summary: summary, Data: json.RawMessage(detailed)})
+}
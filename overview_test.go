@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWeatherClientGetOverview(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+
+		if r.URL.Query().Get("format") == "j1" {
+			w.Write([]byte(`{"current_condition":[{"temp_C":"20"}]}`))
+			return
+		}
+		w.Write([]byte("London: ☀️ +20°C"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.GetOverview("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(queries) != 2 {
+		t.Errorf("expected two fetches, got %v", queries)
+	}
+	if result != `{"summary":"London: ☀️ +20°C","data":{"current_condition":[{"temp_C":"20"}]}}` {
+		t.Errorf("unexpected result: %s", result)
+	}
+}
+
+func TestWeatherClientGetOverviewPartialFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") == "j1" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("London: ☀️ +20°C"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	_, err := client.GetOverview("London")
+	if err == nil || !strings.Contains(err.Error(), "detailed data") {
+		t.Fatalf("expected detailed data error, got %v", err)
+	}
+}
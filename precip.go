@@ -0,0 +1,58 @@
+package main
+
+// snowMaxTempC is the daily maximum at or below which any precipitation is
+// taken to fall as snow, whatever the chances say.
+const snowMaxTempC = 0
+
+// PrecipDay is the precipitation outlook for a forecast day.
+type PrecipDay struct {
+	Date     string  `json:"date"`
+	Chance   int     `json:"chance"`
+	Type     string  `json:"type"`
+	AmountMM float64 `json:"amount_mm"`
+}
+
+// PrecipForecast is the precipitation outlook for the forecast days.
+type PrecipForecast struct {
+	Days []PrecipDay `json:"days"`
+}
+
+// precipDay aggregates the hourly slots of a day: the chance is the
+// highest of rain and snow over the day and the amount is the total. The
+// type is snow when snow is more likely than rain or the day stays below
+// freezing, rain otherwise, and none when nothing is expected.
+func (c *WeatherClient) precipDay(d DayForecast) PrecipDay {
+	var rain, snow int
+	var amount float64
+	for _, h := range d.Hourly {
+		rain = max(rain, int(h.ChanceOfRain))
+		snow = max(snow, int(h.ChanceOfSnow))
+		amount += float64(h.PrecipMM)
+	}
+
+	day := PrecipDay{Date: d.Date, Chance: max(rain, snow), AmountMM: c.round(amount)}
+	switch {
+	case day.Chance == 0 && amount == 0:
+		day.Type = "none"
+	case snow > rain || d.MaxTempC <= snowMaxTempC:
+		day.Type = "snow"
+	default:
+		day.Type = "rain"
+	}
+	return day
+}
+
+// GetPrecipForecast returns the chance, type and amount of precipitation
+// for each of the first days of the forecast as JSON.
+func (c *WeatherClient) GetPrecipForecast(location string, days int) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result := PrecipForecast{Days: []PrecipDay{}}
+	for _, d := range w.Weather[:min(max(days, 1), len(w.Weather))] {
+		result.Days = append(result.Days, c.precipDay(d))
+	}
+	return marshalResult(result)
+}
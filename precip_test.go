@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestPrecipDayRain(t *testing.T) {
+	c := &WeatherClient{precision: 1}
+	day := c.precipDay(DayForecast{Date: "2024-06-12", MaxTempC: 17, Hourly: []HourlyWeather{
+		{Time: 900, ChanceOfRain: 20, PrecipMM: 0.4},
+		{Time: 1200, ChanceOfRain: 85, PrecipMM: 2.1},
+		{Time: 1500, ChanceOfRain: 60, PrecipMM: 0.7},
+	}})
+
+	want := PrecipDay{Date: "2024-06-12", Chance: 85, Type: "rain", AmountMM: 3.2}
+	if day != want {
+		t.Errorf("got %+v, want %+v", day, want)
+	}
+}
+
+func TestPrecipDaySnow(t *testing.T) {
+	c := &WeatherClient{precision: 1}
+	day := c.precipDay(DayForecast{Date: "2024-01-15", MaxTempC: 1, Hourly: []HourlyWeather{
+		{Time: 900, ChanceOfRain: 10, ChanceOfSnow: 70, PrecipMM: 1.5},
+		{Time: 1200, ChanceOfRain: 30, ChanceOfSnow: 90, PrecipMM: 2.5},
+	}})
+
+	want := PrecipDay{Date: "2024-01-15", Chance: 90, Type: "snow", AmountMM: 4}
+	if day != want {
+		t.Errorf("got %+v, want %+v", day, want)
+	}
+}
+
+func TestPrecipDayDry(t *testing.T) {
+	c := &WeatherClient{precision: 1}
+	day := c.precipDay(DayForecast{Date: "2024-06-13", MaxTempC: 25, Hourly: []HourlyWeather{{Time: 1200}}})
+
+	if day.Type != "none" || day.Chance != 0 || day.AmountMM != 0 {
+		t.Errorf("unexpected dry day: %+v", day)
+	}
+}
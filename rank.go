@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxRankLocations caps the locations ranked in one call, each costing an
+// upstream request.
+const maxRankLocations = 20
+
+// RankedLocation is one entry of a temperature ranking. Locations that
+// could not be fetched carry their error instead of a temperature.
+type RankedLocation struct {
+	Location    string   `json:"location"`
+	Name        string   `json:"name,omitempty"`
+	TempC       *float64 `json:"temp_c,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// checkRankLocations validates the locations of a ranking before anything
+// is fetched.
+func checkRankLocations(locations []string) error {
+	if len(locations) == 0 {
+		return invalidArgument("at least one location is required")
+	}
+	if len(locations) > maxRankLocations {
+		return invalidArgument("%d locations given, the limit is %d", len(locations), maxRankLocations)
+	}
+	for i, location := range locations {
+		if strings.TrimSpace(location) == "" {
+			return invalidArgument("location %d is empty", i)
+		}
+		if err := checkCoordinates(location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rankByTemperature orders fetched locations warmest first. On equal
+// temperatures and among failures the given order is kept; failures come
+// last.
+func rankByTemperature(results []locationResult) []RankedLocation {
+	ranked := make([]RankedLocation, len(results))
+	for i, r := range results {
+		ranked[i] = RankedLocation{Location: r.Location}
+		if r.Err != nil {
+			ranked[i].Error = r.Err.Error()
+			continue
+		}
+		w, err := parseDetailed(r.Text)
+		if err != nil {
+			ranked[i].Error = err.Error()
+			continue
+		}
+		place := antipodePlace(w, Coordinates{})
+		ranked[i].Name, ranked[i].TempC, ranked[i].Description = place.Name, place.TempC, place.Description
+		if place.TempC == nil {
+			ranked[i].Error = "no current temperature in weather data"
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].TempC, ranked[j].TempC
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a > *b
+	})
+	return ranked
+}
+
+// RankByTemperature fetches the locations concurrently and returns them
+// as a JSON array ordered by current temperature, warmest first, with the
+// ones that failed at the end.
+func (c *WeatherClient) RankByTemperature(locations []string) (string, error) {
+	if err := checkRankLocations(locations); err != nil {
+		return "", err
+	}
+
+	results := fetchAll(locations, c.GetDetailed)
+	ranked := rankByTemperature(results)
+	if ranked[0].TempC == nil {
+		errs := make([]string, len(ranked))
+		for i, r := range ranked {
+			errs[i] = r.Location + ": " + r.Error
+		}
+		return "", fmt.Errorf("all locations failed: %s", strings.Join(errs, "; "))
+	}
+	return marshalResult(ranked)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWeatherClientRankByTemperature(t *testing.T) {
+	temps := map[string]string{"/Oslo": "8", "/Madrid": "31", "/Lisbon": "24", "/Porto": "24"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		temp, ok := temps[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"current_condition": [{"temp_C": "` + temp + `", "weatherDesc": [{"value": "Sunny"}]}]}`))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.RankByTemperature([]string{"Oslo", "Atlantis", "Porto", "Madrid", "Lisbon"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var ranked []RankedLocation
+	if err := json.Unmarshal([]byte(result), &ranked); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	var order []string
+	for _, r := range ranked {
+		order = append(order, r.Location)
+	}
+	// Porto and Lisbon tie and keep their given order.
+	if got := strings.Join(order, ","); got != "Madrid,Porto,Lisbon,Oslo,Atlantis" {
+		t.Errorf("unexpected order: %s", got)
+	}
+	if r := ranked[0]; r.TempC == nil || *r.TempC != 31 || r.Description != "Sunny" {
+		t.Errorf("unexpected warmest: %+v", r)
+	}
+	if r := ranked[4]; r.TempC != nil || !strings.Contains(r.Error, "404") {
+		t.Errorf("expected the failed location to carry its error, got %+v", r)
+	}
+
+	if _, err := client.RankByTemperature([]string{"Atlantis"}); err == nil || !strings.Contains(err.Error(), "all locations failed") {
+		t.Errorf("expected an error when every location fails, got %v", err)
+	}
+}
+
+func TestCheckRankLocations(t *testing.T) {
+	tooMany := make([]string, maxRankLocations+1)
+	for i := range tooMany {
+		tooMany[i] = "Oslo"
+	}
+	for _, locations := range [][]string{nil, {"Oslo", " "}, tooMany} {
+		if err := checkRankLocations(locations); errorCode(err) != errorCodeInvalidArguments {
+			t.Errorf("%d locations: expected an invalid argument error, got %v", len(locations), err)
+		}
+	}
+}
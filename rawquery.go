@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxRawQueryLength caps raw queries; real wttr.in options are far shorter.
+const maxRawQueryLength = 200
+
+// rawQueryPattern is the characters wttr.in options use: letters, digits
+// and = & % . _ + , : ~ -. Slashes, "?", "#" and "@" are excluded so a raw
+// query cannot change the path, host or scheme of the request.
+var rawQueryPattern = regexp.MustCompile(`^[A-Za-z0-9=&%._+,:~-]+$`)
+
+// checkRawQuery validates a caller-supplied query string. A leading "?" is
+// allowed and removed.
+func checkRawQuery(query string) (string, error) {
+	query = strings.TrimPrefix(query, "?")
+	if query == "" {
+		return "", invalidArgument("raw_query must not be empty")
+	}
+	if len(query) > maxRawQueryLength {
+		return "", invalidArgument("raw_query is %d characters long, the limit is %d", len(query), maxRawQueryLength)
+	}
+	if !rawQueryPattern.MatchString(query) {
+		return "", invalidArgument("raw_query %q may only contain letters, digits and = & %% . _ + , : ~ -", query)
+	}
+	return query, nil
+}
+
+// GetRaw fetches a location with a caller-supplied wttr.in query string and
+// returns the response as is.
+func (c *WeatherClient) GetRaw(location, rawQuery string) (string, error) {
+	query, err := checkRawQuery(rawQuery)
+	if err != nil {
+		return "", err
+	}
+	path, err := locationPath(location)
+	if err != nil {
+		return "", err
+	}
+	return c.fetch(fmt.Sprintf("%s/%s?%s", c.baseURL, path, query))
+}
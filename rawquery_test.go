@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWeatherClientGetRaw(t *testing.T) {
+	var requestURI string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		w.Write([]byte("raw"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.GetRaw("London", "?format=%l:+%m&lang=de")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "raw" || requestURI != "/London?format=%l:+%m&lang=de" {
+		t.Errorf("unexpected result %q for request %s", result, requestURI)
+	}
+}
+
+func TestCheckRawQueryRejectsInjection(t *testing.T) {
+	for _, query := range []string{
+		"format=3@evil.example",
+		"format=3/../../admin",
+		"format=3#fragment",
+		"http://evil.example/?q=1",
+		"format=3 lang=de",
+		"",
+		string(make([]byte, maxRawQueryLength+1)),
+	} {
+		if _, err := checkRawQuery(query); err == nil {
+			t.Errorf("%q: expected error", query)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// windArrows point the way the wind blows, as wttr.in's one-liner shows
+// them, for winds from north, northeast and so on clockwise.
+var windArrows = [8]string{"↓", "↙", "←", "↖", "↑", "↗", "→", "↘"}
+
+// moonPhaseIcons are the icons of moonPhaseNames, in the same order.
+var moonPhaseIcons = [8]string{"🌑", "🌒", "🌓", "🌔", "🌕", "🌖", "🌗", "🌘"}
+
+// windArrow returns the arrow for a wind direction in degrees.
+func windArrow(degree float64) string {
+	return windArrows[int(math.Floor(math.Mod(degree+22.5, 360)/45))%8]
+}
+
+// moonIcon returns the icon of a moon phase name.
+func moonIcon(phase string) string {
+	for i, name := range moonPhaseNames {
+		if name == phase {
+			return moonPhaseIcons[i]
+		}
+	}
+	return iconUnknown
+}
+
+// signedCelsius formats a temperature with its sign, as wttr.in does.
+func signedCelsius(v number) string {
+	return fmt.Sprintf("%+g°C", float64(v))
+}
+
+// currentSlot returns today's hourly slot the observation time falls in.
+func currentSlot(w DetailedWeather) (HourlyWeather, error) {
+	if len(w.Weather) == 0 || len(w.Weather[0].Hourly) == 0 {
+		return HourlyWeather{}, fmt.Errorf("no hourly forecast for today")
+	}
+	now, err := localTimeOfDay(w)
+	if err != nil {
+		return HourlyWeather{}, err
+	}
+	slot := w.Weather[0].Hourly[0]
+	for _, h := range w.Weather[0].Hourly {
+		if at := time.Duration(h.Time/100) * time.Hour; at <= now {
+			slot = h
+		}
+	}
+	return slot, nil
+}
+
+// renderCurrentField renders an optional one-liner field from the j1 data.
+// Dawn and dusk are not in j1, so they report false and the one-liner is
+// left to wttr.in.
+func renderCurrentField(w DetailedWeather, cur CurrentCondition, field string) (string, bool, error) {
+	switch field {
+	case "precipitation":
+		return fmt.Sprintf("%.1fmm", float64(cur.PrecipMM)), true, nil
+	case "precipitation_chance":
+		slot, err := currentSlot(w)
+		if err != nil {
+			return "", true, err
+		}
+		return fmt.Sprintf("%d%%", int(slot.ChanceOfRain)), true, nil
+	case "pressure":
+		return fmt.Sprintf("%ghPa", float64(cur.Pressure)), true, nil
+	case "uv_index":
+		return fmt.Sprintf("%g", float64(cur.UVIndex)), true, nil
+	case "moon_phase":
+		today, err := localDate(w)
+		if err != nil {
+			return "", true, err
+		}
+		return moonIcon(moonPhase(today.Add(12 * time.Hour)).Phase), true, nil
+	case "sunrise", "sunset":
+		astro, err := todayAstronomy(w)
+		if err != nil {
+			return "", true, err
+		}
+		clock := astro.Sunrise
+		if field == "sunset" {
+			clock = astro.Sunset
+		}
+		d, err := parseClock(clock)
+		if err != nil {
+			return "", true, err
+		}
+		return formatClock(d), true, nil
+	}
+	return "", false, nil
+}
+
+// renderCurrent renders the one-liner from the j1 data in wttr.in's
+// layout: location, condition icon, temperature, feels like, humidity and
+// wind, then the optional fields. It reports false when a field can only
+// come from wttr.in.
+func renderCurrent(w DetailedWeather, location string, fields []string) (string, bool, error) {
+	cur, err := w.current()
+	if err != nil {
+		return "", true, err
+	}
+	parts := []string{
+		location + ":",
+		conditionIcon(cur.WeatherCode),
+		signedCelsius(cur.TempC),
+		"(" + signedCelsius(cur.FeelsLikeC) + ")",
+		fmt.Sprintf("%g%%", float64(cur.Humidity)),
+		fmt.Sprintf("%s%gkm/h", windArrow(float64(cur.WindDirDegree)), float64(cur.WindSpeedKmph)),
+	}
+	for _, field := range fields {
+		value, ok, err := renderCurrentField(w, cur, field)
+		if err != nil || !ok {
+			return "", ok, err
+		}
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, " "), true, nil
+}
+
+// renderForecast renders a text forecast from the j1 data: a caption, the
+// current conditions and, for each day, its morning, noon, evening and
+// night. The narrow option keeps noon and night, quiet drops the caption
+// and plain changes nothing, since the rendering has no color sequences.
+// Zero days is the today-only view without the day panels.
+func renderForecast(w DetailedWeather, location string, days int, options []string) (string, error) {
+	cur, err := w.current()
+	if err != nil {
+		return "", err
+	}
+	set := make(map[string]bool, len(options))
+	for _, option := range options {
+		set[option] = true
+	}
+
+	var b strings.Builder
+	if !set["quiet"] {
+		fmt.Fprintf(&b, "Weather report: %s\n\n", location)
+	}
+	fmt.Fprintf(&b, "%s %s %s (%s) %s%gkm/h %g%%\n",
+		conditionIcon(cur.WeatherCode), cur.WeatherDesc.String(), signedCelsius(cur.TempC), signedCelsius(cur.FeelsLikeC),
+		windArrow(float64(cur.WindDirDegree)), float64(cur.WindSpeedKmph), float64(cur.Humidity))
+
+	for _, d := range w.Weather[:min(days, len(w.Weather))] {
+		summary := summarizeDay(d)
+		fmt.Fprintf(&b, "\n%s %s: %s..%s\n", summary.Day, d.Date, signedCelsius(d.MinTempC), signedCelsius(d.MaxTempC))
+		for _, seg := range daySegments {
+			if set["narrow"] && seg.name != "noon" && seg.name != "night" {
+				continue
+			}
+			for _, h := range d.Hourly {
+				if int(h.Time) != seg.time {
+					continue
+				}
+				fmt.Fprintf(&b, "  %-8s %s %s %s, %d%% chance of rain\n",
+					seg.name, conditionIcon(h.WeatherCode), signedCelsius(h.TempC), h.WeatherDesc.String(), int(h.ChanceOfRain))
+				break
+			}
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const renderJ1 = `{
+	"current_condition": [{
+		"temp_C": "15", "FeelsLikeC": "14", "humidity": "70", "precipMM": "0.2", "pressure": "1016",
+		"uvIndex": "3", "weatherCode": "116", "weatherDesc": [{"value": "Partly cloudy"}],
+		"winddirDegree": "40", "windspeedKmph": "11", "localObsDateTime": "2024-06-12 01:10 PM"
+	}],
+	"weather": [{
+		"date": "2024-06-12", "maxtempC": "17", "mintempC": "9",
+		"astronomy": [{"sunrise": "04:43 AM", "sunset": "09:18 PM"}],
+		"hourly": [
+			{"time": "900", "tempC": "12", "chanceofrain": "0", "weatherCode": "113", "weatherDesc": [{"value": "Sunny"}]},
+			{"time": "1200", "tempC": "15", "chanceofrain": "20", "weatherCode": "116", "weatherDesc": [{"value": "Partly cloudy"}]},
+			{"time": "1800", "tempC": "14", "chanceofrain": "60", "weatherCode": "296", "weatherDesc": [{"value": "Light rain"}]},
+			{"time": "2100", "tempC": "11", "chanceofrain": "40", "weatherCode": "122", "weatherDesc": [{"value": "Overcast"}]}
+		]
+	}, {
+		"date": "2024-06-13", "maxtempC": "-1", "mintempC": "-4",
+		"hourly": [
+			{"time": "1200", "tempC": "-1", "chanceofrain": "0", "weatherCode": "338", "weatherDesc": [{"value": "Heavy snow"}]},
+			{"time": "2100", "tempC": "-3", "chanceofrain": "0", "weatherCode": "113", "weatherDesc": [{"value": "Clear"}]}
+		]
+	}]
+}`
+
+func TestWindArrow(t *testing.T) {
+	for degree, want := range map[float64]string{0: "↓", 40: "↙", 90: "←", 200: "↑", 337.6: "↓", 315: "↘"} {
+		if got := windArrow(degree); got != want {
+			t.Errorf("windArrow(%g) = %s, want %s", degree, got, want)
+		}
+	}
+}
+
+func TestRenderCurrent(t *testing.T) {
+	w, err := parseDetailed(renderJ1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	line, ok, err := renderCurrent(w, "London", []string{"pressure", "precipitation_chance", "sunset"})
+	if err != nil || !ok {
+		t.Fatalf("unexpected result %q, %v (%v)", line, ok, err)
+	}
+	if want := "London: " + iconPartlyCloudy + " +15°C (+14°C) 70% ↙11km/h 1016hPa 20% 21:18"; line != want {
+		t.Errorf("expected %q, got %q", want, line)
+	}
+
+	if _, ok, err := renderCurrent(w, "London", []string{"dawn"}); err != nil || ok {
+		t.Errorf("expected dawn to be left to wttr.in, got %v (%v)", ok, err)
+	}
+}
+
+func TestRenderForecastOptions(t *testing.T) {
+	w, err := parseDetailed(renderJ1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	today, err := renderForecast(w, "London", 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(today, "Weather report: London\n\n") || strings.Contains(today, "2024-06-12") {
+		t.Errorf("expected the today-only view, got:\n%s", today)
+	}
+
+	narrow, err := renderForecast(w, "London", 2, []string{"narrow", "quiet"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(narrow, "Weather report") || strings.Contains(narrow, "morning") || strings.Contains(narrow, "evening") {
+		t.Errorf("expected no caption and only noon and night, got:\n%s", narrow)
+	}
+	if !strings.Contains(narrow, "Thursday 2024-06-13: -4°C..-1°C") {
+		t.Errorf("expected the second day, got:\n%s", narrow)
+	}
+}
+
+func TestWeatherClientLocalRenderSharesJ1(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		w.Write([]byte(renderJ1))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+		cache:      newResponseCache(time.Minute),
+		local:      true,
+	}
+
+	current, err := client.GetCurrent("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "London: " + iconPartlyCloudy + " +15°C (+14°C) 70% ↙11km/h"; current != want {
+		t.Errorf("expected %q, got %q", want, current)
+	}
+
+	forecast, err := client.GetForecast("London", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Weather report: London",
+		"Wednesday 2024-06-12: +9°C..+17°C",
+		"evening  " + iconLightRain + " +14°C Light rain, 60% chance of rain",
+		"Thursday 2024-06-13",
+	} {
+		if !strings.Contains(forecast, want) {
+			t.Errorf("expected %q in forecast:\n%s", want, forecast)
+		}
+	}
+
+	if len(paths) != 1 || paths[0] != "/London?format=j1" {
+		t.Errorf("expected a single j1 request, got %v", paths)
+	}
+}
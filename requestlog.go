@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	defaultRequestLogMaxBytes = 10 << 20
+	requestLogBuffer          = 256
+)
+
+// requestLogEntry is one JSONL line of the request log.
+type requestLogEntry struct {
+	Timestamp  string  `json:"timestamp"`
+	Method     string  `json:"method"`
+	Tool       string  `json:"tool,omitempty"`
+	Location   string  `json:"location,omitempty"`
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// requestLog appends entries to a JSONL file from a background goroutine so
+// that logging never delays a response. When the file reaches maxBytes it is
+// renamed to path.1 (replacing an older one) and a new file is started.
+type requestLog struct {
+	path     string
+	maxBytes int64
+	entries  chan requestLogEntry
+	done     chan struct{}
+
+	file *os.File
+	size int64
+}
+
+func newRequestLog(path string, maxBytes int64) (*requestLog, error) {
+	l := &requestLog{
+		path:     path,
+		maxBytes: maxBytes,
+		entries:  make(chan requestLogEntry, requestLogBuffer),
+		done:     make(chan struct{}),
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	go l.writeLoop()
+	return l, nil
+}
+
+func (l *requestLog) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening request log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("opening request log: %w", err)
+	}
+
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// Log queues an entry. If the writer has fallen behind the entry is dropped
+// rather than blocking the caller.
+func (l *requestLog) Log(entry requestLogEntry) {
+	select {
+	case l.entries <- entry:
+	default:
+	}
+}
+
+// Close flushes the queued entries and closes the file.
+func (l *requestLog) Close() {
+	close(l.entries)
+	<-l.done
+}
+
+func (l *requestLog) writeLoop() {
+	defer close(l.done)
+
+	for entry := range l.entries {
+		if l.file == nil {
+			continue
+		}
+
+		line, err := json.Marshal(entry)
+		if err != nil {
+			continue
+		}
+		line = append(line, '\n')
+
+		n, err := l.file.Write(line)
+		l.size += int64(n)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "request log: %v\n", err)
+		}
+
+		if l.maxBytes > 0 && l.size >= l.maxBytes {
+			l.rotate()
+		}
+	}
+
+	if l.file != nil {
+		l.file.Close()
+	}
+}
+
+func (l *requestLog) rotate() {
+	l.file.Close()
+	l.file = nil
+
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		fmt.Fprintf(os.Stderr, "request log: rotating: %v\n", err)
+	}
+	if err := l.open(); err != nil {
+		fmt.Fprintf(os.Stderr, "request log: %v\n", err)
+	}
+}
+
+// newRequestLogEntry describes a handled request and its response.
+func newRequestLogEntry(req JSONRPCRequest, resp *JSONRPCResponse, start time.Time) requestLogEntry {
+	entry := requestLogEntry{
+		Timestamp:  start.UTC().Format(time.RFC3339Nano),
+		Method:     req.Method,
+		Status:     "ok",
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if req.Method == "tools/call" {
+		var params struct {
+			Name      string `json:"name"`
+			Arguments struct {
+				Location string `json:"location"`
+			} `json:"arguments"`
+		}
+		if json.Unmarshal(req.Params, &params) == nil {
+			entry.Tool = params.Name
+			entry.Location = params.Arguments.Location
+		}
+	}
+
+	switch {
+	case resp == nil:
+	case resp.Error != nil:
+		entry.Status = "error"
+	default:
+		if result, ok := resp.Result.(map[string]interface{}); ok && result["isError"] == true {
+			entry.Status = "tool_error"
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readRequestLog(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening request log: %v", err)
+	}
+	defer f.Close()
+
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid JSONL line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRequestLogToolCall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	log, err := newRequestLog(path, defaultRequestLogMaxBytes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := &Server{weather: &mockWeather{currentResult: "ok"}, requestLog: log}
+	params := map[string]interface{}{
+		"name":      "get_current_weather",
+		"arguments": map[string]string{"location": "London"},
+	}
+	s.handleRequest(makeRequest("tools/call", 1, params))
+	s.handleRequest(makeRequest("unknown/method", 2, nil))
+	log.Close()
+
+	entries := readRequestLog(t, path)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	call := entries[0]
+	if call["method"] != "tools/call" || call["tool"] != "get_current_weather" || call["location"] != "London" {
+		t.Errorf("unexpected entry: %v", call)
+	}
+	if call["status"] != "ok" {
+		t.Errorf("expected status ok, got %v", call["status"])
+	}
+	if _, ok := call["timestamp"].(string); !ok {
+		t.Errorf("expected timestamp, got %v", call["timestamp"])
+	}
+	if _, ok := call["duration_ms"].(float64); !ok {
+		t.Errorf("expected duration_ms, got %v", call["duration_ms"])
+	}
+
+	if entries[1]["status"] != "error" {
+		t.Errorf("expected status error for unknown method, got %v", entries[1]["status"])
+	}
+}
+
+func TestRequestLogToolError(t *testing.T) {
+	resp := (&Server{}).errorResponse(1, os.ErrNotExist)
+	entry := newRequestLogEntry(makeRequest("tools/call", 1, map[string]interface{}{"name": "get_forecast"}), resp, time.Now())
+
+	if entry.Status != "tool_error" || entry.Tool != "get_forecast" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestRequestLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	log, err := newRequestLog(path, 200)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		log.Log(requestLogEntry{Timestamp: "2024-06-12T14:35:00Z", Method: "tools/call", Tool: "get_current_weather", Status: "ok"})
+	}
+	log.Close()
+
+	rotated := readRequestLog(t, path+".1")
+	current := readRequestLog(t, path)
+	// Each line is a little over 100 bytes, so the file rotates after every
+	// second entry and only the latest rotation is kept.
+	if len(rotated) != 2 || len(current) != 1 {
+		t.Errorf("expected 2 rotated and 1 current entries, got %d and %d", len(rotated), len(current))
+	}
+}
+
+func TestRequestLogDoesNotBlock(t *testing.T) {
+	log := &requestLog{entries: make(chan requestLogEntry)}
+
+	// Nobody reads the unbuffered channel; Log must drop instead of blocking.
+	log.Log(requestLogEntry{Method: "tools/list"})
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// resourceTemplates are the weather:// URIs resources/read accepts. The
+// forecast and detailed ones match the URIs of embedded resource results.
+var resourceTemplates = []map[string]string{
+	{
+		"uriTemplate": "weather://current/{location}",
+		"name":        "Current weather",
+		"description": "One-line summary of the current conditions at a location",
+		"mimeType":    "text/plain",
+	},
+	{
+		"uriTemplate": "weather://forecast/{location}{?days}",
+		"name":        "Weather forecast",
+		"description": "Text forecast for a location for 0-3 days (default: 3)",
+		"mimeType":    "text/plain",
+	},
+	{
+		"uriTemplate": "weather://detailed/{location}",
+		"name":        "Detailed weather",
+		"description": "Structured JSON weather data for a location",
+		"mimeType":    "application/json",
+	},
+}
+
+// handleResourcesList lists no concrete resources: every weather resource
+// is parameterized by location and advertised as a template instead.
+func (s *Server) handleResourcesList(req JSONRPCRequest) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"resources": []map[string]string{},
+		},
+	}
+}
+
+func (s *Server) handleResourceTemplatesList(req JSONRPCRequest) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"resourceTemplates": resourceTemplates,
+		},
+	}
+}
+
+func (s *Server) handleResourcesRead(req JSONRPCRequest) *JSONRPCResponse {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return s.paramError(req.ID, "Invalid params", err.Error())
+	}
+
+	resource, err := parseResourceURI(params.URI)
+	if err != nil {
+		return s.paramError(req.ID, "Invalid resource URI", err.Error())
+	}
+
+	var text, mimeType string
+	switch resource.kind {
+	case "current":
+		text, err = s.weather.GetCurrent(resource.location)
+		mimeType = "text/plain"
+	case "forecast":
+		text, err = s.weather.GetForecast(resource.location, resource.days)
+		mimeType = "text/plain"
+	case "detailed":
+		text, err = s.weather.GetDetailed(resource.location)
+		mimeType = "application/json"
+	}
+	if err != nil {
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error: &RPCError{
+				Code:    -32603,
+				Message: "Failed to read resource",
+				Data:    err.Error(),
+			},
+		}
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"contents": []map[string]string{
+				{"uri": params.URI, "mimeType": mimeType, "text": text},
+			},
+		},
+	}
+}
+
+// weatherResource is a parsed weather:// URI.
+type weatherResource struct {
+	kind     string
+	location string
+	days     int
+}
+
+// parseResourceURI parses a URI matching one of the resourceTemplates.
+func parseResourceURI(uri string) (weatherResource, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return weatherResource{}, err
+	}
+	if u.Scheme != "weather" {
+		return weatherResource{}, fmt.Errorf("unsupported scheme %q, expected weather://", u.Scheme)
+	}
+
+	r := weatherResource{kind: u.Host, location: strings.TrimPrefix(u.Path, "/"), days: 3}
+	if r.location == "" {
+		return weatherResource{}, fmt.Errorf("%s has no location", uri)
+	}
+	if err := checkCoordinates(r.location); err != nil {
+		return weatherResource{}, err
+	}
+
+	switch r.kind {
+	case "current", "detailed":
+	case "forecast":
+		if v := u.Query().Get("days"); v != "" {
+			days, err := strconv.Atoi(v)
+			if err != nil || days < 0 || days > 3 {
+				return weatherResource{}, fmt.Errorf("days must be between 0 and 3, got %q", v)
+			}
+			r.days = days
+		}
+	default:
+		return weatherResource{}, fmt.Errorf("unknown resource %q, expected current, forecast or detailed", r.kind)
+	}
+	return r, nil
+}
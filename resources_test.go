@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResourceTemplatesList(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+	resp := s.handleRequest(makeRequest("resources/templates/list", 1, nil))
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	templates := resp.Result.(map[string]interface{})["resourceTemplates"].([]map[string]string)
+
+	uris := map[string]bool{}
+	for _, template := range templates {
+		uri := template["uriTemplate"]
+		if !strings.Contains(uri, "{location}") {
+			t.Errorf("template %s has no {location} placeholder", uri)
+		}
+		uris[uri] = true
+	}
+	for _, want := range []string{"weather://current/{location}", "weather://forecast/{location}{?days}", "weather://detailed/{location}"} {
+		if !uris[want] {
+			t.Errorf("missing template %s", want)
+		}
+	}
+}
+
+func TestResourcesRead(t *testing.T) {
+	mock := &mockWeather{forecastResult: "forecast"}
+	s := &Server{weather: mock}
+
+	resp := s.handleRequest(makeRequest("resources/read", 1, map[string]string{"uri": "weather://forecast/New%20York?days=1"}))
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	contents := resp.Result.(map[string]interface{})["contents"].([]map[string]string)
+	if len(contents) != 1 || contents[0]["text"] != "forecast" || contents[0]["mimeType"] != "text/plain" {
+		t.Errorf("unexpected contents: %v", contents)
+	}
+	if mock.lastLocation != "New York" || mock.lastDays != 1 {
+		t.Errorf("unexpected fetch of %q for %d days", mock.lastLocation, mock.lastDays)
+	}
+}
+
+func TestResourcesReadErrors(t *testing.T) {
+	s := &Server{weather: &mockWeather{}}
+	for _, uri := range []string{"https://wttr.in/London", "weather://hourly/London", "weather://current/", "weather://forecast/London?days=9"} {
+		resp := s.handleRequest(makeRequest("resources/read", 1, map[string]string{"uri": uri}))
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("%s: expected invalid params error, got %+v", uri, resp)
+		}
+	}
+
+	s = &Server{weather: &mockWeather{err: errors.New("upstream down")}}
+	resp := s.handleRequest(makeRequest("resources/read", 1, map[string]string{"uri": "weather://current/London"}))
+	if resp.Error == nil || resp.Error.Code != -32603 {
+		t.Errorf("expected internal error for a failed fetch, got %+v", resp)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// validateArguments checks tool arguments against the subset of JSON Schema
+// the tool definitions use: type, properties, required, items, minItems,
+// maxItems, enum, minimum, exclusiveMinimum and maximum. It returns one
+// message per violation, or none if args match.
+func validateArguments(schema map[string]interface{}, args json.RawMessage) []string {
+	var value interface{}
+	if err := json.Unmarshal(args, &value); err != nil {
+		return []string{fmt.Sprintf("arguments: %v", err)}
+	}
+	return validateValue("arguments", schema, value)
+}
+
+func validateValue(path string, schema map[string]interface{}, value interface{}) []string {
+	if want, ok := schema["type"].(string); ok && !hasType(value, want) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, want, jsonType(value))}
+	}
+
+	var violations []string
+
+	if enum := stringList(schema["enum"]); enum != nil {
+		if !containsValue(enum, value) {
+			violations = append(violations, fmt.Sprintf("%s: %s is not one of %v", path, formatValue(value), enum))
+		}
+	}
+
+	if n, ok := value.(float64); ok {
+		if min, ok := schemaNumber(schema["minimum"]); ok && n < min {
+			violations = append(violations, fmt.Sprintf("%s: %g is less than the minimum %g", path, n, min))
+		}
+		if min, ok := schemaNumber(schema["exclusiveMinimum"]); ok && n <= min {
+			violations = append(violations, fmt.Sprintf("%s: %g is not greater than %g", path, n, min))
+		}
+		if max, ok := schemaNumber(schema["maximum"]); ok && n > max {
+			violations = append(violations, fmt.Sprintf("%s: %g is greater than the maximum %g", path, n, max))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range stringList(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				violations = append(violations, fmt.Sprintf("%s.%s: required", path, name))
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := properties[name].(map[string]interface{}); ok {
+				violations = append(violations, validateValue(path+"."+name, property, v[name])...)
+			}
+		}
+	case []interface{}:
+		if min, ok := schemaNumber(schema["minItems"]); ok && float64(len(v)) < min {
+			violations = append(violations, fmt.Sprintf("%s: %d items, fewer than the minimum %g", path, len(v), min))
+		}
+		if max, ok := schemaNumber(schema["maxItems"]); ok && float64(len(v)) > max {
+			violations = append(violations, fmt.Sprintf("%s: %d items, more than the maximum %g", path, len(v), max))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				violations = append(violations, validateValue(fmt.Sprintf("%s[%d]", path, i), items, item)...)
+			}
+		}
+	}
+
+	return violations
+}
+
+// hasType reports whether a decoded JSON value has the JSON Schema type.
+func hasType(value interface{}, want string) bool {
+	if want == "integer" {
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return jsonType(value) == want
+}
+
+// jsonType names the JSON Schema type of a decoded JSON value.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// stringList reads a schema keyword holding a list of strings.
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		strs := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs
+	}
+	return nil
+}
+
+// schemaNumber reads a numeric schema keyword, which the tool definitions
+// declare as Go ints or floats.
+func schemaNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func containsValue(enum []string, value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	for _, e := range enum {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValue(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
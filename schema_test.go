@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"location": map[string]interface{}{"type": "string"},
+			"days":     map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 3},
+			"radius":   map[string]interface{}{"type": "number", "exclusiveMinimum": 0},
+			"fields": map[string]interface{}{
+				"type":     "array",
+				"minItems": 1,
+				"maxItems": 2,
+				"items":    map[string]interface{}{"type": "string", "enum": []string{"pressure", "uv_index"}},
+			},
+		},
+		"required": []string{"location"},
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want []string
+	}{
+		{"valid", `{"location": "Oslo", "days": 2, "fields": ["pressure"]}`, nil},
+		{"type mismatch", `{"location": "Oslo", "days": "2"}`, []string{"arguments.days: expected integer, got string"}},
+		{"fractional integer", `{"location": "Oslo", "days": 1.5}`, []string{"arguments.days: expected integer, got number"}},
+		{"missing required", `{"days": 2}`, []string{"arguments.location: required"}},
+		{"out of range", `{"location": "Oslo", "days": 5}`, []string{"arguments.days: 5 is greater than the maximum 3"}},
+		{"enum", `{"location": "Oslo", "fields": ["pollen"]}`, []string{`arguments.fields[0]: "pollen" is not one of [pressure uv_index]`}},
+		{"exclusive minimum", `{"location": "Oslo", "radius": 0}`, []string{"arguments.radius: 0 is not greater than 0"}},
+		{"above exclusive minimum", `{"location": "Oslo", "radius": 0.5}`, nil},
+		{"too few items", `{"location": "Oslo", "fields": []}`, []string{"arguments.fields: 0 items, fewer than the minimum 1"}},
+		{"too many items", `{"location": "Oslo", "fields": ["pressure", "uv_index", "pressure"]}`, []string{"arguments.fields: 3 items, more than the maximum 2"}},
+		{"not an object", `[]`, []string{"arguments: expected object, got array"}},
+	}
+
+	for _, tt := range tests {
+		got := validateArguments(testSchema(), json.RawMessage(tt.args))
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateArgumentsToolSchemas(t *testing.T) {
+	s := &Server{}
+
+	schema, ok := s.toolSchema("get_current_weather")
+	if !ok {
+		t.Fatal("expected get_current_weather schema")
+	}
+	if got := validateArguments(schema, json.RawMessage(`{"location": "Oslo", "fields": ["pressure"], "include_provenance": true}`)); len(got) != 0 {
+		t.Errorf("expected valid arguments, got %q", got)
+	}
+	if got := validateArguments(schema, json.RawMessage(`{"include_provenance": "yes"}`)); len(got) != 2 {
+		t.Errorf("expected missing location and provenance type violations, got %q", got)
+	}
+
+	rank, ok := s.toolSchema("rank_by_temperature")
+	if !ok {
+		t.Fatal("expected rank_by_temperature schema")
+	}
+	if got := validateArguments(rank, json.RawMessage(`{"locations": []}`)); len(got) != 1 {
+		t.Errorf("expected the empty locations list to be rejected, got %q", got)
+	}
+}
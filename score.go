@@ -0,0 +1,75 @@
+package main
+
+import "math"
+
+// Weights of the weather score components. They add up to 100.
+const (
+	scoreWeightTemperature   = 40
+	scoreWeightPrecipitation = 25
+	scoreWeightWind          = 20
+	scoreWeightVisibility    = 15
+)
+
+// weatherScore rates the current conditions from 0 (miserable) to 100 (perfect).
+//
+// Each component earns up to its weight:
+//   - temperature: full marks when it feels like 18-24°C, zero at 15°C outside that range
+//   - precipitation: full marks when dry, zero at 5 mm or more
+//   - wind: full marks up to 10 km/h, zero at 50 km/h
+//   - visibility: full marks at 10 km or more, scaled down linearly below that
+func weatherScore(w DetailedWeather) (int, map[string]int) {
+	components := map[string]int{
+		"temperature":   0,
+		"precipitation": 0,
+		"wind":          0,
+		"visibility":    0,
+	}
+
+	cur, err := w.current()
+	if err != nil {
+		return 0, components
+	}
+
+	feels := float64(cur.FeelsLikeC)
+	var deviation float64
+	switch {
+	case feels < 18:
+		deviation = 18 - feels
+	case feels > 24:
+		deviation = feels - 24
+	}
+
+	components["temperature"] = scorePoints(scoreWeightTemperature, 1-deviation/15)
+	components["precipitation"] = scorePoints(scoreWeightPrecipitation, 1-float64(cur.PrecipMM)/5)
+	components["wind"] = scorePoints(scoreWeightWind, 1-(float64(cur.WindSpeedKmph)-10)/40)
+	components["visibility"] = scorePoints(scoreWeightVisibility, float64(cur.Visibility)/10)
+
+	score := 0
+	for _, points := range components {
+		score += points
+	}
+	return score, components
+}
+
+// scorePoints scales weight by fraction clamped to [0, 1].
+func scorePoints(weight int, fraction float64) int {
+	fraction = math.Max(0, math.Min(1, fraction))
+	return int(math.Round(float64(weight) * fraction))
+}
+
+// GetWeatherScore returns the weather score and its components as JSON.
+func (c *WeatherClient) GetWeatherScore(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.current(); err != nil {
+		return "", err
+	}
+
+	score, components := weatherScore(w)
+	return marshalResult(map[string]interface{}{
+		"score":      score,
+		"components": components,
+	})
+}
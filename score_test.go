@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWeatherScorePerfectDay(t *testing.T) {
+	w := DetailedWeather{CurrentCondition: []CurrentCondition{{
+		FeelsLikeC:    21,
+		PrecipMM:      0,
+		WindSpeedKmph: 6,
+		Visibility:    10,
+	}}}
+
+	score, components := weatherScore(w)
+	if score != 100 {
+		t.Errorf("expected perfect score 100, got %d (%v)", score, components)
+	}
+}
+
+func TestWeatherScoreStorm(t *testing.T) {
+	w := DetailedWeather{CurrentCondition: []CurrentCondition{{
+		FeelsLikeC:    4,
+		PrecipMM:      12,
+		WindSpeedKmph: 65,
+		Visibility:    2,
+	}}}
+
+	score, components := weatherScore(w)
+	if score > 20 {
+		t.Errorf("expected storm to score low, got %d (%v)", score, components)
+	}
+	if components["precipitation"] != 0 || components["wind"] != 0 {
+		t.Errorf("expected zero precipitation and wind points, got %v", components)
+	}
+	if components["visibility"] != 3 {
+		t.Errorf("expected 3 visibility points, got %d", components["visibility"])
+	}
+}
+
+func TestWeatherScoreComponentsSum(t *testing.T) {
+	w := DetailedWeather{CurrentCondition: []CurrentCondition{{
+		FeelsLikeC:    29,
+		PrecipMM:      1,
+		WindSpeedKmph: 20,
+		Visibility:    8,
+	}}}
+
+	score, components := weatherScore(w)
+	sum := 0
+	for _, points := range components {
+		sum += points
+	}
+	if sum != score {
+		t.Errorf("components %v sum to %d, score is %d", components, sum, score)
+	}
+}
+
+func TestWeatherClientGetWeatherScore(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(sampleJ1))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	result, err := client.GetWeatherScore("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, `"score":`) || !strings.Contains(result, `"components":`) {
+		t.Errorf("unexpected result: %s", result)
+	}
+}
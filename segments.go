@@ -0,0 +1,98 @@
+package main
+
+import "fmt"
+
+// maxDayOffset is the last day of the j1 forecast (today plus two days).
+const maxDayOffset = 2
+
+// daySegments are the parts of the day wttr.in's own forecast shows, by the
+// time of the hourly slot that represents each.
+var daySegments = []struct {
+	name string
+	time int
+}{
+	{"morning", 900},
+	{"noon", 1200},
+	{"evening", 1800},
+	{"night", 2100},
+}
+
+// DaySegment is the weather for one part of a day.
+type DaySegment struct {
+	Segment      string    `json:"segment"`
+	Time         timeValue `json:"time"`
+	TempC        float64   `json:"temp_c"`
+	FeelsLikeC   float64   `json:"feels_like_c"`
+	TempF        *float64  `json:"temp_f,omitempty"`
+	FeelsLikeF   *float64  `json:"feels_like_f,omitempty"`
+	Description  string    `json:"description"`
+	ChanceOfRain int       `json:"chance_of_rain"`
+}
+
+// DaySegments lists the segments of a forecast day.
+type DaySegments struct {
+	Date     string       `json:"date"`
+	Day      string       `json:"day"`
+	Segments []DaySegment `json:"segments"`
+}
+
+// daySegment describes the hourly slot that stands for a part of the day.
+func (c *WeatherClient) daySegment(name string, h HourlyWeather) DaySegment {
+	return DaySegment{
+		Segment:      name,
+		Time:         slotClock(h.Time),
+		TempC:        float64(h.TempC),
+		FeelsLikeC:   float64(h.FeelsLikeC),
+		TempF:        c.fahrenheit(h.TempF),
+		FeelsLikeF:   c.fahrenheit(h.FeelsLikeF),
+		Description:  h.WeatherDesc.String(),
+		ChanceOfRain: int(h.ChanceOfRain),
+	}
+}
+
+// daySegmentsFor picks the morning, noon, evening and night slots of the
+// forecast day at offset. Segments whose slot is missing are left out.
+func (c *WeatherClient) daySegmentsFor(w DetailedWeather, offset int) (DaySegments, error) {
+	if offset < 0 || offset >= len(w.Weather) {
+		return DaySegments{}, invalidArgument("day %d is beyond the %d-day forecast", offset, len(w.Weather))
+	}
+	d := w.Weather[offset]
+
+	result := DaySegments{Date: d.Date, Day: summarizeDay(d).Day, Segments: []DaySegment{}}
+	for _, seg := range daySegments {
+		for _, h := range d.Hourly {
+			if int(h.Time) != seg.time {
+				continue
+			}
+			result.Segments = append(result.Segments, c.daySegment(seg.name, h))
+			break
+		}
+	}
+
+	if len(result.Segments) == 0 {
+		return DaySegments{}, fmt.Errorf("no hourly forecast for %s", d.Date)
+	}
+	return result, nil
+}
+
+// GetDaySegments returns the morning, noon, evening and night forecast for
+// the day at dayOffset (0 is today) as JSON.
+func (c *WeatherClient) GetDaySegments(location string, dayOffset int) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := c.daySegmentsFor(w, dayOffset)
+	if err != nil {
+		return "", err
+	}
+	if c.convertsTimes() {
+		for i, seg := range result.Segments {
+			if result.Segments[i].Time, err = c.zoneClock(w, result.Date, seg.Time); err != nil {
+				return "", err
+			}
+		}
+	}
+	return marshalResult(result)
+}
@@ -0,0 +1,66 @@
+package main
+
+import "testing"
+
+func segmentsFixture() DetailedWeather {
+	d := DayForecast{Date: "2024-06-12"}
+	descs := []string{"Clear", "Clear", "Mist", "Sunny", "Partly cloudy", "Light rain", "Overcast", "Clear"}
+	for i, desc := range descs {
+		d.Hourly = append(d.Hourly, HourlyWeather{
+			Time:         number(i * 300),
+			TempC:        number(10 + i),
+			FeelsLikeC:   number(9 + i),
+			ChanceOfRain: number(10 * i),
+			WeatherDesc:  text{{Value: desc}},
+		})
+	}
+	return DetailedWeather{Weather: []DayForecast{d}}
+}
+
+func TestDaySegmentsFor(t *testing.T) {
+	result, err := new(WeatherClient).daySegmentsFor(segmentsFixture(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Date != "2024-06-12" || result.Day != "Wednesday" {
+		t.Errorf("unexpected day: %+v", result)
+	}
+	want := []string{"morning", "noon", "evening", "night"}
+	if len(result.Segments) != len(want) {
+		t.Fatalf("expected %d segments, got %+v", len(want), result.Segments)
+	}
+	for i, seg := range result.Segments {
+		if seg.Segment != want[i] {
+			t.Errorf("segment %d: expected %s, got %s", i, want[i], seg.Segment)
+		}
+	}
+	// Noon is the fifth slot (12:00) and night the last (21:00).
+	if noon := result.Segments[1]; noon.Time != "12:00" || noon.TempC != 14 || noon.Description != "Partly cloudy" || noon.ChanceOfRain != 40 {
+		t.Errorf("unexpected noon segment: %+v", noon)
+	}
+	if night := result.Segments[3]; night.Time != "21:00" || night.TempC != 17 || night.FeelsLikeC != 16 {
+		t.Errorf("unexpected night segment: %+v", night)
+	}
+}
+
+func TestDaySegmentsForBeyondForecast(t *testing.T) {
+	w := forecastFixture("2024-06-12 02:35 PM", 3)
+
+	if _, err := new(WeatherClient).daySegmentsFor(w, 3); err == nil {
+		t.Fatal("expected error beyond the forecast")
+	}
+}
+
+func TestDaySegmentsForSkipsMissingSlots(t *testing.T) {
+	// The fixture only has 09:00 and 12:00 slots.
+	w := forecastFixture("2024-06-12 02:35 PM", 3)
+
+	result, err := new(WeatherClient).daySegmentsFor(w, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Segments) != 2 || result.Day != "Thursday" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
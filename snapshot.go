@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Snapshot is the current conditions in a fixed, minimal shape for
+// periodic polling and logging, with one-letter keys to keep it small.
+type Snapshot struct {
+	TempC       int    `json:"t"`
+	Humidity    int    `json:"h"`
+	WindKmph    int    `json:"w"`
+	WindDegree  int    `json:"d"`
+	WeatherCode int    `json:"c"`
+	Time        string `json:"ts"`
+}
+
+// snapshot condenses the current conditions. The observation time is
+// given in UTC as RFC 3339, or as local time without a zone when the UTC
+// offset can't be derived.
+func snapshot(w DetailedWeather) (Snapshot, error) {
+	cur, err := w.current()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	local, err := time.Parse(j1DateTimeLayout, cur.LocalObsDateTime)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("parsing observation time %q: %w", cur.LocalObsDateTime, err)
+	}
+
+	ts := local.Format("2006-01-02T15:04:05")
+	if offset, err := utcOffset(w); err == nil {
+		ts = local.Add(-offset).Format(time.RFC3339)
+	}
+
+	round := func(n number) int { return int(math.Round(float64(n))) }
+	return Snapshot{
+		TempC:       round(cur.TempC),
+		Humidity:    round(cur.Humidity),
+		WindKmph:    round(cur.WindSpeedKmph),
+		WindDegree:  round(cur.WindDirDegree),
+		WeatherCode: round(cur.WeatherCode),
+		Time:        ts,
+	}, nil
+}
+
+// GetSnapshot returns the current conditions as a compact JSON snapshot.
+func (c *WeatherClient) GetSnapshot(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	s, err := snapshot(w)
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(s)
+}
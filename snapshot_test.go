@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWeatherClientGetSnapshot(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"current_condition": [{
+			"temp_C": "20", "humidity": "45", "windspeedKmph": "5", "winddirDegree": "338", "weatherCode": "113",
+			"localObsDateTime": "2024-06-12 02:35 PM", "observation_time": "01:35 PM",
+			"weatherDesc": [{"value": "Sunny"}]
+		}]}`))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	result, err := client.GetSnapshot("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"t":20,"h":45,"w":5,"d":338,"c":113,"ts":"2024-06-12T13:35:00Z"}`; result != want {
+		t.Errorf("got %s, want %s", result, want)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(result), &fields); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	var keys []string
+	for key, value := range fields {
+		keys = append(keys, key)
+		if _, isNumber := value.(float64); isNumber != (key != "ts") {
+			t.Errorf("%s: unexpected type %T", key, value)
+		}
+	}
+	sort.Strings(keys)
+	if got := strings.Join(keys, ","); got != "c,d,h,t,ts,w" {
+		t.Errorf("unexpected keys: %s", got)
+	}
+}
+
+func TestSnapshotLocalTime(t *testing.T) {
+	// Without the UTC observation the local time is given without a zone.
+	w := DetailedWeather{CurrentCondition: []CurrentCondition{{TempC: -3, LocalObsDateTime: "2024-01-05 07:10 AM"}}}
+
+	s, err := snapshot(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Time != "2024-01-05T07:10:00" || s.TempC != -3 {
+		t.Errorf("unexpected snapshot: %+v", s)
+	}
+
+	w.CurrentCondition[0].LocalObsDateTime = ""
+	if _, err := snapshot(w); err == nil {
+		t.Error("expected an error without an observation time")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// compassWords spells out the 16-point wind directions j1 reports.
+var compassWords = map[string]string{
+	"N": "north", "NNE": "north-northeast", "NE": "northeast", "ENE": "east-northeast",
+	"E": "east", "ESE": "east-southeast", "SE": "southeast", "SSE": "south-southeast",
+	"S": "south", "SSW": "south-southwest", "SW": "southwest", "WSW": "west-southwest",
+	"W": "west", "WNW": "west-northwest", "NW": "northwest", "NNW": "north-northwest",
+}
+
+// ssmlBreak is the pause between the sentences of an SSML report.
+const ssmlBreak = `<break time="400ms"/>`
+
+// spokenDegrees spells out a temperature: "minus 3 degrees Celsius".
+func spokenDegrees(c float64) string {
+	n := int(math.Round(c))
+	unit := "degrees"
+	if n == 1 || n == -1 {
+		unit = "degree"
+	}
+	if n < 0 {
+		return fmt.Sprintf("minus %d %s Celsius", -n, unit)
+	}
+	return fmt.Sprintf("%d %s Celsius", n, unit)
+}
+
+// weatherSSML renders the current conditions as sentences separated by
+// pauses, with every unit spelled out for text-to-speech.
+func weatherSSML(place string, cur CurrentCondition) string {
+	var sentences []string
+
+	opening := "It is currently " + spokenDegrees(float64(cur.TempC))
+	if place != "" {
+		opening = "In " + place + ", it is currently " + spokenDegrees(float64(cur.TempC))
+	}
+	if math.Round(float64(cur.FeelsLikeC)) != math.Round(float64(cur.TempC)) {
+		opening += ", feeling like " + spokenDegrees(float64(cur.FeelsLikeC))
+	}
+	sentences = append(sentences, opening+".")
+
+	if desc := strings.TrimSpace(cur.WeatherDesc.String()); desc != "" {
+		sentences = append(sentences, desc+".")
+	}
+
+	details := fmt.Sprintf("Humidity is %d percent", int(cur.Humidity))
+	if speed := int(cur.WindSpeedKmph); speed > 0 {
+		details += fmt.Sprintf(", with wind at %d kilometers per hour", speed)
+		if dir, ok := compassWords[cur.WindDir16Point]; ok {
+			details += " from the " + dir
+		}
+	} else {
+		details += ", and the air is calm"
+	}
+	sentences = append(sentences, details+".")
+
+	for i, sentence := range sentences {
+		var escaped bytes.Buffer
+		xml.EscapeText(&escaped, []byte(sentence))
+		sentences[i] = escaped.String()
+	}
+	return "<speak>" + strings.Join(sentences, ssmlBreak) + "</speak>"
+}
+
+// GetWeatherSSML returns the current conditions as an SSML snippet.
+func (c *WeatherClient) GetWeatherSSML(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	cur, err := w.current()
+	if err != nil {
+		return "", err
+	}
+
+	var place string
+	if len(w.NearestArea) > 0 {
+		place = w.NearestArea[0].AreaName.String()
+	}
+	return weatherSSML(place, cur), nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWeatherSSML(t *testing.T) {
+	cur := CurrentCondition{
+		TempC:          20,
+		FeelsLikeC:     19,
+		Humidity:       45,
+		WeatherDesc:    text{{Value: "Partly cloudy"}},
+		WindSpeedKmph:  5,
+		WindDir16Point: "NW",
+	}
+
+	got := weatherSSML("Bath & Wells", cur)
+	want := `<speak>In Bath &amp; Wells, it is currently 20 degrees Celsius, feeling like 19 degrees Celsius.` +
+		`<break time="400ms"/>Partly cloudy.` +
+		`<break time="400ms"/>Humidity is 45 percent, with wind at 5 kilometers per hour from the northwest.</speak>`
+	if got != want {
+		t.Errorf("unexpected SSML:\n got %s\nwant %s", got, want)
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"speak"`
+	}
+	if err := xml.Unmarshal([]byte(got), &doc); err != nil {
+		t.Errorf("invalid SSML: %v", err)
+	}
+}
+
+func TestWeatherSSMLSpellsOutUnits(t *testing.T) {
+	got := weatherSSML("", CurrentCondition{TempC: -1, FeelsLikeC: -6, Humidity: 80})
+
+	for _, want := range []string{"It is currently minus 1 degree Celsius", "feeling like minus 6 degrees Celsius", "80 percent", "the air is calm"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %s", want, got)
+		}
+	}
+	for _, symbol := range []string{"°", "%", "km/h"} {
+		if strings.Contains(got, symbol) {
+			t.Errorf("unexpected unit symbol %q in %s", symbol, got)
+		}
+	}
+}
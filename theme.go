@@ -0,0 +1,54 @@
+package main
+
+// Theme selects the glyphs used in prose output: "emoji", "ascii" or "none".
+type Theme string
+
+const (
+	themeNone  Theme = "none"
+	themeEmoji Theme = "emoji"
+	themeASCII Theme = "ascii"
+)
+
+// glyph names a condition that prose output can be decorated with.
+type glyph int
+
+const (
+	glyphSun glyph = iota
+	glyphMoon
+	glyphUmbrella
+	glyphThermometer
+	glyphPin
+	glyphWind
+	glyphDroplet
+)
+
+// glyphs holds the emoji and ASCII rendering of each glyph.
+var glyphs = map[glyph]struct{ emoji, ascii string }{
+	glyphSun:         {"☀️", "[sun]"},
+	glyphMoon:        {"🌙", "[moon]"},
+	glyphUmbrella:    {"☂️", "[umbrella]"},
+	glyphThermometer: {"🌡️", "[temp]"},
+	glyphPin:         {"📍", "[at]"},
+	glyphWind:        {"💨", "[wind]"},
+	glyphDroplet:     {"💧", "[humidity]"},
+}
+
+// glyph returns the rendering of g in the theme, or "" for no glyph.
+func (t Theme) glyph(g glyph) string {
+	switch t {
+	case themeEmoji:
+		return glyphs[g].emoji
+	case themeASCII:
+		return glyphs[g].ascii
+	default:
+		return ""
+	}
+}
+
+// decorate prefixes s with the theme's rendering of g.
+func (t Theme) decorate(g glyph, s string) string {
+	if prefix := t.glyph(g); prefix != "" {
+		return prefix + " " + s
+	}
+	return s
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestThemeDecorate(t *testing.T) {
+	tests := []struct {
+		theme Theme
+		want  string
+	}{
+		{themeEmoji, "☀️ Low UV."},
+		{themeASCII, "[sun] Low UV."},
+		{themeNone, "Low UV."},
+		{"", "Low UV."},
+	}
+
+	for _, tt := range tests {
+		if got := tt.theme.decorate(glyphSun, "Low UV."); got != tt.want {
+			t.Errorf("theme %q: got %q, want %q", tt.theme, got, tt.want)
+		}
+	}
+}
+
+func TestWeatherClientGetSunSafetyTheme(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"current_condition": [{"uvIndex": "2", "cloudcover": "0", "localObsDateTime": "2024-06-12 02:35 PM"}],
+			"weather": [{"date": "2024-06-12", "astronomy": [{"sunrise": "04:43 AM", "sunset": "09:18 PM"}]}]
+		}`))
+	}))
+	defer srv.Close()
+
+	for theme, prefix := range map[Theme]string{themeEmoji: `"advice":"☀️ Low UV`, themeASCII: `"advice":"[sun] Low UV`, themeNone: `"advice":"Low UV`} {
+		client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL, theme: theme}
+
+		result, err := client.GetSunSafety("London")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(result, prefix) {
+			t.Errorf("theme %q: expected %s in %s", theme, prefix, result)
+		}
+	}
+}
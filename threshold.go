@@ -0,0 +1,89 @@
+package main
+
+// Threshold directions.
+const (
+	thresholdAbove = "above"
+	thresholdBelow = "below"
+)
+
+// ThresholdCheck reports whether today's temperature goes past a threshold.
+type ThresholdCheck struct {
+	Date       string  `json:"date"`
+	Day        string  `json:"day"`
+	ThresholdC float64 `json:"threshold_c"`
+	Direction  string  `json:"direction"`
+	Crossed    bool    `json:"crossed"`
+	// First is the earliest slot past the threshold.
+	First *TempAt `json:"first,omitempty"`
+	// Slots lists every slot past the threshold.
+	Slots []TempAt `json:"slots"`
+	// Extreme is the warmest slot when checking above and the coldest when
+	// checking below, so a miss shows how close it came.
+	Extreme TempAt `json:"extreme"`
+}
+
+// thresholdCheck scans today's hourly slots for temperatures strictly
+// above or below threshold.
+func (c *WeatherClient) thresholdCheck(w DetailedWeather, threshold float64, direction string) (ThresholdCheck, error) {
+	if direction != thresholdAbove && direction != thresholdBelow {
+		return ThresholdCheck{}, invalidArgument("direction must be %s or %s, got %q", thresholdAbove, thresholdBelow, direction)
+	}
+	extremes, err := c.tempExtremes(w, 0)
+	if err != nil {
+		return ThresholdCheck{}, err
+	}
+
+	result := ThresholdCheck{
+		Date:       extremes.Date,
+		Day:        extremes.Day,
+		ThresholdC: threshold,
+		Direction:  direction,
+		Slots:      []TempAt{},
+		Extreme:    extremes.Warmest,
+	}
+	if direction == thresholdBelow {
+		result.Extreme = extremes.Coldest
+	}
+
+	for _, h := range w.Weather[0].Hourly {
+		temp := float64(h.TempC)
+		if (direction == thresholdAbove && temp > threshold) || (direction == thresholdBelow && temp < threshold) {
+			result.Slots = append(result.Slots, TempAt{Time: slotClock(h.Time), TempC: temp, TempF: c.fahrenheit(h.TempF)})
+		}
+	}
+	if len(result.Slots) > 0 {
+		result.Crossed = true
+		first := result.Slots[0]
+		result.First = &first
+	}
+	return result, nil
+}
+
+// GetThresholdCheck returns whether and when today's temperature goes
+// above or below threshold degrees Celsius as JSON.
+func (c *WeatherClient) GetThresholdCheck(location string, threshold float64, direction string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := c.thresholdCheck(w, threshold, direction)
+	if err != nil {
+		return "", err
+	}
+	if c.convertsTimes() {
+		for i := range result.Slots {
+			if result.Slots[i].Time, err = c.zoneClock(w, result.Date, result.Slots[i].Time); err != nil {
+				return "", err
+			}
+		}
+		if result.First != nil {
+			first := result.Slots[0]
+			result.First = &first
+		}
+		if result.Extreme.Time, err = c.zoneClock(w, result.Date, result.Extreme.Time); err != nil {
+			return "", err
+		}
+	}
+	return marshalResult(result)
+}
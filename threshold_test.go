@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestThresholdCheckCrossed(t *testing.T) {
+	// segmentsFixture warms from 10°C at midnight to 17°C at 21:00.
+	result, err := new(WeatherClient).thresholdCheck(segmentsFixture(), 15, thresholdAbove)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !result.Crossed || result.First == nil || *result.First != (TempAt{Time: "18:00", TempC: 16}) {
+		t.Errorf("expected the threshold crossed at 18:00, got %+v", result)
+	}
+	if len(result.Slots) != 2 || result.Slots[1] != (TempAt{Time: "21:00", TempC: 17}) {
+		t.Errorf("unexpected slots: %+v", result.Slots)
+	}
+
+	below, err := new(WeatherClient).thresholdCheck(segmentsFixture(), 11, thresholdBelow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !below.Crossed || below.First.Time != "00:00" || below.Extreme != (TempAt{Time: "00:00", TempC: 10}) {
+		t.Errorf("expected only midnight below 11°C, got %+v", below)
+	}
+}
+
+func TestThresholdCheckNotCrossed(t *testing.T) {
+	// Reaching the threshold is not exceeding it.
+	result, err := new(WeatherClient).thresholdCheck(segmentsFixture(), 17, thresholdAbove)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Crossed || result.First != nil || len(result.Slots) != 0 {
+		t.Errorf("expected no crossing, got %+v", result)
+	}
+	if result.Extreme != (TempAt{Time: "21:00", TempC: 17}) {
+		t.Errorf("expected the warmest slot as the extreme, got %+v", result.Extreme)
+	}
+}
+
+func TestThresholdCheckDirection(t *testing.T) {
+	if _, err := new(WeatherClient).thresholdCheck(segmentsFixture(), 15, "over"); errorCode(err) != errorCodeInvalidArguments {
+		t.Errorf("expected an invalid argument error, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// zonedLayout is how times are rendered when a client asks for a timezone.
+// The date and zone abbreviation are included because converted times can
+// fall on another day than the location's.
+const zonedLayout = "2006-01-02 15:04 MST"
+
+// utcOffset derives the location's UTC offset from the j1 observation, which
+// is reported both in local time (localObsDateTime) and in UTC
+// (observation_time). The difference is rounded to a quarter hour.
+func utcOffset(w DetailedWeather) (time.Duration, error) {
+	cur, err := w.current()
+	if err != nil {
+		return 0, err
+	}
+	local, err := time.Parse(j1DateTimeLayout, cur.LocalObsDateTime)
+	if err != nil {
+		return 0, fmt.Errorf("parsing observation time %q: %w", cur.LocalObsDateTime, err)
+	}
+	utc, err := parseClock(cur.ObservationTime)
+	if err != nil {
+		return 0, fmt.Errorf("parsing UTC observation time: %w", err)
+	}
+
+	localClock := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	offset := localClock - utc
+	// The UTC observation can be on the previous or next day.
+	switch {
+	case offset > 14*time.Hour:
+		offset -= 24 * time.Hour
+	case offset <= -12*time.Hour:
+		offset += 24 * time.Hour
+	}
+	return offset.Round(15 * time.Minute), nil
+}
+
+// timeValue is a time in a parsed result: the location's local HH:MM, a
+// time in a requested timezone, or Unix seconds, which are marshaled as a
+// JSON number.
+type timeValue string
+
+func (t timeValue) MarshalJSON() ([]byte, error) {
+	if _, err := strconv.ParseInt(string(t), 10, 64); err == nil {
+		return []byte(t), nil
+	}
+	return json.Marshal(string(t))
+}
+
+// slotClock renders a j1 hourly slot time such as 900 as HH:MM.
+func slotClock(t number) timeValue {
+	return timeValue(fmt.Sprintf("%02d:%02d", int(t)/100, int(t)%100))
+}
+
+// WithTimezone returns a copy of the client that renders the times in its
+// parsed results in loc instead of the location's local time.
+func (c *WeatherClient) WithTimezone(loc *time.Location) WeatherService {
+	zoned := *c
+	zoned.tz = loc
+	return &zoned
+}
+
+// WithEpochTimes returns a copy of the client that renders the times in
+// its parsed results as Unix seconds.
+func (c *WeatherClient) WithEpochTimes() WeatherService {
+	epoch := *c
+	epoch.epoch = true
+	return &epoch
+}
+
+// convertsTimes reports whether times in parsed results are rendered
+// other than as the location's local HH:MM.
+func (c *WeatherClient) convertsTimes() bool {
+	return c.tz != nil || c.epoch
+}
+
+// zoneTime renders a wall clock time at the location as Unix seconds or in
+// the requested timezone. Otherwise it is returned as 24-hour HH:MM.
+//
+// j1 gives the location's offset, not its zone, so the offset observed
+// today is applied to every date: times after a daylight saving change
+// within the forecast are off by the shift.
+func (c *WeatherClient) zoneTime(w DetailedWeather, wall time.Time) (timeValue, error) {
+	if !c.convertsTimes() {
+		return timeValue(wall.Format("15:04")), nil
+	}
+
+	offset, err := utcOffset(w)
+	if err != nil {
+		if c.epoch {
+			return "", fmt.Errorf("cannot convert to Unix time: %w", err)
+		}
+		return "", fmt.Errorf("cannot convert to %s: %w", c.tz, err)
+	}
+	at := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, time.FixedZone("", int(offset.Seconds())))
+	if c.epoch {
+		return timeValue(strconv.FormatInt(at.Unix(), 10)), nil
+	}
+	return timeValue(at.In(c.tz).Format(zonedLayout)), nil
+}
+
+// zoneClock is zoneTime for an HH:MM clock on a j1 date.
+func (c *WeatherClient) zoneClock(w DetailedWeather, date string, clock timeValue) (timeValue, error) {
+	wall, err := time.Parse(j1DateLayout+" 15:04", date+" "+string(clock))
+	if err != nil {
+		return "", fmt.Errorf("parsing time %q on %s: %w", clock, date, err)
+	}
+	return c.zoneTime(w, wall)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUTCOffset(t *testing.T) {
+	tests := []struct {
+		local, utc string
+		want       time.Duration
+	}{
+		{"2024-06-12 02:35 PM", "01:35 PM", time.Hour},
+		{"2024-06-12 01:30 AM", "08:30 PM", 5 * time.Hour},
+		{"2024-06-12 08:00 PM", "11:00 PM", -3 * time.Hour},
+		{"2024-06-12 11:05 PM", "01:19 PM", 9*time.Hour + 45*time.Minute},
+	}
+
+	for _, tt := range tests {
+		w := DetailedWeather{CurrentCondition: []CurrentCondition{{LocalObsDateTime: tt.local, ObservationTime: tt.utc}}}
+		got, err := utcOffset(w)
+		if err != nil {
+			t.Errorf("%s / %s: unexpected error: %v", tt.local, tt.utc, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s / %s: got %v, want %v", tt.local, tt.utc, got, tt.want)
+		}
+	}
+}
+
+func TestWeatherClientGetDaylightTimezone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"current_condition": [{"localObsDateTime": "2024-06-12 02:35 PM", "observation_time": "01:35 PM"}],
+			"weather": [{"date": "2024-06-12", "astronomy": [{"sunrise": "04:43 AM", "sunset": "09:21 PM"}]}]
+		}`))
+	}))
+	defer srv.Close()
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	client := (&WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}).WithTimezone(ny)
+
+	result, err := client.GetDaylight("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// London is UTC+1 in June and New York UTC-4.
+	if !strings.Contains(result, `"sunrise":"2024-06-11 23:43 EDT"`) || !strings.Contains(result, `"sunset":"2024-06-12 16:21 EDT"`) {
+		t.Errorf("unexpected result: %s", result)
+	}
+	if !strings.Contains(result, `"day_length":"16:38"`) {
+		t.Errorf("expected day length unchanged: %s", result)
+	}
+}
+
+func TestWeatherClientGetNowcastTimezone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"current_condition": [{"localObsDateTime": "2024-06-12 01:00 PM", "observation_time": "12:00 PM"}],
+			"weather": [{"date": "2024-06-12", "hourly": [{"time": "1200"}, {"time": "1500"}]}]
+		}`))
+	}))
+	defer srv.Close()
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	client := (&WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}).WithTimezone(ny)
+
+	result, err := client.GetNowcast("London", 60)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, `"from":"2024-06-12 08:00 EDT","to":"2024-06-12 09:00 EDT"`) {
+		t.Errorf("unexpected result: %s", result)
+	}
+}
+
+func TestWeatherClientZoneTimeWithoutOffset(t *testing.T) {
+	client := &WeatherClient{tz: time.UTC}
+
+	if _, err := client.zoneClock(DetailedWeather{}, "2024-06-12", "12:00"); err == nil {
+		t.Fatal("expected error without an observation to derive the offset from")
+	}
+}
+
+func TestWeatherClientGetDaylightEpoch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"current_condition": [{"localObsDateTime": "2024-06-12 02:35 PM", "observation_time": "01:35 PM"}],
+			"weather": [{"date": "2024-06-12", "astronomy": [{"sunrise": "04:43 AM", "sunset": "09:21 PM"}]}]
+		}`))
+	}))
+	defer srv.Close()
+
+	client := (&WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}).WithEpochTimes()
+
+	result, err := client.GetDaylight("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 04:43 BST (UTC+1) on 2024-06-12 is 03:43 UTC.
+	if !strings.Contains(result, `"sunrise":1718163780`) || !strings.Contains(result, `"sunset":1718223660`) {
+		t.Errorf("unexpected result: %s", result)
+	}
+}
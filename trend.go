@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Changes within these bounds over the trend window count as steady.
+const (
+	steadyTempC      = 0.5
+	steadyPressureMB = 1
+)
+
+// Reading is one observation of the current conditions.
+type Reading struct {
+	FetchedAt  string  `json:"fetched_at"`
+	ObservedAt string  `json:"observed_at"`
+	TempC      float64 `json:"temp_c"`
+	PressureMB float64 `json:"pressure_mb"`
+}
+
+// ShortTrend compares the earliest and latest observation of the last
+// hour. Temperature and pressure are "rising", "falling", "steady", or
+// "unknown" with a single observation.
+type ShortTrend struct {
+	From           *Reading `json:"from,omitempty"`
+	To             Reading  `json:"to"`
+	Temperature    string   `json:"temperature"`
+	Pressure       string   `json:"pressure"`
+	TempChangeC    float64  `json:"temp_change_c"`
+	PressureChange float64  `json:"pressure_change_mb"`
+	Note           string   `json:"note,omitempty"`
+}
+
+// direction names the direction of a change beyond steady.
+func direction(change, steady float64) string {
+	switch {
+	case change > steady:
+		return "rising"
+	case change < -steady:
+		return "falling"
+	default:
+		return "steady"
+	}
+}
+
+// shortTrend derives the trend from cached j1 responses, oldest first.
+// Responses repeating an observation are skipped.
+func (c *WeatherClient) shortTrend(entries []cacheEntry) (ShortTrend, error) {
+	var readings []Reading
+	for _, entry := range entries {
+		w, err := parseDetailed(entry.body)
+		if err != nil {
+			return ShortTrend{}, err
+		}
+		cur, err := w.current()
+		if err != nil {
+			return ShortTrend{}, err
+		}
+		if len(readings) > 0 && readings[len(readings)-1].ObservedAt == cur.LocalObsDateTime {
+			continue
+		}
+		readings = append(readings, Reading{
+			FetchedAt:  entry.fetchedAt.UTC().Format(time.RFC3339),
+			ObservedAt: cur.LocalObsDateTime,
+			TempC:      float64(cur.TempC),
+			PressureMB: float64(cur.Pressure),
+		})
+	}
+	if len(readings) == 0 {
+		return ShortTrend{}, fmt.Errorf("no observations to compare")
+	}
+
+	last := readings[len(readings)-1]
+	if len(readings) == 1 {
+		return ShortTrend{
+			To:          last,
+			Temperature: "unknown",
+			Pressure:    "unknown",
+			Note:        "only one observation in the last hour; ask again once wttr.in has a newer one",
+		}, nil
+	}
+
+	first := readings[0]
+	trend := ShortTrend{
+		From:           &first,
+		To:             last,
+		TempChangeC:    c.round(last.TempC - first.TempC),
+		PressureChange: c.round(last.PressureMB - first.PressureMB),
+	}
+	trend.Temperature = direction(trend.TempChangeC, steadyTempC)
+	trend.Pressure = direction(trend.PressureChange, steadyPressureMB)
+	return trend, nil
+}
+
+// GetShortTrend returns whether temperature and pressure are rising or
+// falling, comparing the observations cached over the last hour, as JSON.
+func (c *WeatherClient) GetShortTrend(location string) (string, error) {
+	if c.cache == nil {
+		return "", fmt.Errorf("short trends compare cached observations; set WTTR_CACHE_TTL to enable them")
+	}
+
+	u, err := c.detailedURL(location)
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.fetch(u); err != nil {
+		return "", err
+	}
+
+	trend, err := c.shortTrend(c.cache.history(u))
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(trend)
+}
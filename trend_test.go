@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWeatherClientGetShortTrend(t *testing.T) {
+	observations := []struct {
+		obs            string
+		temp, pressure int
+	}{
+		{"2024-06-12 02:00 PM", 18, 1016},
+		{"2024-06-12 02:15 PM", 19, 1015},
+		{"2024-06-12 02:30 PM", 21, 1013},
+	}
+	reading := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o := observations[reading]
+		fmt.Fprintf(w, `{"current_condition": [{"localObsDateTime": %q, "temp_C": "%d", "pressure": "%d"}]}`, o.obs, o.temp, o.pressure)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2024, 6, 12, 13, 0, 0, 0, time.UTC)
+	cache := newResponseCache(10 * time.Minute)
+	cache.now = func() time.Time { return now }
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL, precision: 1, cache: cache}
+
+	result, err := client.GetShortTrend("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `"temperature":"unknown"`; !strings.Contains(result, want) {
+		t.Errorf("expected %s with one reading, got %s", want, result)
+	}
+
+	// Two later readings, each after the cached one expired.
+	for reading = 1; reading < len(observations); reading++ {
+		now = now.Add(15 * time.Minute)
+		if result, err = client.GetShortTrend("London"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for _, want := range []string{`"temperature":"rising"`, `"pressure":"falling"`, `"temp_change_c":3`, `"pressure_change_mb":-3`} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %s in %s", want, result)
+		}
+	}
+}
+
+func TestWeatherClientGetShortTrendNeedsCache(t *testing.T) {
+	client := &WeatherClient{}
+	if _, err := client.GetShortTrend("London"); err == nil {
+		t.Fatal("expected error without a cache")
+	}
+}
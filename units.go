@@ -0,0 +1,25 @@
+package main
+
+const (
+	unitsMetric = "metric"
+	unitsBoth   = "both"
+)
+
+// WithBothUnits returns a copy of the client whose parsed results carry
+// Fahrenheit temperatures next to the Celsius ones.
+func (c *WeatherClient) WithBothUnits() WeatherService {
+	both := *c
+	both.bothUnits = true
+	return &both
+}
+
+// fahrenheit returns a Fahrenheit temperature for a parsed result, or nil
+// unless both units were requested. The value is wttr.in's own dual field
+// rather than a conversion, so it always matches what wttr.in shows.
+func (c *WeatherClient) fahrenheit(f number) *float64 {
+	if !c.bothUnits {
+		return nil
+	}
+	v := float64(f)
+	return &v
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+// dualFixture is a forecast day whose slots carry both of wttr.in's
+// temperature fields.
+func dualFixture() DetailedWeather {
+	d := DayForecast{Date: "2024-06-12"}
+	for i, temp := range []float64{12, 10, 14, 19, 24, 26, 21, 16} {
+		d.Hourly = append(d.Hourly, HourlyWeather{
+			Time:       number(i * 300),
+			TempC:      number(temp),
+			TempF:      number(math.Round(temp*9/5 + 32)),
+			FeelsLikeC: number(temp - 1),
+			FeelsLikeF: number(math.Round((temp-1)*9/5 + 32)),
+		})
+	}
+	return DetailedWeather{Weather: []DayForecast{d}}
+}
+
+// consistent reports whether f is c in Fahrenheit, allowing for wttr.in
+// rounding both to whole degrees.
+func consistent(c float64, f *float64) bool {
+	return f != nil && math.Abs(c*9/5+32-*f) <= 1
+}
+
+func TestDayBandBothUnits(t *testing.T) {
+	c := new(WeatherClient).WithBothUnits().(*WeatherClient)
+
+	band, err := c.dayBand(dualFixture())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if band.MinTempC != 10 || !consistent(band.MinTempC, band.MinTempF) || *band.MinTempF != 50 {
+		t.Errorf("unexpected minimum: %g°C / %v", band.MinTempC, band.MinTempF)
+	}
+	if band.MaxTempC != 26 || !consistent(band.MaxTempC, band.MaxTempF) || *band.MaxTempF != 79 {
+		t.Errorf("unexpected maximum: %g°C / %v", band.MaxTempC, band.MaxTempF)
+	}
+}
+
+func TestDaySegmentsBothUnits(t *testing.T) {
+	c := new(WeatherClient).WithBothUnits().(*WeatherClient)
+
+	result, err := c.daySegmentsFor(dualFixture(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, seg := range result.Segments {
+		if !consistent(seg.TempC, seg.TempF) || !consistent(seg.FeelsLikeC, seg.FeelsLikeF) {
+			t.Errorf("%s: inconsistent units: %+v", seg.Segment, seg)
+		}
+	}
+
+	data, _ := json.Marshal(result.Segments[0])
+	if !strings.Contains(string(data), `"temp_c":19,`) || !strings.Contains(string(data), `"temp_f":66`) {
+		t.Errorf("expected both fields in %s", data)
+	}
+}
+
+func TestMetricOnlyByDefault(t *testing.T) {
+	band, err := new(WeatherClient).dayBand(dualFixture())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := json.Marshal(band)
+	if strings.Contains(string(data), "_f") {
+		t.Errorf("expected Celsius only, got %s", data)
+	}
+}
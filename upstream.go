@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRateLimitCooldown is how long requests to wttr.in are held back
+// after it rate limits one, unless it says otherwise with Retry-After.
+const defaultRateLimitCooldown = time.Minute
+
+// upstreamState is what the copies of a client share about wttr.in. After
+// a rate limit response no requests are made until the cooldown ends;
+// responses are served from the cache, stale if need be, instead.
+type upstreamState struct {
+	now func() time.Time
+
+	mu           sync.Mutex
+	limitedUntil time.Time
+}
+
+func newUpstreamState() *upstreamState {
+	return &upstreamState{now: time.Now}
+}
+
+// rateLimited starts a cooldown of the given length, or the default when
+// it is not positive. A running cooldown is never shortened.
+func (u *upstreamState) rateLimited(retryAfter time.Duration) {
+	if retryAfter <= 0 {
+		retryAfter = defaultRateLimitCooldown
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if until := u.now().Add(retryAfter); until.After(u.limitedUntil) {
+		u.limitedUntil = until
+	}
+}
+
+// coolingDown reports whether requests are held back, and until when.
+func (u *upstreamState) coolingDown() (time.Time, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.limitedUntil, u.now().Before(u.limitedUntil)
+}
+
+// retryAfter reads a Retry-After header given in seconds. The HTTP date
+// form is not used by wttr.in and is ignored.
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(header.Get("Retry-After")))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// isRateLimit reports whether err is a rate limit response from wttr.in.
+func isRateLimit(err error) bool {
+	var status *statusError
+	return errors.As(err, &status) && status.StatusCode == http.StatusTooManyRequests
+}
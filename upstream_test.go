@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWeatherClientRateLimitServesStale(t *testing.T) {
+	requests, limited := 0, false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if limited {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("London: ☀️ +20°C"))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2024, 6, 12, 14, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+		cache:      newResponseCache(time.Minute),
+		upstream:   &upstreamState{now: clock},
+	}
+	client.cache.now = clock
+
+	if _, err := client.GetCurrent("London"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The cached copy expires and wttr.in starts rate limiting.
+	now = now.Add(2 * time.Minute)
+	limited = true
+	if result, err := client.GetCurrent("London"); err != nil || result != "London: ☀️ +20°C" {
+		t.Fatalf("expected the stale copy on a rate limit, got %q (%v)", result, err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 upstream requests, got %d", requests)
+	}
+
+	// During the cooldown wttr.in is not asked at all.
+	now = now.Add(time.Minute)
+	if result, err := client.GetCurrent("London"); err != nil || result != "London: ☀️ +20°C" {
+		t.Errorf("expected the stale copy during the cooldown, got %q (%v)", result, err)
+	}
+	_, err := client.GetCurrent("Paris")
+	if errorCode(err) != errorCodeRateLimited {
+		t.Errorf("expected a rate limited error without a cached copy, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected no upstream requests during the cooldown, got %d", requests)
+	}
+
+	// Retry-After was 120 seconds.
+	now = now.Add(time.Minute + time.Second)
+	limited = false
+	if _, err := client.GetCurrent("Paris"); err != nil || requests != 3 {
+		t.Errorf("expected wttr.in to be asked after the cooldown, got %d requests (%v)", requests, err)
+	}
+}
+
+func TestUpstreamStateCooldown(t *testing.T) {
+	now := time.Date(2024, 6, 12, 14, 0, 0, 0, time.UTC)
+	u := &upstreamState{now: func() time.Time { return now }}
+
+	if _, ok := u.coolingDown(); ok {
+		t.Fatal("expected no cooldown before a rate limit")
+	}
+
+	u.rateLimited(0)
+	if until, ok := u.coolingDown(); !ok || until != now.Add(defaultRateLimitCooldown) {
+		t.Errorf("expected the default cooldown, got %v (%v)", until, ok)
+	}
+
+	// A shorter Retry-After does not cut the running cooldown short.
+	u.rateLimited(10 * time.Second)
+	if until, _ := u.coolingDown(); until != now.Add(defaultRateLimitCooldown) {
+		t.Errorf("expected the cooldown to be kept, got %v", until)
+	}
+
+	now = now.Add(defaultRateLimitCooldown)
+	if _, ok := u.coolingDown(); ok {
+		t.Error("expected the cooldown to have ended")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"":                              0,
+		"30":                            30 * time.Second,
+		"-5":                            0,
+		"Wed, 12 Jun 2024 14:00:00 GMT": 0,
+	} {
+		header := http.Header{}
+		header.Set("Retry-After", value)
+		if got := retryAfter(header); got != want {
+			t.Errorf("Retry-After %q: got %v, want %v", value, got, want)
+		}
+	}
+}
@@ -1,64 +1,548 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 type WeatherClient struct {
 	httpClient *http.Client
 	baseURL    string
+	precision  int
+	trace      *FetchTrace
+	archive    ArchiveProvider
+	theme      Theme
+	tz         *time.Location
+	epoch      bool
+	bothUnits  bool
+	hourly     bool
+	local      bool
+	cache      *responseCache
+	upstream   *upstreamState
+	autoLangs  []string
+
+	// maintenancePhrases overrides defaultMaintenancePhrases when set.
+	maintenancePhrases []string
+}
+
+// FetchTrace collects the upstream requests made while serving a tool call.
+type FetchTrace struct {
+	mu      sync.Mutex
+	fetches []FetchRecord
+}
+
+// FetchRecord is a single upstream request.
+type FetchRecord struct {
+	URL       string
+	FetchedAt time.Time
+}
+
+func (t *FetchTrace) record(rawURL string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fetches = append(t.fetches, FetchRecord{URL: rawURL, FetchedAt: at})
+}
+
+// Fetches returns the recorded requests in the order they were made.
+func (t *FetchTrace) Fetches() []FetchRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]FetchRecord(nil), t.fetches...)
 }
 
-func NewWeatherClient() *WeatherClient {
-	return &WeatherClient{
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+// Default upstream timeouts: the whole request, connecting, and waiting for
+// the response headers.
+const (
+	defaultFetchTimeout  = 30 * time.Second
+	defaultDialTimeout   = 10 * time.Second
+	defaultHeaderTimeout = 20 * time.Second
+)
+
+// orDefault returns d, or def when d is not set.
+func orDefault(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
+
+// newDialer returns the dialer for connections to wttr.in.
+func newDialer(cfg Config) *net.Dialer {
+	return &net.Dialer{
+		Timeout:   orDefault(cfg.DialTimeout, defaultDialTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+}
+
+// newHTTPClient returns the upstream HTTP client with the configured
+// timeouts, so a slow connect and a slow response are told apart.
+func newHTTPClient(cfg Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = newDialer(cfg).DialContext
+	transport.ResponseHeaderTimeout = orDefault(cfg.HeaderTimeout, defaultHeaderTimeout)
+
+	return &http.Client{
+		Timeout:   orDefault(cfg.FetchTimeout, defaultFetchTimeout),
+		Transport: transport,
+	}
+}
+
+func NewWeatherClient(cfg Config) *WeatherClient {
+	c := &WeatherClient{
+		httpClient: newHTTPClient(cfg),
 		baseURL:    "http://wttr.in",
+		precision:  cfg.Precision,
+		theme:      cfg.Theme,
+		autoLangs:  cfg.AutoLangs,
+		local:      cfg.LocalRender,
+		upstream:   newUpstreamState(),
+
+		maintenancePhrases: cfg.MaintenancePhrases,
+	}
+	if cfg.CacheTTL > 0 {
+		c.cache = newResponseCache(cfg.CacheTTL)
 	}
+	if cfg.ArchiveURL != "" {
+		c.archive = &openMeteoArchive{httpClient: c.httpClient, baseURL: cfg.ArchiveURL}
+	}
+	return c
 }
 
-// GetCurrent returns a one-line summary of current weather.
-func (c *WeatherClient) GetCurrent(location string) (string, error) {
-	u := fmt.Sprintf("%s/%s?format=%%l:+%%c+%%t+(%%f)+%%h+%%w", c.baseURL, url.PathEscape(location))
-	return c.fetch(u)
+// WithTrace returns a copy of the client that records its fetches in trace.
+func (c *WeatherClient) WithTrace(trace *FetchTrace) WeatherService {
+	traced := *c
+	traced.trace = trace
+	return &traced
 }
 
-// GetForecast returns a text forecast for the given number of days.
-func (c *WeatherClient) GetForecast(location string, days int) (string, error) {
-	u := fmt.Sprintf("%s/%s?%d&lang=ru", c.baseURL, url.PathEscape(location), days)
-	return c.fetch(u)
+// currentFormat is the default one-liner: location, condition, temperature,
+// feels like, humidity and wind.
+const currentFormat = "%l:+%c+%t+(%f)+%h+%w"
+
+// currentFields maps the optional one-liner fields to wttr.in format directives.
+var currentFields = map[string]string{
+	"precipitation":        "%p",
+	"precipitation_chance": "%o",
+	"pressure":             "%P",
+	"uv_index":             "%u",
+	"moon_phase":           "%m",
+	"dawn":                 "%D",
+	"sunrise":              "%S",
+	"sunset":               "%s",
+	"dusk":                 "%d",
+}
+
+// currentFieldNames returns the supported optional field names, sorted.
+func currentFieldNames() []string {
+	names := make([]string, 0, len(currentFields))
+	for name := range currentFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// buildCurrentFormat appends the directives for the requested fields to the
+// default one-liner format.
+func buildCurrentFormat(fields []string) (string, error) {
+	format := currentFormat
+	for _, field := range fields {
+		directive, ok := currentFields[field]
+		if !ok {
+			return "", invalidArgument("unknown field %q (supported: %s)", field, strings.Join(currentFieldNames(), ", "))
+		}
+		format += "+" + directive
+	}
+	return format, nil
+}
+
+// forecastOptions maps the text forecast display options to wttr.in's
+// single-letter flags.
+var forecastOptions = map[string]string{
+	"narrow": "n", // day and night only, no morning and evening columns
+	"plain":  "T", // no terminal color sequences
+	"quiet":  "q", // no "Weather report" caption
+}
+
+// forecastOptionNames returns the supported display option names, sorted.
+func forecastOptionNames() []string {
+	names := make([]string, 0, len(forecastOptions))
+	for name := range forecastOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// forecastFlags returns the wttr.in flags for the display options, in the
+// order of forecastOptionNames and each once however often it was asked for.
+func forecastFlags(options []string) (string, error) {
+	requested := make(map[string]bool, len(options))
+	for _, option := range options {
+		if _, ok := forecastOptions[option]; !ok {
+			return "", invalidArgument("unknown option %q (supported: %s)", option, strings.Join(forecastOptionNames(), ", "))
+		}
+		requested[option] = true
+	}
+	var flags strings.Builder
+	for _, name := range forecastOptionNames() {
+		if requested[name] {
+			flags.WriteString(forecastOptions[name])
+		}
+	}
+	return flags.String(), nil
+}
+
+// forecastQuery builds the text forecast query string. wttr.in reads the
+// day selector and the display flags as one run of single-letter options
+// ahead of the named parameters, so they are written together: "0nT" for
+// a narrow, plain today-only view, then "&lang=fr".
+func forecastQuery(days int, lang string, options []string) (string, error) {
+	flags, err := forecastFlags(options)
+	if err != nil {
+		return "", err
+	}
+	query := fmt.Sprintf("%d%s", days, flags)
+	if lang != "" {
+		query += "&lang=" + url.QueryEscape(lang)
+	}
+	return query, nil
+}
+
+// coordinatePattern matches "lat,lon" locations.
+var coordinatePattern = regexp.MustCompile(`^\s*([-+]?\d+(?:\.\d+)?)\s*,\s*([-+]?\d+(?:\.\d+)?)\s*$`)
+
+// checkCoordinates rejects "lat,lon" locations outside the valid ranges,
+// which wttr.in would otherwise resolve to some arbitrary place. Other
+// locations are accepted as is.
+func checkCoordinates(location string) error {
+	m := coordinatePattern.FindStringSubmatch(location)
+	if m == nil {
+		return nil
+	}
+	lat, _ := strconv.ParseFloat(m[1], 64)
+	lon, _ := strconv.ParseFloat(m[2], 64)
+	if lat < -90 || lat > 90 {
+		return invalidArgument("latitude %g is out of range (-90 to 90)", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return invalidArgument("longitude %g is out of range (-180 to 180)", lon)
+	}
+	return nil
+}
+
+// locationPath returns the URL path segment for a location. Clients
+// sometimes send locations that are already percent-encoded ("New%20York");
+// those are decoded first so they are not escaped twice. A "%" that does not
+// start a valid escape is kept literally. Leading and trailing slashes are
+// dropped, since wttr.in would read them as part of the path.
+func locationPath(location string) (string, error) {
+	if decoded, err := url.PathUnescape(location); err == nil {
+		location = decoded
+	}
+	location = strings.Trim(location, "/")
+	if err := checkCoordinates(location); err != nil {
+		return "", err
+	}
+	return url.PathEscape(location), nil
+}
+
+// GetCurrent returns a one-line summary of current weather, extended with
+// the optional fields in the order given. With local rendering it is built
+// from the j1 data, unless a field is only available from wttr.in.
+func (c *WeatherClient) GetCurrent(location string, fields ...string) (string, error) {
+	format, err := buildCurrentFormat(fields)
+	if err != nil {
+		return "", err
+	}
+
+	if c.local {
+		w, err := c.fetchDetailed(location)
+		if err != nil {
+			return "", err
+		}
+		line, ok, err := renderCurrent(w, location, fields)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return line, nil
+		}
+	}
+
+	path, err := locationPath(location)
+	if err != nil {
+		return "", err
+	}
+	u := fmt.Sprintf("%s/%s?format=%s", c.baseURL, path, format)
+	body, err := c.fetch(u)
+	if err != nil {
+		return "", err
+	}
+	return collapseSpaces(body), nil
+}
+
+// collapseSpaces collapses the irregular runs of whitespace wttr.in renders
+// between one-liner fields into single spaces.
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// GetForecast returns a text forecast for the given number of days,
+// rendered with the display options. Zero days is wttr.in's today-only
+// view: the current conditions without the day panels. It is in Russian
+// unless a language is detected from the location, which costs an extra
+// j1 request. With local rendering it is built in English from the j1 data
+// instead, so it shares the cached response with the other tools.
+func (c *WeatherClient) GetForecast(location string, days int, options ...string) (string, error) {
+	if _, err := forecastFlags(options); err != nil {
+		return "", err
+	}
+	path, err := locationPath(location)
+	if err != nil {
+		return "", err
+	}
+	if c.local {
+		w, err := c.fetchDetailed(location)
+		if err != nil {
+			return "", err
+		}
+		return renderForecast(w, location, days, options)
+	}
+	lang, err := c.forecastLang(location)
+	if err != nil {
+		return "", err
+	}
+	query, err := forecastQuery(days, lang, options)
+	if err != nil {
+		return "", err
+	}
+	return c.fetch(fmt.Sprintf("%s/%s?%s", c.baseURL, path, query))
 }
 
 // GetDetailed returns structured JSON weather data.
 func (c *WeatherClient) GetDetailed(location string) (string, error) {
-	u := fmt.Sprintf("%s/%s?format=j1", c.baseURL, url.PathEscape(location))
+	u, err := c.detailedURL(location)
+	if err != nil {
+		return "", err
+	}
 	return c.fetch(u)
 }
 
+// detailedURL is the j1 URL for a location.
+func (c *WeatherClient) detailedURL(location string) (string, error) {
+	path, err := locationPath(location)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s?format=j1", c.baseURL, path), nil
+}
+
+// DebugHeaders is the upstream response to a request, for diagnosing caching
+// and rate limiting.
+type DebugHeaders struct {
+	URL           string            `json:"url"`
+	Status        int               `json:"status"`
+	ContentType   string            `json:"content_type"`
+	ContentLength int               `json:"content_length"`
+	Headers       map[string]string `json:"headers"`
+}
+
+// GetDebugHeaders fetches the current weather one-liner and returns the
+// upstream response headers as JSON. Error replies, such as a 429 with its
+// Retry-After, are reported with their status rather than failing.
+func (c *WeatherClient) GetDebugHeaders(location string) (string, error) {
+	path, err := locationPath(location)
+	if err != nil {
+		return "", err
+	}
+	u := fmt.Sprintf("%s/%s?format=%s", c.baseURL, path, currentFormat)
+	body, err := c.fetchBytes(u)
+	var status *statusError
+	if errors.As(err, &status) {
+		return marshalResult(debugHeaders(u, status.StatusCode, status.Header, status.Header.Get("Content-Type"), len(status.Body)))
+	}
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(debugHeaders(u, http.StatusOK, body.Header, body.ContentType, body.Length))
+}
+
+// debugHeaders describes an upstream response, joining repeated headers.
+func debugHeaders(u string, status int, header http.Header, contentType string, length int) DebugHeaders {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	return DebugHeaders{
+		URL:           u,
+		Status:        status,
+		ContentType:   contentType,
+		ContentLength: length,
+		Headers:       headers,
+	}
+}
+
+// fetch returns the body of a successful upstream response, from the cache
+// when one is configured and holds a fresh copy. While wttr.in is rate
+// limiting, stale cached copies are served instead of asking it.
 func (c *WeatherClient) fetch(rawURL string) (string, error) {
+	if c.cache != nil {
+		if entry, ok := c.cache.get(rawURL); ok {
+			return c.cached(rawURL, entry), nil
+		}
+	}
+
+	if c.upstream != nil {
+		if until, ok := c.upstream.coolingDown(); ok {
+			return c.fetchStale(rawURL, &cooldownError{Until: until})
+		}
+	}
+
+	body, err := c.fetchText(rawURL)
+	if err != nil {
+		if c.upstream != nil && isRateLimit(err) {
+			var status *statusError
+			errors.As(err, &status)
+			c.upstream.rateLimited(status.RetryAfter)
+			return c.fetchStale(rawURL, err)
+		}
+		return "", err
+	}
+
+	if c.cache != nil {
+		c.cache.set(rawURL, body)
+	}
+	return body, nil
+}
+
+// cached records a response served from the cache and returns its body.
+func (c *WeatherClient) cached(rawURL string, entry cacheEntry) string {
+	if c.trace != nil {
+		c.trace.record(rawURL, entry.fetchedAt)
+	}
+	return entry.body
+}
+
+// fetchStale returns the latest cached response for rawURL, even if
+// expired, as long as it was fetched within historyWindow (an hour), or
+// err when there is none.
+func (c *WeatherClient) fetchStale(rawURL string, err error) (string, error) {
+	if c.cache != nil {
+		if entry, ok := c.cache.stale(rawURL); ok {
+			return c.cached(rawURL, entry), nil
+		}
+	}
+	return "", err
+}
+
+// fetchText fetches a text response from upstream, bypassing the cache.
+func (c *WeatherClient) fetchText(rawURL string) (string, error) {
+	body, err := c.fetchBytes(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	phrases := c.maintenancePhrases
+	if phrases == nil {
+		phrases = defaultMaintenancePhrases
+	}
+	if isMaintenanceNotice(string(body.Data), phrases) {
+		return "", errUnavailable
+	}
+
+	return string(body.Data), nil
+}
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decodeBody decompresses a gzip response body. Setting Accept-Encoding
+// ourselves turns off the transport's transparent decompression, so it is
+// done here; bodies are also checked for the gzip magic, since some
+// mirrors compress without a Content-Encoding header. Other bodies are
+// returned as is.
+func decodeBody(data []byte, encoding string) ([]byte, error) {
+	if !strings.EqualFold(encoding, "gzip") && !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// fetchedBody is a successful upstream response body, which may be binary,
+// with its content type and length.
+type fetchedBody struct {
+	Data        []byte
+	ContentType string
+	Length      int
+	Header      http.Header
+}
+
+// fetchBytes fetches from upstream, bypassing the cache. Unlike
+// fetchText it makes no assumptions about the body being text.
+func (c *WeatherClient) fetchBytes(rawURL string) (fetchedBody, error) {
 	req, err := http.NewRequest("GET", rawURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return fetchedBody{}, fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("User-Agent", "curl/8.0")
+	// Ask for gzip even from mirrors that don't compress unprompted; the
+	// large j1 payload shrinks several times over.
+	req.Header.Set("Accept-Encoding", "gzip")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("fetching weather: %w", err)
+		return fetchedBody{}, fmt.Errorf("fetching weather: %w", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("reading response: %w", err)
+		return fetchedBody{}, fmt.Errorf("reading response: %w", err)
 	}
+	encoding := resp.Header.Get("Content-Encoding")
 
+	// The status decides first, so a rate limit is recognized even when its
+	// body doesn't decompress; error bodies are only decoded if they can be.
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("wttr.in returned status %d: %s", resp.StatusCode, string(body))
+		if decoded, err := decodeBody(data, encoding); err == nil {
+			data = decoded
+		}
+		return fetchedBody{}, &statusError{StatusCode: resp.StatusCode, Body: string(data), RetryAfter: retryAfter(resp.Header), Header: resp.Header}
+	}
+
+	if data, err = decodeBody(data, encoding); err != nil {
+		return fetchedBody{}, fmt.Errorf("decompressing response: %w", err)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return fetchedBody{}, errEmptyResponse
+	}
+
+	if c.trace != nil {
+		c.trace.record(rawURL, time.Now())
 	}
 
-	return string(body), nil
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return fetchedBody{Data: data, ContentType: contentType, Length: len(data), Header: resp.Header}, nil
 }
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestWeatherClientGetCurrent(t *testing.T) {
@@ -33,6 +38,66 @@ func TestWeatherClientGetCurrent(t *testing.T) {
 	}
 }
 
+func TestWeatherClientGetCurrentCollapsesSpaces(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("London:   ⛅️  +13°C  (+12°C)\t71%  ↙11km/h\n"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	result, err := client.GetCurrent("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "London: ⛅️ +13°C (+12°C) 71% ↙11km/h" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestBuildCurrentFormat(t *testing.T) {
+	format, err := buildCurrentFormat([]string{"precipitation", "precipitation_chance", "pressure"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if format != "%l:+%c+%t+(%f)+%h+%w+%p+%o+%P" {
+		t.Errorf("unexpected format: %s", format)
+	}
+
+	format, err = buildCurrentFormat(nil)
+	if err != nil || format != "%l:+%c+%t+(%f)+%h+%w" {
+		t.Errorf("expected default format, got %q (%v)", format, err)
+	}
+
+	if _, err := buildCurrentFormat([]string{"pollen"}); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestWeatherClientGetCurrentFields(t *testing.T) {
+	var receivedQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedQuery = r.URL.RawQuery
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	if _, err := client.GetCurrent("London", "uv_index", "sunset"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receivedQuery != "format=%l:+%c+%t+(%f)+%h+%w+%u+%s" {
+		t.Errorf("unexpected query: %s", receivedQuery)
+	}
+}
+
 func TestWeatherClientGetForecast(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !strings.HasPrefix(r.URL.Path, "/Tokyo") {
@@ -62,6 +127,159 @@ func TestWeatherClientGetForecast(t *testing.T) {
 	}
 }
 
+func TestWeatherClientGetForecastTodayOnly(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte("today"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	if _, err := client.GetForecast("Tokyo", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "0&lang=ru" {
+		t.Errorf("expected today-only query, got %q", query)
+	}
+}
+
+func gzipped(t *testing.T, body string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWeatherClientGzipDetailed(t *testing.T) {
+	j1 := `{"current_condition": [{"temp_C": "21", "weatherDesc": [{"value": "Sunny"}]}]}`
+	for _, encoding := range []string{"gzip", ""} {
+		var acceptEncoding string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			acceptEncoding = r.Header.Get("Accept-Encoding")
+			if encoding != "" {
+				w.Header().Set("Content-Encoding", encoding)
+			}
+			w.Write(gzipped(t, j1))
+		}))
+
+		client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+		w, err := client.fetchDetailed("Madrid")
+		srv.Close()
+		if err != nil {
+			t.Fatalf("Content-Encoding %q: unexpected error: %v", encoding, err)
+		}
+		if acceptEncoding != "gzip" {
+			t.Errorf("expected gzip to be requested, got %q", acceptEncoding)
+		}
+		if cur, err := w.current(); err != nil || cur.TempC != 21 {
+			t.Errorf("Content-Encoding %q: expected the decompressed j1 to parse, got %+v (%v)", encoding, cur, err)
+		}
+	}
+}
+
+func TestDecodeBodyCorrupt(t *testing.T) {
+	body := gzipped(t, "London: ☀️ +20°C")
+	if _, err := decodeBody(body[:len(body)/2], "gzip"); err == nil {
+		t.Error("expected an error for a truncated gzip body")
+	}
+	if data, err := decodeBody([]byte("plain"), ""); err != nil || string(data) != "plain" {
+		t.Errorf("expected an uncompressed body as is, got %q (%v)", data, err)
+	}
+}
+
+func TestWeatherClientGzipErrorBody(t *testing.T) {
+	body := gzipped(t, "Too many requests")
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"corrupt", body[:len(body)/2], string(body[:len(body)/2])},
+		{"valid", body, "Too many requests"},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write(tc.data)
+		}))
+
+		client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+		_, err := client.fetchBytes(srv.URL + "/London")
+		srv.Close()
+
+		var status *statusError
+		if !errors.As(err, &status) || !isRateLimit(err) {
+			t.Fatalf("%s gzip body: expected a rate limit status error, got %v", tc.name, err)
+		}
+		if status.RetryAfter != 30*time.Second || status.Body != tc.want {
+			t.Errorf("%s gzip body: unexpected status error %+v", tc.name, status)
+		}
+	}
+}
+
+func TestForecastQuery(t *testing.T) {
+	for _, tc := range []struct {
+		days    int
+		lang    string
+		options []string
+		want    string
+	}{
+		{3, "ru", nil, "3&lang=ru"},
+		{0, "ru", []string{"narrow"}, "0n&lang=ru"},
+		{1, "fr", []string{"quiet", "plain", "narrow"}, "1nTq&lang=fr"},
+		{2, "de", []string{"plain", "plain"}, "2T&lang=de"},
+		{0, "", []string{"quiet"}, "0q"},
+	} {
+		got, err := forecastQuery(tc.days, tc.lang, tc.options)
+		if err != nil {
+			t.Errorf("%d %v: unexpected error: %v", tc.days, tc.options, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%d %s %v: got %q, want %q", tc.days, tc.lang, tc.options, got, tc.want)
+		}
+	}
+
+	if _, err := forecastQuery(1, "ru", []string{"wide"}); errorCode(err) != errorCodeInvalidArguments {
+		t.Errorf("expected an invalid argument error for an unknown option, got %v", err)
+	}
+}
+
+func TestWeatherClientGetForecastOptions(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte("today"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	if _, err := client.GetForecast("Tokyo", 0, "plain", "narrow"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "0nT&lang=ru" {
+		t.Errorf("expected the flags after the day selector, got %q", query)
+	}
+
+	query = ""
+	if _, err := client.GetForecast("Tokyo", 0, "wide"); err == nil || query != "" {
+		t.Errorf("expected an unknown option to fail before fetching, got %v (query %q)", err, query)
+	}
+}
+
 func TestWeatherClientGetDetailed(t *testing.T) {
 	jsonResp := `{"current_condition":[{"temp_C":"25"}]}`
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -126,6 +344,109 @@ func TestWeatherClientLocationEncoding(t *testing.T) {
 	}
 }
 
+func TestWeatherClientPreEncodedLocation(t *testing.T) {
+	var receivedRawURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedRawURL = r.RequestURI
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	for location, want := range map[string]string{
+		"New%20York":       "/New%20York?",
+		"S%C3%A3o%20Paulo": "/S%C3%A3o%20Paulo?",
+		"100%":             "/100%25?",
+	} {
+		client.GetCurrent(location)
+		if !strings.HasPrefix(receivedRawURL, want) {
+			t.Errorf("%s: expected path %s, got %s", location, want, receivedRawURL)
+		}
+	}
+}
+
+func TestWeatherClientLocationSlashes(t *testing.T) {
+	var receivedRawURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedRawURL = r.RequestURI
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	for _, location := range []string{"/London", "London/", "/London/", "%2FLondon"} {
+		client.GetCurrent(location)
+		if !strings.HasPrefix(receivedRawURL, "/London?") {
+			t.Errorf("%s: expected a clean path, got %s", location, receivedRawURL)
+		}
+	}
+}
+
+func TestNewHTTPClientTimeouts(t *testing.T) {
+	cfg := Config{FetchTimeout: 45 * time.Second, DialTimeout: 3 * time.Second, HeaderTimeout: 15 * time.Second}
+
+	client := newHTTPClient(cfg)
+	if client.Timeout != 45*time.Second {
+		t.Errorf("expected a 45s total timeout, got %v", client.Timeout)
+	}
+	if transport := client.Transport.(*http.Transport); transport.ResponseHeaderTimeout != 15*time.Second {
+		t.Errorf("expected a 15s response header timeout, got %v", transport.ResponseHeaderTimeout)
+	}
+	if dialer := newDialer(cfg); dialer.Timeout != 3*time.Second {
+		t.Errorf("expected a 3s dial timeout, got %v", dialer.Timeout)
+	}
+
+	// Unset timeouts fall back to the defaults.
+	client = newHTTPClient(Config{})
+	if client.Timeout != defaultFetchTimeout || client.Transport.(*http.Transport).ResponseHeaderTimeout != defaultHeaderTimeout {
+		t.Errorf("expected the default timeouts, got %v and %v", client.Timeout, client.Transport.(*http.Transport).ResponseHeaderTimeout)
+	}
+	if dialer := newDialer(Config{}); dialer.Timeout != defaultDialTimeout {
+		t.Errorf("expected the default dial timeout, got %v", dialer.Timeout)
+	}
+}
+
+func TestWeatherClientWithTrace(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	trace := &FetchTrace{}
+	traced := client.WithTrace(trace)
+	if _, err := traced.GetCurrent("London"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fetches := trace.Fetches()
+	if len(fetches) != 1 {
+		t.Fatalf("expected 1 recorded fetch, got %d", len(fetches))
+	}
+	if !strings.HasPrefix(fetches[0].URL, srv.URL+"/London?format=") {
+		t.Errorf("unexpected recorded url: %s", fetches[0].URL)
+	}
+	if fetches[0].FetchedAt.IsZero() {
+		t.Error("expected fetch time to be recorded")
+	}
+
+	if client.trace != nil {
+		t.Error("WithTrace must not modify the original client")
+	}
+}
+
 func TestWeatherClientUserAgent(t *testing.T) {
 	var receivedUA string
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -144,3 +465,175 @@ func TestWeatherClientUserAgent(t *testing.T) {
 		t.Errorf("expected User-Agent wttr-weather-mcp/1.0, got %s", receivedUA)
 	}
 }
+
+func TestWeatherClientGetDebugHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=300")
+		w.Header().Set("Age", "42")
+		w.Header().Add("X-Cache", "HIT")
+		w.Header().Add("X-Cache", "edge")
+		w.Write([]byte("London: ☀️ +15°C"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	result, err := client.GetDebugHeaders("London")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var headers DebugHeaders
+	if err := json.Unmarshal([]byte(result), &headers); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if headers.Status != 200 || !strings.HasPrefix(headers.URL, srv.URL+"/London?format=") {
+		t.Errorf("unexpected response: %+v", headers)
+	}
+	if headers.ContentType != "text/plain; charset=utf-8" || headers.ContentLength != len("London: ☀️ +15°C") {
+		t.Errorf("unexpected content metadata: %s, %d bytes", headers.ContentType, headers.ContentLength)
+	}
+	for name, want := range map[string]string{"Cache-Control": "max-age=300", "Age": "42", "X-Cache": "HIT, edge"} {
+		if got := headers.Headers[name]; got != want {
+			t.Errorf("header %s: expected %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestWeatherClientGetDebugHeadersRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("Too many requests"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	result, err := client.GetDebugHeaders("London")
+	if err != nil {
+		t.Fatalf("expected the 429 to be reported, got error: %v", err)
+	}
+
+	var headers DebugHeaders
+	if err := json.Unmarshal([]byte(result), &headers); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if headers.Status != http.StatusTooManyRequests || headers.Headers["Retry-After"] != "120" {
+		t.Errorf("expected the 429 with its Retry-After, got %+v", headers)
+	}
+	if headers.ContentLength != len("Too many requests") {
+		t.Errorf("unexpected content length %d", headers.ContentLength)
+	}
+}
+
+func TestWeatherClientFetchBytes(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/London.png" {
+			w.Header().Set("Content-Type", "image/png")
+		}
+		w.Write(png)
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{httpClient: srv.Client(), baseURL: srv.URL}
+
+	body, err := client.fetchBytes(srv.URL + "/London.png")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body.ContentType != "image/png" || body.Length != len(png) || string(body.Data) != string(png) {
+		t.Errorf("unexpected body: %s, %d bytes", body.ContentType, body.Length)
+	}
+
+	// Without a Content-Type header it is sniffed from the data.
+	body, err = client.fetchBytes(srv.URL + "/Paris.png")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body.ContentType != "image/png" {
+		t.Errorf("expected a sniffed image/png, got %s", body.ContentType)
+	}
+}
+
+func TestWeatherClientEmptyBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(" \n"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	_, err := client.GetCurrent("London")
+	if err == nil || err.Error() != "upstream returned empty response" {
+		t.Fatalf("expected empty response error, got %v", err)
+	}
+}
+
+func TestWeatherClientMaintenanceNotice(t *testing.T) {
+	body := "Sorry, we are running out of queries to the weather service at the moment.\nHere is the weather report for the default city (just to show you what it looks like)."
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/London") {
+			w.Write([]byte(body))
+			return
+		}
+		w.Write([]byte("Paris: ☀️ +22°C"))
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	if _, err := client.GetCurrent("London"); !errors.Is(err, errUnavailable) {
+		t.Errorf("expected unavailable error for the overload notice, got %v", err)
+	}
+
+	result, err := client.GetCurrent("Paris")
+	if err != nil || result != "Paris: ☀️ +22°C" {
+		t.Errorf("expected a normal response to pass through, got %q (%v)", result, err)
+	}
+}
+
+func TestCheckCoordinates(t *testing.T) {
+	valid := []string{"51.5,-0.12", "90,0", "-90,180", "0,-180", " 48.85 , 2.35 ", "London", "Saint-Petersburg", "~Eiffel Tower"}
+	for _, location := range valid {
+		if err := checkCoordinates(location); err != nil {
+			t.Errorf("%q: unexpected error: %v", location, err)
+		}
+	}
+
+	invalid := []string{"100,0", "0,200", "-91,0", "45,-180.5"}
+	for _, location := range invalid {
+		if err := checkCoordinates(location); err == nil {
+			t.Errorf("%q: expected error", location)
+		}
+	}
+}
+
+func TestWeatherClientRejectsInvalidCoordinates(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s", r.URL)
+	}))
+	defer srv.Close()
+
+	client := &WeatherClient{
+		httpClient: srv.Client(),
+		baseURL:    srv.URL,
+	}
+
+	if _, err := client.GetDetailed("100,20"); err == nil {
+		t.Fatal("expected error for latitude 100")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StripDay is one day of a week strip.
+type StripDay struct {
+	Day  string  `json:"day"`
+	Date string  `json:"date"`
+	Icon string  `json:"icon"`
+	MaxC float64 `json:"max_c"`
+}
+
+// weekStrip lists every forecast day with the icon of its noon conditions
+// and its maximum temperature.
+func weekStrip(w DetailedWeather) []StripDay {
+	strip := []StripDay{}
+	for _, d := range w.Weather {
+		day := StripDay{Date: d.Date, Icon: iconUnknown, MaxC: float64(d.MaxTempC)}
+		if date, err := time.Parse(j1DateLayout, d.Date); err == nil {
+			day.Day = date.Format("Mon")
+		}
+		if noon, ok := noonSlot(d); ok {
+			day.Icon = conditionIcon(noon.WeatherCode)
+		}
+		strip = append(strip, day)
+	}
+	return strip
+}
+
+// GetWeekStrip returns one icon and maximum temperature per available
+// forecast day as a JSON array.
+func (c *WeatherClient) GetWeekStrip(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	return marshalResult(weekStrip(w))
+}
+
+// emojiForecast renders the week strip as one line of day names and icons,
+// "Mon ☀️ Tue 🌧 Wed ⛅", for chat replies. Days whose date doesn't parse
+// are labeled with the date itself.
+func emojiForecast(w DetailedWeather) string {
+	tokens := make([]string, 0, 2*len(w.Weather))
+	for _, day := range weekStrip(w) {
+		label := day.Day
+		if label == "" {
+			label = day.Date
+		}
+		tokens = append(tokens, label, day.Icon)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// GetEmojiForecast returns one day name and condition emoji per available
+// forecast day on a single line.
+func (c *WeatherClient) GetEmojiForecast(location string) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+	if len(w.Weather) == 0 {
+		return "", fmt.Errorf("no daily forecast for %q", location)
+	}
+	return emojiForecast(w), nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestWeekStrip(t *testing.T) {
+	w := DetailedWeather{Weather: []DayForecast{
+		{Date: "2024-06-10", MaxTempC: 24, Hourly: []HourlyWeather{{Time: 900, WeatherCode: 116}, {Time: 1200, WeatherCode: 113}}},
+		{Date: "2024-06-11", MaxTempC: 18, Hourly: []HourlyWeather{{Time: 1200, WeatherCode: 302}, {Time: 1500, WeatherCode: 119}}},
+		{Date: "2024-06-12", MaxTempC: 15, Hourly: []HourlyWeather{{Time: 1200, WeatherCode: 389}}},
+	}}
+
+	strip := weekStrip(w)
+
+	want := []StripDay{
+		{Day: "Mon", Date: "2024-06-10", Icon: "☀️", MaxC: 24},
+		{Day: "Tue", Date: "2024-06-11", Icon: "🌧", MaxC: 18},
+		{Day: "Wed", Date: "2024-06-12", Icon: "🌩", MaxC: 15},
+	}
+	if len(strip) != len(want) {
+		t.Fatalf("expected %d days, got %+v", len(want), strip)
+	}
+	for i := range want {
+		if strip[i] != want[i] {
+			t.Errorf("day %d: got %+v, want %+v", i, strip[i], want[i])
+		}
+	}
+}
+
+func TestConditionIconUnknown(t *testing.T) {
+	if icon := conditionIcon(999); icon != iconUnknown {
+		t.Errorf("expected the unknown icon, got %q", icon)
+	}
+	if strip := weekStrip(DetailedWeather{Weather: []DayForecast{{Date: "2024-06-10"}}}); strip[0].Icon != iconUnknown {
+		t.Errorf("expected the unknown icon for a day without slots, got %+v", strip[0])
+	}
+}
+
+func TestEmojiForecast(t *testing.T) {
+	// 2024-06-15 is a Saturday; the strip runs across the weekend.
+	w := DetailedWeather{Weather: []DayForecast{
+		{Date: "2024-06-15", Hourly: []HourlyWeather{{Time: 1200, WeatherCode: 113}}},
+		{Date: "2024-06-16", Hourly: []HourlyWeather{{Time: 1200, WeatherCode: 302}}},
+		{Date: "2024-06-17", Hourly: []HourlyWeather{{Time: 1200, WeatherCode: 116}}},
+	}}
+
+	if line := emojiForecast(w); line != "Sat "+iconSunny+" Sun "+iconHeavyRain+" Mon "+iconPartlyCloudy {
+		t.Errorf("unexpected line: %q", line)
+	}
+
+	w.Weather = w.Weather[:1]
+	if line := emojiForecast(w); line != "Sat "+iconSunny {
+		t.Errorf("expected one token for one day, got %q", line)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import "fmt"
+
+// WindSlot is the wind in one 3-hourly slot.
+type WindSlot struct {
+	Time         timeValue `json:"time"`
+	SpeedKmph    float64   `json:"speed_kmph"`
+	GustKmph     *float64  `json:"gust_kmph,omitempty"`
+	Direction    string    `json:"direction"`
+	DirectionDeg float64   `json:"direction_deg"`
+}
+
+// WindForecast is the wind through a forecast day.
+type WindForecast struct {
+	Date  string     `json:"date"`
+	Day   string     `json:"day"`
+	Slots []WindSlot `json:"slots"`
+}
+
+// windForecast lists the wind of each hourly slot of the forecast day at
+// offset. Gusts are left out where wttr.in does not report them.
+func windForecast(w DetailedWeather, offset int) (WindForecast, error) {
+	if offset < 0 || offset >= len(w.Weather) {
+		return WindForecast{}, invalidArgument("day %d is beyond the %d-day forecast", offset, len(w.Weather))
+	}
+	d := w.Weather[offset]
+	if len(d.Hourly) == 0 {
+		return WindForecast{}, fmt.Errorf("no hourly forecast for %s", d.Date)
+	}
+
+	result := WindForecast{Date: d.Date, Day: summarizeDay(d).Day, Slots: make([]WindSlot, 0, len(d.Hourly))}
+	for _, h := range d.Hourly {
+		slot := WindSlot{
+			Time:         slotClock(h.Time),
+			SpeedKmph:    float64(h.WindSpeedKmph),
+			Direction:    h.WindDir16Point,
+			DirectionDeg: float64(h.WindDirDegree),
+		}
+		if h.WindGustKmph.Valid {
+			gust := h.WindGustKmph.Value
+			slot.GustKmph = &gust
+		}
+		result.Slots = append(result.Slots, slot)
+	}
+	return result, nil
+}
+
+// GetWindForecast returns the wind speed, gusts and direction through the
+// day at dayOffset (0 is today) as JSON.
+func (c *WeatherClient) GetWindForecast(location string, dayOffset int) (string, error) {
+	w, err := c.fetchDetailed(location)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := windForecast(w, dayOffset)
+	if err != nil {
+		return "", err
+	}
+	if c.convertsTimes() {
+		for i, slot := range result.Slots {
+			if result.Slots[i].Time, err = c.zoneClock(w, result.Date, slot.Time); err != nil {
+				return "", err
+			}
+		}
+	}
+	return marshalResult(result)
+}
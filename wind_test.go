@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestWindForecast(t *testing.T) {
+	w, err := parseDetailed(`{"weather": [{"date": "2024-06-12", "hourly": [
+		{"time": "0", "windspeedKmph": "8", "WindGustKmph": "12", "winddir16Point": "SW", "winddirDegree": "225"},
+		{"time": "1200", "windspeedKmph": "22", "WindGustKmph": "31", "winddir16Point": "WSW", "winddirDegree": "247"},
+		{"time": "2100", "windspeedKmph": "15", "winddir16Point": "W", "winddirDegree": "270"}
+	]}]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := windForecast(w, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Date != "2024-06-12" || result.Day != "Wednesday" || len(result.Slots) != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	noon := result.Slots[1]
+	if noon.Time != "12:00" || noon.SpeedKmph != 22 || noon.Direction != "WSW" || noon.DirectionDeg != 247 {
+		t.Errorf("unexpected noon slot: %+v", noon)
+	}
+	if noon.GustKmph == nil || *noon.GustKmph != 31 {
+		t.Errorf("expected 31 km/h gusts, got %v", noon.GustKmph)
+	}
+	if result.Slots[0].Time != "00:00" {
+		t.Errorf("expected midnight as 00:00, got %s", result.Slots[0].Time)
+	}
+
+	// The last slot has no gust reported.
+	if gust := result.Slots[2].GustKmph; gust != nil {
+		t.Errorf("expected no gust for the last slot, got %v", *gust)
+	}
+}
+
+func TestWindForecastBeyondForecast(t *testing.T) {
+	if _, err := windForecast(forecastFixture("2024-06-12 02:35 PM", 3), 3); err == nil {
+		t.Fatal("expected error beyond the forecast")
+	}
+}
+
+func TestOptionalNumber(t *testing.T) {
+	w, err := parseDetailed(`{"weather": [{"hourly": [{"WindGustKmph": "0"}, {"WindGustKmph": ""}, {"WindGustKmph": null}, {}]}]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []bool{true, false, false, false}
+	for i, h := range w.Weather[0].Hourly {
+		if h.WindGustKmph.Valid != want[i] {
+			t.Errorf("slot %d: expected valid=%v, got %+v", i, want[i], h.WindGustKmph)
+		}
+	}
+}